@@ -7,12 +7,11 @@ package activities
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	"math"
+	"sort"
 	"time"
 
-	"github.com/renbou/jogmock/activities/internal/gpx"
 	"github.com/renbou/jogmock/activities/internal/randutil"
-	"github.com/renbou/jogmock/activities/internal/wavegen"
 )
 
 // SpeedOptions represents the options used to generate
@@ -65,8 +64,14 @@ type ActivityOptions struct {
 	Type ActivityType
 	// Required, the activity starting time
 	Start time.Time
-	// Required, the desired average speed of the activity in km/h
+	// Required unless TotalDuration is set, the desired average speed of the activity in km/h
 	DesiredSpeed float64
+	// If set, the activity lasts exactly the total duration, including the stops. When
+	// building from a whole route, DesiredSpeed is then derived from the distance of the
+	// route, otherwise the timing of the records is only corrected after adding them
+	TotalDuration time.Duration
+	// Alternative to TotalDuration, the time at which the activity ends
+	End time.Time
 	// Options for generating common speed slopes
 	CommonSpeed *SpeedOptions
 	// Options for generating common speed slopes
@@ -82,6 +87,35 @@ type ActivityOptions struct {
 	// The fraction of speed which should be faded. E.g. 0.5 would mean
 	// that the fading would begin at 50% of the desired speed instead of 0.
 	FadeFraction float64
+	// If set, the timestamps of imported points are kept instead of generating
+	// the speed, as long as the imported file contains them
+	SourceTiming *SourceTimingOptions
+	// The rider along with the bike, which is used to model the physics of rides
+	Ride *RideOptions
+	// If set, the speed changes depending on the grade between the records
+	Grade *GradeOptions
+	// If set, rides are simulated using the power held by the rider instead of
+	// generating the speed, which also generates the power and cadence of records
+	Power *PowerOptions
+	// If set, the heart rate of records is synthesized when it is unknown
+	HeartRate *HeartRateOptions
+	// If set, the cadence of runs is generated when it is unknown
+	RunCadence *RunCadenceOptions
+	// If set, stops are made during the activity
+	Stops *StopOptions
+	// Marks the stops as made by the auto-pause of the recording device
+	// instead of the timer being paused manually
+	AutoPause bool
+	// If set, laps are added at the configured distances
+	Laps *LapOptions
+	// If set, the target speed follows the workout plan instead of DesiredSpeed
+	Workout *WorkoutOptions
+	// If set, the speed is generated by the selected generator instead of the speed wave
+	SpeedGenerator *SpeedGeneratorOptions
+	// Seed of the random values used to generate the activity, so that the same
+	// activity is generated again given the same seed, options and route.
+	// A new seed is chosen if 0, which is then returned by the activity's Seed
+	Seed int64
 }
 
 const (
@@ -138,7 +172,24 @@ func (o *ActivityOptions) validateAndSetDefaults() error {
 		return errors.New("start time is in the future")
 	}
 
-	if o.DesiredSpeed < minDesiredSpeed {
+	if !o.End.IsZero() {
+		if o.TotalDuration != 0 {
+			return errors.New("only one of the total duration and the end time can be set")
+		}
+		if !o.End.After(o.Start) {
+			return errors.New("end time must be after the start time")
+		}
+		o.TotalDuration = o.End.Sub(o.Start)
+	}
+	if o.TotalDuration < 0 {
+		return errors.New("total duration cannot be negative")
+	}
+	if o.TotalDuration > 0 && (o.Power != nil || o.Workout != nil) {
+		return errors.New("total duration cannot be used along with the power simulation or workout plans")
+	}
+
+	// the desired speed is derived from the total duration if it isn't set
+	if o.DesiredSpeed < minDesiredSpeed && (o.TotalDuration == 0 || o.DesiredSpeed != 0) {
 		return fmt.Errorf("desired speed is less than %fkm/h", minDesiredSpeed)
 	}
 
@@ -168,6 +219,68 @@ func (o *ActivityOptions) validateAndSetDefaults() error {
 		o.FadeFraction = DefaultFadeFraction
 	}
 
+	if o.Ride == nil {
+		o.Ride = &RideOptions{}
+	}
+	if err := o.Ride.validateAndSetDefaults(); err != nil {
+		return err
+	}
+
+	if o.Grade != nil {
+		if err := o.Grade.validateAndSetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if o.Power != nil {
+		if err := o.Power.validateAndSetDefaults(o.Type, o.DesiredSpeed, o.Ride); err != nil {
+			return err
+		}
+	}
+
+	if o.HeartRate != nil {
+		if err := o.HeartRate.validateAndSetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if o.RunCadence != nil {
+		if err := o.RunCadence.validateAndSetDefaults(o.Type); err != nil {
+			return err
+		}
+	}
+
+	if o.Stops != nil {
+		if err := o.Stops.validateAndSetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if o.Laps != nil {
+		if err := o.Laps.validateAndSetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if o.Workout != nil {
+		if o.Power != nil {
+			return errors.New("workout plans cannot be used along with the power simulation")
+		}
+		if err := o.Workout.validateAndSetDefaults(o.DesiredSpeed); err != nil {
+			return err
+		}
+	}
+
+	if o.SpeedGenerator != nil {
+		if err := o.SpeedGenerator.validateAndSetDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if o.Seed == 0 {
+		o.Seed = randutil.NewSeed()
+	}
+
 	if o.CommonSpeed == nil {
 		if o.Type == RunActivity {
 			o.CommonSpeed = &DefaultRunCommonSpeed
@@ -189,22 +302,56 @@ func (o *ActivityOptions) validateAndSetDefaults() error {
 
 // Activity represents a single activity of any valid type
 type Activity struct {
-	name            string
-	description     string
-	activityType    ActivityType
-	startTime       time.Time
-	desiredSpeed    float64
-	wave            wavegen.Wave
-	fadeInDuration  time.Duration
-	fadeOutDuration time.Duration
-	fadeFraction    float64
-	records         []Record
+	name         string
+	description  string
+	activityType ActivityType
+	startTime    time.Time
+	desiredSpeed float64
+	seed         int64
+	rand         *randutil.Rand
+	speed        SpeedGenerator
+	// creates the speed generator for the desired speed once it is known
+	newSpeedGenerator func(desiredSpeed float64) SpeedGenerator
+	targetDuration    time.Duration
+	fadeInDuration    time.Duration
+	fadeOutDuration   time.Duration
+	fadeFraction      float64
+	sourceTiming      *SourceTimingOptions
+	// whether the activity was built using the timestamps of the points
+	timed bool
+	ride  *RideOptions
+	grade *GradeOptions
+	// normalization of the grade factors over the route and the current smoothed factor
+	gradeNormalization float64
+	currentGradeFactor float64
+	power              *PowerOptions
+	heartRate          *HeartRateOptions
+	runCadence         *RunCadenceOptions
+	stopOptions        *StopOptions
+	autoPause          bool
+	lapOptions         *LapOptions
+	workout            *WorkoutOptions
+	workoutPlan        []workoutInterval
+	// current smoothed factor of the workout target speed
+	currentWorkoutFactor float64
+	records              []Record
+	stops                []Stop
+	lapMarkers           []LapMarker
 }
 
-func randomiseFade(fadeDuration time.Duration) time.Duration {
+// LapMarker marks the point of the activity at which a new lap should begin
+type LapMarker struct {
+	Name string
+	// distance from the start of the activity in km
+	Distance float64
+	// what caused the previous lap to end
+	Trigger LapTrigger
+}
+
+func randomiseFade(fadeDuration time.Duration, rnd *randutil.Rand) time.Duration {
 	amplitude := int(float64(fadeDuration) / float64(time.Second) * fadeRandomFraction)
 	return fadeDuration + time.Second*time.Duration(
-		randutil.IntInRange(-amplitude, amplitude))
+		rnd.IntInRange(-amplitude, amplitude))
 }
 
 // NewActivity initializes a new activity with the given options
@@ -213,31 +360,53 @@ func NewActivity(options *ActivityOptions) (*Activity, error) {
 		return nil, err
 	}
 
+	var workoutPlan []workoutInterval
+	if options.Workout != nil {
+		workoutPlan, _ = flattenWorkout(nil, options.Workout.Steps, 0)
+	}
+
+	// all of the random values of the activity are generated by a single source
+	rnd := randutil.New(options.Seed)
+	generatorOptions := *options
+	newSpeedGenerator := func(desiredSpeed float64) SpeedGenerator {
+		generatorOptions.DesiredSpeed = desiredSpeed
+		return generatorOptions.speedGenerator(rnd)
+	}
+	var speed SpeedGenerator
+	if options.DesiredSpeed != 0 {
+		speed = newSpeedGenerator(options.DesiredSpeed)
+	}
+
 	return &Activity{
-		name:         options.Name,
-		description:  options.Description,
-		activityType: options.Type,
-		startTime:    options.Start,
-		desiredSpeed: options.DesiredSpeed,
-		wave: wavegen.Wave{
-			CommonSlope: wavegen.SlopeOptions{
-				Slope:     options.CommonSpeed.Slope,
-				Amplitude: options.CommonSpeed.Amplitude,
-				MinPeriod: options.CommonSpeed.MinDuration,
-				MaxPeriod: options.CommonSpeed.MaxDuration,
-			},
-			RareSlope: wavegen.SlopeOptions{
-				Slope:     options.RareSpeed.Slope,
-				Amplitude: options.RareSpeed.Amplitude,
-				MinPeriod: options.RareSpeed.MinDuration,
-				MaxPeriod: options.RareSpeed.MaxDuration,
-			},
-			RareSlopeChance: options.RareSpeedChance,
-			Average:         options.DesiredSpeed,
-		},
-		fadeInDuration:  randomiseFade(options.FadeDuration),
-		fadeOutDuration: randomiseFade(options.FadeDuration),
-		fadeFraction:    options.FadeFraction,
+		name:              options.Name,
+		description:       options.Description,
+		activityType:      options.Type,
+		startTime:         options.Start,
+		desiredSpeed:      options.DesiredSpeed,
+		seed:              options.Seed,
+		rand:              rnd,
+		speed:             speed,
+		newSpeedGenerator: newSpeedGenerator,
+		targetDuration:    options.TotalDuration,
+		fadeInDuration:    randomiseFade(options.FadeDuration, rnd),
+		fadeOutDuration:   randomiseFade(options.FadeDuration, rnd),
+		fadeFraction:      options.FadeFraction,
+		sourceTiming:      options.SourceTiming,
+		ride:              options.Ride,
+		grade:             options.Grade,
+		// the normalization is only known if the whole route is known in advance
+		gradeNormalization: 1,
+		currentGradeFactor: 1,
+		power:              options.Power,
+		heartRate:          options.HeartRate,
+		runCadence:         options.RunCadence,
+		stopOptions:        options.Stops,
+		autoPause:          options.AutoPause,
+		lapOptions:         options.Laps,
+		workout:            options.Workout,
+		workoutPlan:        workoutPlan,
+		// the speed begins changing towards the target of the first step during the fade-in
+		currentWorkoutFactor: 1,
 	}, nil
 }
 
@@ -286,6 +455,11 @@ func (a *Activity) Description() string {
 	return a.description
 }
 
+// Seed returns the seed of the random values used to generate the activity
+func (a *Activity) Seed() int64 {
+	return a.seed
+}
+
 // intermediateRecord builds an intermediate record which is a result
 // of moving from prev to next with given speed, and returns the new record
 // as well as a boolean representing whether the next record was reached
@@ -298,11 +472,13 @@ func intermediateRecord(prev *Record, next *Record, speed float64) (intermediate
 		// if we reach the point, then we need to just copy it and correct the timestamp
 		reached = true
 		intermediate = Record{
-			Lat:      next.Lat,
-			Lon:      next.Lon,
-			Altitude: next.Altitude,
-			Distance: prev.Distance + distanceBetweenRecords,
-			Speed:    speed,
+			Lat:       next.Lat,
+			Lon:       next.Lon,
+			Altitude:  next.Altitude,
+			HeartRate: next.HeartRate,
+			Cadence:   next.Cadence,
+			Distance:  prev.Distance + distanceBetweenRecords,
+			Speed:     speed,
 			Timestamp: prev.Timestamp.Add(time.Duration(
 				float64(time.Hour) * (distanceBetweenRecords / speed),
 			)),
@@ -328,10 +504,14 @@ func calculateFadeSegmentSpeed(fadeDuration time.Duration, fadeFraction float64,
 var (
 	ErrInvalidLatitude  = errors.New("latitude isn't in bounds (-90, 90)")
 	ErrInvalidLongitude = errors.New("longitude isn't in bounds (-180, 180)")
+	ErrNoRecords        = errors.New("activity doesn't contain any records")
 )
 
-// AddRecord adds the given record to the activity. Only the Lat, Lon and Altitude
-// fields of the Record are used, other fields are calculated depending on the activity parameters
+// AddRecord adds the given record to the activity. Only the Lat, Lon, Altitude, HeartRate and Cadence
+// fields of the Record are used, other fields are calculated depending on the activity parameters.
+// If ActivityOptions.Grade is set, the average speed is only kept when building the activity from
+// a whole route, e.g. using BuildFromRecords, since the grades of the route must be known in advance.
+// If ActivityOptions.Power is set, the Power and unknown Cadence of the records are simulated as well.
 func (a *Activity) AddRecord(record *Record) error {
 	if record.Lat < -90 || record.Lat > 90 {
 		return ErrInvalidLatitude
@@ -339,12 +519,18 @@ func (a *Activity) AddRecord(record *Record) error {
 		return ErrInvalidLongitude
 	}
 
+	if a.speed == nil {
+		return ErrUnknownDesiredSpeed
+	}
+
 	if len(a.records) == 0 {
 		// the first record with speed set to 0 for fade-in
 		a.records = append(a.records, Record{
 			Lat:       record.Lat,
 			Lon:       record.Lon,
 			Altitude:  record.Altitude,
+			HeartRate: record.HeartRate,
+			Cadence:   record.Cadence,
 			Timestamp: a.startTime,
 			Distance:  0,
 			Speed:     (a.desiredSpeed * 1 / 4),
@@ -354,16 +540,36 @@ func (a *Activity) AddRecord(record *Record) error {
 
 	var intermediate Record
 	var speed float64
+	gradeFactor := a.gradeFactor(a.lastRecord(), record) / a.gradeNormalization
 	reached := false
 	for !reached {
+		if a.power != nil {
+			// the speed is simulated from the power, which varies with the generated speed
+			var power, cadence float64
+			speed, power, cadence = a.simulatePower(record, a.speed.Next()/a.desiredSpeed)
+			intermediate, reached = intermediateRecord(a.lastRecord(), record, speed)
+			intermediate.Power = uint16(math.Round(power))
+			if intermediate.Cadence == 0 {
+				intermediate.Cadence = uint8(math.Round(cadence))
+			}
+			a.records = append(a.records, intermediate)
+			continue
+		}
+
 		if a.TotalDuration() < a.fadeInDuration {
 			// add the fade-in records if we haven't reached our desired speed yet
 			fadeInSegmentSpeed := calculateFadeSegmentSpeed(a.fadeInDuration, a.fadeFraction, a.desiredSpeed)
 			speed = fadeInSegmentSpeed*(float64(a.TotalDuration())/float64(time.Second)+1) +
 				(a.desiredSpeed * (1 - a.fadeFraction))
 		} else {
-			speed = a.wave.Next()
+			speed = a.speed.Next()
 		}
+		// smoothly approach the speed for the grade of the current segment
+		a.currentGradeFactor += (gradeFactor - a.currentGradeFactor) * gradeSmoothing
+		speed *= a.currentGradeFactor
+		// and the target speed of the current workout step
+		a.currentWorkoutFactor += (a.workoutFactor(a.TotalDistance()) - a.currentWorkoutFactor) * workoutSmoothing
+		speed *= a.currentWorkoutFactor
 		intermediate, reached = intermediateRecord(a.lastRecord(), record, speed)
 		a.records = append(a.records, intermediate)
 	}
@@ -403,9 +609,8 @@ func (a *Activity) addFadeOut() error {
 	fadeOutSegmentSpeed := calculateFadeSegmentSpeed(a.fadeOutDuration, a.fadeFraction, recBeforeFadeout.Speed)
 	calcSegmentSpeed := func() float64 {
 		actualFadeout := float64(a.lastRecord().Timestamp.Sub(recBeforeFadeout.Timestamp)) / float64(time.Second)
-		if actualFadeout > float64(fadeOutSeconds) {
-			return fadeOutSegmentSpeed
-		}
+		// the speed at the end of the fade-out is kept if the rest of the route takes longer
+		actualFadeout = math.Min(actualFadeout, fadeOutSeconds)
 		return fadeOutSegmentSpeed*(float64(fadeOutSeconds)-actualFadeout) +
 			(recBeforeFadeout.Speed * (1 - a.fadeFraction))
 	}
@@ -422,12 +627,27 @@ func (a *Activity) addFadeOut() error {
 	return nil
 }
 
-// Finalize finalizes the activity by adding the fade-out.
-// Records should not be added after this point
+// Finalize finalizes the activity by adding the fade-out, the laps, the stops and synthesizing
+// the heart rate and cadence if configured. Records should not be added after this point
 func (a *Activity) Finalize() error {
 	if err := a.addFadeOut(); err != nil {
 		return err
 	}
+	return a.finish(nil)
+}
+
+// finish adds the configured lap markers and the stops, including the ones at the given
+// waypoint records, corrects the timing for the total duration and generates
+// the configured values of the records which are unknown
+func (a *Activity) finish(waypointRecords []int) error {
+	a.addOptionLapMarkers()
+	a.addWorkoutLapMarkers()
+	a.addStops(waypointRecords)
+	if err := a.retime(); err != nil {
+		return err
+	}
+	a.addRunCadence()
+	a.addHeartRate()
 	return nil
 }
 
@@ -437,33 +657,17 @@ func (a *Activity) Records() []Record {
 	return a.records
 }
 
-// BuildFromGPX
-func (a *Activity) BuildFromGPX(b []byte) error {
-	gpx, err := gpx.UnmarshalGPX(b)
-	if err != nil {
-		return err
-	}
-
-	// add each record from the gpx file to the activity
-	for _, trackPart := range gpx.Track.TrackSegment.TrackParts {
-		lat, err := strconv.ParseFloat(trackPart.Lat, 64)
-		if err != nil {
-			return err
-		}
-		lon, err := strconv.ParseFloat(trackPart.Lon, 64)
-		if err != nil {
-			return err
-		}
-
-		if err := a.AddRecord(&Record{
-			Lat:      lat,
-			Lon:      lon,
-			Altitude: trackPart.Elevation,
-		}); err != nil {
-			return err
-		}
-	}
+// LapMarkers returns the lap markers of the activity ordered by distance
+func (a *Activity) LapMarkers() []LapMarker {
+	return a.lapMarkers
+}
 
-	// finalize the activity
-	return a.Finalize()
+// addLapMarker adds a lap marker, keeping the markers ordered by distance
+func (a *Activity) addLapMarker(marker LapMarker) {
+	i := sort.Search(len(a.lapMarkers), func(i int) bool {
+		return a.lapMarkers[i].Distance > marker.Distance
+	})
+	a.lapMarkers = append(a.lapMarkers, LapMarker{})
+	copy(a.lapMarkers[i+1:], a.lapMarkers[i:])
+	a.lapMarkers[i] = marker
 }
@@ -0,0 +1,71 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newActivity creates an activity with the given options, defaulting to a run
+// started an hour ago at 10km/h unless the total duration is set instead
+func newActivity(r *require.Assertions, options *ActivityOptions) *Activity {
+	if options == nil {
+		options = &ActivityOptions{}
+	}
+	if options.Type == 0 {
+		options.Type = RunActivity
+	}
+	if options.Start.IsZero() {
+		options.Start = time.Now().Add(-time.Hour).Truncate(time.Second)
+	}
+	if options.DesiredSpeed == 0 && options.TotalDuration == 0 && options.End.IsZero() {
+		options.DesiredSpeed = 10
+	}
+	activity, err := NewActivity(options)
+	r.NoError(err)
+	return activity
+}
+
+func newSeededActivity(r *require.Assertions, start time.Time, seed int64) *Activity {
+	activity := newActivity(r, &ActivityOptions{
+		Start:      start,
+		HeartRate:  &HeartRateOptions{},
+		RunCadence: &RunCadenceOptions{},
+		Stops:      &StopOptions{RandomPerHour: 10, TrafficLightDistance: 0.5},
+		Seed:       seed,
+	})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	return activity
+}
+
+func TestSeed(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// the same seed generates exactly the same activity
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	activity := newSeededActivity(r, start, 42)
+	a.Equal(int64(42), activity.Seed())
+	same := newSeededActivity(r, start, 42)
+	a.Equal(activity.Records(), same.Records())
+	a.Equal(activity.Stops(), same.Stops())
+	a.Equal(activity.Laps(), same.Laps())
+
+	b, err := activity.MarshalTCX()
+	r.NoError(err)
+	sameB, err := same.MarshalTCX()
+	r.NoError(err)
+	a.Equal(b, sameB)
+
+	// while another seed doesn't
+	other := newSeededActivity(r, start, 43)
+	a.NotEqual(activity.Records(), other.Records())
+
+	// and a new seed is chosen if none is set
+	activity = newSeededActivity(r, start, 0)
+	a.NotZero(activity.Seed())
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+
+	"github.com/renbou/jogmock/activities/internal/randutil"
+)
+
+// StridePoint is a single point of the stride length curve
+type StridePoint struct {
+	// speed in km/h
+	Speed float64
+	// length of a single step in m at this speed
+	Length float64
+}
+
+// RunCadenceOptions configures the cadence generated for runs. The number of steps per
+// minute is derived from the speed and the stride length at that speed, with some noise.
+// Like in all of the supported formats, the cadence of records is stored in strides
+// (pairs of steps) per minute, with the half stride kept in FractionalCadence.
+// The known cadences of imported points are kept.
+type RunCadenceOptions struct {
+	// The stride length curve. The length is linearly interpolated between
+	// the points, which must be sorted by speed
+	StrideCurve []StridePoint
+	// Relative noise of the cadence, e.g. 0.02 for up to 2% more or fewer steps
+	Noise float64
+}
+
+const maxCadenceNoise = 0.2
+
+var (
+	// DefaultStrideCurve is the stride length curve used by default,
+	// runners mostly speed up by taking longer steps rather than more of them
+	DefaultStrideCurve = []StridePoint{
+		{Speed: 6, Length: 0.75},
+		{Speed: 8, Length: 0.85},
+		{Speed: 10, Length: 1},
+		{Speed: 12, Length: 1.12},
+		{Speed: 15, Length: 1.35},
+		{Speed: 20, Length: 1.7},
+	}
+	DefaultCadenceNoise = 0.01
+)
+
+func (o *RunCadenceOptions) validateAndSetDefaults(activityType ActivityType) error {
+	if activityType != RunActivity {
+		return errors.New("cadence generation is only supported for runs")
+	}
+	if o.Noise < 0 || o.Noise > maxCadenceNoise {
+		return errors.New("cadence noise should be in range (0, 0.2)")
+	}
+
+	if len(o.StrideCurve) == 0 {
+		o.StrideCurve = DefaultStrideCurve
+	}
+	for i, point := range o.StrideCurve {
+		if point.Length <= 0 {
+			return errors.New("stride lengths must be positive")
+		}
+		if i > 0 && point.Speed <= o.StrideCurve[i-1].Speed {
+			return errors.New("stride curve points must be sorted by speed")
+		}
+	}
+
+	if o.Noise == 0 {
+		o.Noise = DefaultCadenceNoise
+	}
+	return nil
+}
+
+// strideLength returns the stride length of the curve at the given speed
+func (o *RunCadenceOptions) strideLength(speed float64) float64 {
+	curve := o.StrideCurve
+	if speed <= curve[0].Speed {
+		return curve[0].Length
+	}
+	for i := 1; i < len(curve); i++ {
+		if speed <= curve[i].Speed {
+			fraction := (speed - curve[i-1].Speed) / (curve[i].Speed - curve[i-1].Speed)
+			return curve[i-1].Length + (curve[i].Length-curve[i-1].Length)*fraction
+		}
+	}
+	return curve[len(curve)-1].Length
+}
+
+// steps returns the number of steps per minute at the given speed in km/h, including the noise
+func (o *RunCadenceOptions) steps(speed float64, rnd *randutil.Rand) float64 {
+	metersPerMinute := speed * 1000 / 60
+	return metersPerMinute / o.strideLength(speed) * (1 + rnd.Float64InRange(-o.Noise, o.Noise))
+}
+
+// addRunCadence generates the cadence of all moving records for which it is unknown
+func (a *Activity) addRunCadence() {
+	if a.runCadence == nil {
+		return
+	}
+
+	for i := range a.records {
+		record := &a.records[i]
+		if record.Cadence != 0 || record.Speed == 0 {
+			continue
+		}
+		steps := math.Min(2*math.MaxUint8, math.Round(a.runCadence.steps(record.Speed, a.rand)))
+		record.Cadence = uint8(steps / 2)
+		record.FractionalCadence = math.Mod(steps, 2) / 2
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/renbou/jogmock/activities/internal/tcx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCadence(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{DesiredSpeed: 12, RunCadence: &RunCadenceOptions{}})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+
+	for _, record := range activity.Records() {
+		r.Contains([]float64{0, 0.5}, record.FractionalCadence)
+		if record.Speed < 5 {
+			// the steps are rounded, which is too coarse for comparing when walking
+			continue
+		}
+		r.NotZero(record.Cadence)
+		// the cadence is derived from the speed using the stride length
+		steps := record.Speed * 1000 / 60 / activity.runCadence.strideLength(record.Speed)
+		a.InEpsilon(steps, 2*(float64(record.Cadence)+record.FractionalCadence), DefaultCadenceNoise+0.01)
+	}
+	// about 178 steps per minute at 12 km/h
+	a.InDelta(89, recordAt(activity, 3).Cadence, 3)
+
+	// the curve is interpolated and clamped at its ends
+	options := &RunCadenceOptions{StrideCurve: []StridePoint{{Speed: 10, Length: 1}, {Speed: 20, Length: 2}}}
+	r.NoError(options.validateAndSetDefaults(RunActivity))
+	a.Equal(1.0, options.strideLength(5))
+	a.Equal(1.5, options.strideLength(15))
+	a.Equal(2.0, options.strideLength(25))
+
+	// the running cadence is exported to the extensions of TCX trackpoints
+	b, err := activity.MarshalTCX()
+	r.NoError(err)
+	file, err := tcx.UnmarshalTCX(b)
+	r.NoError(err)
+	trackpoint := file.Activities[0].Laps[0].Tracks[0].Trackpoints[100]
+	a.Nil(trackpoint.Cadence)
+	a.Equal(activity.Records()[100].Cadence, trackpoint.CadenceValue())
+
+	// known cadences are kept
+	activity = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	activity.runCadence = &RunCadenceOptions{}
+	r.NoError(activity.runCadence.validateAndSetDefaults(RunActivity))
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	a.Equal(uint8(85), activity.Records()[400].Cadence)
+	a.NotZero(activity.Records()[100].Cadence)
+
+	// Error tests
+	for _, options := range []*ActivityOptions{
+		{Type: RideActivity, RunCadence: &RunCadenceOptions{}},
+		{Type: RunActivity, RunCadence: &RunCadenceOptions{Noise: 0.5}},
+		{Type: RunActivity, RunCadence: &RunCadenceOptions{StrideCurve: []StridePoint{{Speed: 10, Length: 0}}}},
+		{Type: RunActivity, RunCadence: &RunCadenceOptions{
+			StrideCurve: []StridePoint{{Speed: 10, Length: 1}, {Speed: 8, Length: 1.2}},
+		}},
+	} {
+		options.Start = time.Now()
+		options.DesiredSpeed = 10
+		_, err := NewActivity(options)
+		a.Error(err)
+	}
+}
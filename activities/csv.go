@@ -0,0 +1,75 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+)
+
+// CSVHeader is the header of the exported CSV files. The speed is in km/h,
+// the distance in km, and unknown values are left empty.
+var CSVHeader = []string{
+	"timestamp", "latitude", "longitude", "altitude", "speed", "distance", "heart_rate", "cadence", "power",
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatUint formats values which are unknown if 0
+func formatUint(value uint) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+// formatCadence formats the cadence along with its fractional part
+func formatCadence(record *Record) string {
+	if record.Cadence == 0 {
+		return ""
+	}
+	return formatFloat(float64(record.Cadence) + record.FractionalCadence)
+}
+
+// MarshalCSV exports the records of the built activity as CSV with the CSVHeader
+func (a *Activity) MarshalCSV() ([]byte, error) {
+	if len(a.records) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	buffer := new(bytes.Buffer)
+	wr := csv.NewWriter(buffer)
+	if err := wr.Write(CSVHeader); err != nil {
+		return nil, err
+	}
+	for i := range a.records {
+		record := &a.records[i]
+		altitude := ""
+		if record.Altitude != nil {
+			altitude = formatFloat(*record.Altitude)
+		}
+		if err := wr.Write([]string{
+			record.Timestamp.UTC().Format(time.RFC3339Nano),
+			formatFloat(record.Lat),
+			formatFloat(record.Lon),
+			altitude,
+			formatFloat(record.Speed),
+			formatFloat(record.Distance),
+			formatUint(uint(record.HeartRate)),
+			formatCadence(record),
+			formatUint(uint(record.Power)),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	wr.Flush()
+	if err := wr.Error(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
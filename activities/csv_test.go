@@ -0,0 +1,47 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalCSV(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	b, err := activity.MarshalCSV()
+	r.NoError(err)
+
+	rows, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	r.NoError(err)
+	r.Len(rows, len(activity.Records())+1)
+	a.Equal(CSVHeader, rows[0])
+	first := rows[1]
+	a.Equal("2022-04-01T06:30:00Z", first[0])
+	for i, expected := range map[int]float64{1: 59.9234, 2: 30.3052, 3: 20, 5: 0} {
+		value, err := strconv.ParseFloat(first[i], 64)
+		r.NoError(err)
+		a.InDelta(expected, value, 1e-9)
+	}
+	a.Equal("100", first[6])
+	a.Equal("", first[7])
+	// heart rate is unknown during the second lap, cadence is only known at the end
+	last := rows[len(rows)-1]
+	a.Equal("2022-04-01T06:36:40Z", last[0])
+	a.Equal("10", last[3])
+	a.Equal("", last[6])
+	a.Equal("85", last[7])
+
+	// Error tests
+	_, err = newActivity(r, nil).MarshalCSV()
+	a.ErrorIs(err, ErrNoRecords)
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrUnknownDesiredSpeed = errors.New("desired speed is unknown, it must be set unless the activity is built from a whole route")
+
+// setDesiredSpeed sets the desired speed of the activity, which is derived
+// from the target duration, and creates the speed generator for it
+func (a *Activity) setDesiredSpeed(speed float64) error {
+	if speed < minDesiredSpeed {
+		return fmt.Errorf("desired speed for the total duration is less than %fkm/h", minDesiredSpeed)
+	}
+	a.desiredSpeed = speed
+	a.speed = a.newSpeedGenerator(speed)
+	return nil
+}
+
+// retime scales the moving time of the records so that the activity lasts exactly the
+// target duration, which corrects the drift of the generated speed and the time lost
+// during the fade-in and fade-out. The stops keep their durations and are only moved.
+func (a *Activity) retime() error {
+	if a.targetDuration == 0 || len(a.records) < 2 {
+		return nil
+	}
+
+	stopped := a.StoppedDuration(a.startTime, a.startTime.Add(a.TotalDuration()))
+	moving := a.TotalDuration() - stopped
+	if moving <= 0 || a.targetDuration <= stopped {
+		return errors.New("stops of the activity don't fit into the total duration")
+	}
+	scale := float64(a.targetDuration-stopped) / float64(moving)
+
+	var stoppedBefore time.Duration
+	next := 0
+	for i := range a.records {
+		record := &a.records[i]
+		timestamp := record.Timestamp
+		elapsed := timestamp.Sub(a.startTime) - stoppedBefore
+		record.Timestamp = a.startTime.Add(time.Duration(float64(elapsed)*scale) + stoppedBefore)
+		record.Speed /= scale
+		// the stop made at the record moves along with it, shifting the following records
+		for next < len(a.stops) && a.stops[next].Start.Equal(timestamp) {
+			a.stops[next].Start = record.Timestamp
+			stoppedBefore += a.stops[next].Duration
+			next++
+		}
+	}
+	// stops are never made at the last record, which ends the activity exactly at the target
+	a.records[len(a.records)-1].Timestamp = a.startTime.Add(a.targetDuration)
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalDuration(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// the activity lasts exactly the total duration, with the desired speed derived from the route
+	for _, generator := range []*SpeedGeneratorOptions{nil, {Type: NoiseGenerator}, {Type: MeanRevertingGenerator}} {
+		activity := newActivity(r, &ActivityOptions{
+			TotalDuration:  32*time.Minute + 30*time.Second,
+			SpeedGenerator: generator,
+			HeartRate:      &HeartRateOptions{},
+			RunCadence:     &RunCadenceOptions{},
+			Stops:          &StopOptions{RandomPerHour: 10},
+		})
+		r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+		a.InDelta((32*time.Minute + 30*time.Second).Seconds(), activity.TotalDuration().Seconds(), 1)
+
+		records := activity.Records()
+		for i := 1; i < len(records); i++ {
+			a.True(records[i].Timestamp.After(records[i-1].Timestamp))
+			a.NotZero(records[i].HeartRate)
+		}
+		// the stops are moved along with the records
+		for _, stop := range activity.Stops() {
+			a.Equal(stop.Duration, activity.StoppedDuration(stop.Start, stop.End()))
+		}
+	}
+
+	// custom generators are created for the speed derived from the route
+	var created []float64
+	activity := newActivity(r, &ActivityOptions{
+		TotalDuration: 30 * time.Minute,
+		SpeedGenerator: &SpeedGeneratorOptions{NewGenerator: func(desiredSpeed float64) SpeedGenerator {
+			created = append(created, desiredSpeed)
+			return constantSpeed(desiredSpeed)
+		}},
+	})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	r.Len(created, 1)
+	a.InDelta(activity.TotalDistance()/0.5, created[0], 0.01)
+	a.Equal(30*time.Minute, activity.TotalDuration())
+	// and the retiming only makes up for the fade-in and fade-out
+	a.InDelta(created[0], averageSpeed(activity, 0.5, 5), created[0]*0.05)
+
+	// the end time can be set instead, and the stops at the waypoints are kept
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	activity = newActivity(r, &ActivityOptions{
+		Start: start,
+		End:   start.Add(10 * time.Minute),
+		Stops: &StopOptions{WaypointDuration: time.Minute},
+	})
+	r.NoError(activity.BuildFromGPX([]byte(testSegmentsGPX)))
+	a.Equal(start.Add(10*time.Minute), activity.Start().Add(activity.TotalDuration()))
+	r.Len(activity.Stops(), 1)
+	a.Equal(9*time.Minute, activity.MovingDuration())
+
+	// the timestamps of timed routes are scaled to the total duration
+	activity = newActivity(r, &ActivityOptions{TotalDuration: 5 * time.Minute, SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromGPX([]byte(testTimedGPX)))
+	a.Equal(5*time.Minute, activity.TotalDuration())
+
+	// and the timing of added records is corrected when finalizing
+	activity = newActivity(r, &ActivityOptions{TotalDuration: 20 * time.Minute, DesiredSpeed: 10})
+	r.NoError(activity.AddRecord(&Record{Lat: 59.9, Lon: 30.3}))
+	r.NoError(activity.AddRecord(&Record{Lat: 59.92, Lon: 30.3}))
+	r.NoError(activity.Finalize())
+	a.Equal(20*time.Minute, activity.TotalDuration())
+
+	// the fade-out doesn't crawl once it is over
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	a.InDelta(10, activity.TotalDistance()/activity.TotalDuration().Hours(), 1)
+
+	// Error tests
+	for _, options := range []*ActivityOptions{
+		{TotalDuration: -time.Minute},
+		{TotalDuration: time.Hour, End: time.Now()},
+		{End: time.Now().Add(-3 * time.Hour)},
+		{TotalDuration: time.Hour, DesiredSpeed: 0.5},
+		{TotalDuration: time.Hour, Power: &PowerOptions{}},
+		{TotalDuration: time.Hour, Workout: &WorkoutOptions{Steps: []WorkoutStep{{Distance: 1}}}},
+	} {
+		options.Type = RunActivity
+		options.Start = time.Now().Add(-2 * time.Hour)
+		_, err := NewActivity(options)
+		a.Error(err)
+	}
+
+	// the desired speed is unknown when adding records one by one
+	activity = newActivity(r, &ActivityOptions{TotalDuration: time.Hour})
+	a.ErrorIs(activity.AddRecord(&Record{Lat: 59.9, Lon: 30.3}), ErrUnknownDesiredSpeed)
+	// the desired speed for the duration is too low
+	activity = newActivity(r, &ActivityOptions{TotalDuration: 100 * time.Hour})
+	a.Error(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	// and the stops don't fit into the duration
+	activity = newActivity(r, &ActivityOptions{TotalDuration: 10 * time.Minute, Stops: &StopOptions{WaypointDuration: time.Hour}})
+	a.Error(activity.BuildFromGPX([]byte(testSegmentsGPX)))
+}
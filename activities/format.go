@@ -0,0 +1,110 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+
+	"github.com/renbou/jogmock/activities/internal/polyline"
+)
+
+// RouteFormat is the format of a file which an activity can be built from
+type RouteFormat int
+
+const (
+	UnknownRouteFormat RouteFormat = iota
+	GPXRouteFormat
+	TCXRouteFormat
+	GeoJSONRouteFormat
+	KMLRouteFormat
+	PolylineRouteFormat
+)
+
+var ErrUnknownRouteFormat = errors.New("unknown route format, only GPX, TCX, GeoJSON, KML and encoded polylines are known")
+
+func (f RouteFormat) String() string {
+	switch f {
+	case GPXRouteFormat:
+		return "GPX"
+	case TCXRouteFormat:
+		return "TCX"
+	case GeoJSONRouteFormat:
+		return "GeoJSON"
+	case KMLRouteFormat:
+		return "KML"
+	case PolylineRouteFormat:
+		return "encoded polyline"
+	default:
+		return "unknown"
+	}
+}
+
+// utf8BOM is skipped if present at the beginning of the file
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// DetectRouteFormat detects the format of the route by its contents: XML files
+// are detected by their root element, valid JSON is considered to be GeoJSON, and
+// files containing only the characters of the polyline encoding are polylines
+func DetectRouteFormat(b []byte) RouteFormat {
+	b = bytes.TrimSpace(trimBOM(b))
+	if len(b) == 0 {
+		return UnknownRouteFormat
+	}
+
+	if b[0] == '<' {
+		decoder := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return UnknownRouteFormat
+			}
+			if start, ok := token.(xml.StartElement); ok {
+				switch start.Name.Local {
+				case "gpx":
+					return GPXRouteFormat
+				case "TrainingCenterDatabase":
+					return TCXRouteFormat
+				case "kml":
+					return KMLRouteFormat
+				}
+				return UnknownRouteFormat
+			}
+		}
+	}
+
+	if json.Valid(b) {
+		return GeoJSONRouteFormat
+	}
+	if polyline.IsPolyline(string(b)) {
+		return PolylineRouteFormat
+	}
+	return UnknownRouteFormat
+}
+
+func trimBOM(b []byte) []byte {
+	return bytes.TrimPrefix(b, utf8BOM)
+}
+
+// BuildFromRoute detects the format of the route using DetectRouteFormat and builds
+// the activity from it using the default options of the format. Polylines are
+// expected to be encoded with the DefaultPolylinePrecision.
+func (a *Activity) BuildFromRoute(b []byte) error {
+	b = trimBOM(b)
+	switch DetectRouteFormat(b) {
+	case GPXRouteFormat:
+		return a.BuildFromGPX(b)
+	case TCXRouteFormat:
+		return a.BuildFromTCX(b)
+	case GeoJSONRouteFormat:
+		return a.BuildFromGeoJSON(b)
+	case KMLRouteFormat:
+		return a.BuildFromKML(b)
+	case PolylineRouteFormat:
+		return a.BuildFromPolyline(string(b), DefaultPolylinePrecision)
+	default:
+		return ErrUnknownRouteFormat
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the same route of about 2.2 km in each of the formats, the KML track is timed
+var testRoutes = map[RouteFormat]string{
+	GPXRouteFormat: `<?xml version="1.0"?><gpx version="1.1"><trk><trkseg>
+		<trkpt lat="59.9234" lon="30.3052"></trkpt><trkpt lat="59.9434" lon="30.3052"></trkpt>
+	</trkseg></trk></gpx>`,
+	TCXRouteFormat: `<TrainingCenterDatabase><Courses><Course><Name>Route</Name><Track>
+		<Trackpoint><Position><LatitudeDegrees>59.9234</LatitudeDegrees><LongitudeDegrees>30.3052</LongitudeDegrees></Position></Trackpoint>
+		<Trackpoint><Position><LatitudeDegrees>59.9434</LatitudeDegrees><LongitudeDegrees>30.3052</LongitudeDegrees></Position></Trackpoint>
+	</Track></Course></Courses></TrainingCenterDatabase>`,
+	GeoJSONRouteFormat: "\xef\xbb\xbf" + `{"type": "Feature", "properties": {"name": "Route"},
+		"geometry": {"type": "LineString", "coordinates": [[30.3052, 59.9234], [30.3052, 59.9434]]}}`,
+	KMLRouteFormat: `<kml xmlns:gx="http://www.google.com/kml/ext/2.2"><Placemark><name>Route</name><gx:Track>
+		<when>2022-04-01T06:30:00Z</when><when>2022-04-01T06:43:20Z</when>
+		<gx:coord>30.3052 59.9234 20</gx:coord><gx:coord>30.3052 59.9434 30</gx:coord>
+	</gx:Track></Placemark></kml>`,
+	PolylineRouteFormat: "  gxvlJo~}wD_|B?\n",
+}
+
+func TestDetectRouteFormat(t *testing.T) {
+	a := assert.New(t)
+
+	for format, contents := range testRoutes {
+		a.Equal(format, DetectRouteFormat([]byte(contents)), format.String())
+	}
+	a.Equal(UnknownRouteFormat, DetectRouteFormat(nil))
+	a.Equal(UnknownRouteFormat, DetectRouteFormat([]byte(`<html></html>`)))
+	a.Equal(UnknownRouteFormat, DetectRouteFormat([]byte(`<gpx`)))
+	a.Equal(UnknownRouteFormat, DetectRouteFormat([]byte("lat,lon\n59.9,30.3")))
+}
+
+func TestBuildFromRoute(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	for format, contents := range testRoutes {
+		activity := newActivity(r, nil)
+		r.NoError(activity.BuildFromRoute([]byte(contents)), format.String())
+		a.InDelta(2.22, activity.TotalDistance(), 0.01, format.String())
+	}
+
+	// the timestamps of KML tracks are kept, 2.22 km in 800 s
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromKML([]byte(testRoutes[KMLRouteFormat])))
+	a.Equal("Route", activity.Name())
+	a.Equal(int64(800), int64(activity.TotalDuration().Seconds()))
+	a.InDelta(2.22/800*3600, activity.Records()[100].Speed, 0.1)
+
+	// parsed records can be used to build the activity
+	records, err := ParseGeoJSON([]byte(testRoutes[GeoJSONRouteFormat][3:]))
+	r.NoError(err)
+	r.Len(records, 2)
+	a.Equal(59.9434, records[1].Lat)
+	records, err = ParseKML([]byte(testRoutes[KMLRouteFormat]))
+	r.NoError(err)
+	r.Len(records, 2)
+	r.NotNil(records[1].Altitude)
+	a.Equal(30.0, *records[1].Altitude)
+	a.False(records[1].Timestamp.IsZero())
+	records, err = ParsePolyline(testRoutes[PolylineRouteFormat], DefaultPolylinePrecision)
+	r.NoError(err)
+	r.Len(records, 2)
+	a.InDelta(59.9234, records[0].Lat, 1e-9)
+	a.InDelta(30.3052, records[0].Lon, 1e-9)
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromRecords(records))
+	a.InDelta(2.22, activity.TotalDistance(), 0.01)
+
+	// Error tests
+	a.ErrorIs(newActivity(r, nil).BuildFromRoute([]byte("lat,lon")), ErrUnknownRouteFormat)
+	a.Error(newActivity(r, nil).BuildFromRecords(nil))
+	a.Error(newActivity(r, nil).BuildFromGeoJSON([]byte(`{"type": "Point", "coordinates": [30.3, 59.9]}`)))
+	a.Error(newActivity(r, nil).BuildFromKML([]byte(`<kml></kml>`)))
+	a.Error(newActivity(r, nil).BuildFromPolyline("_p~iF", DefaultPolylinePrecision))
+	_, err = ParseGeoJSON([]byte(`{`))
+	a.Error(err)
+	_, err = ParseKML([]byte(`<kml>`))
+	a.Error(err)
+}
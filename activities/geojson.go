@@ -0,0 +1,118 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/renbou/jogmock/activities/internal/geojson"
+)
+
+// ParseGeoJSON returns the points of all LineString and MultiLineString
+// geometries of a GeoJSON file as records containing only the position
+// and the altitude, if it is specified as the third coordinate
+func ParseGeoJSON(b []byte) ([]Record, error) {
+	object, err := geojson.UnmarshalGeoJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := geoJSONSegments(object)
+	if err != nil {
+		return nil, err
+	}
+	return joinSegments(segments), nil
+}
+
+func geoJSONSegments(object *geojson.Object) ([]pointSegment, error) {
+	segments, err := object.Segments()
+	if err != nil {
+		return nil, err
+	}
+
+	pointSegments := make([]pointSegment, len(segments))
+	for i, segment := range segments {
+		pointSegments[i].name = segment.Name
+		for _, point := range segment.Points {
+			pointSegments[i].points = append(pointSegments[i].points, Record{
+				Lat:      point.Lat,
+				Lon:      point.Lon,
+				Altitude: point.Elevation,
+			})
+		}
+	}
+	return pointSegments, nil
+}
+
+// BuildFromGeoJSON builds the activity from all of the line strings of a GeoJSON
+// file and finalizes it. Named Point features are used as lap markers the same way
+// as GPX waypoints, and the name and description properties are used unless set.
+func (a *Activity) BuildFromGeoJSON(b []byte) error {
+	object, err := geojson.UnmarshalGeoJSON(b)
+	if err != nil {
+		return err
+	}
+	segments, err := geoJSONSegments(object)
+	if err != nil {
+		return err
+	}
+	waypoints, err := object.Waypoints()
+	if err != nil {
+		return err
+	}
+
+	if a.name == "" {
+		a.name = object.Name()
+	}
+	if a.description == "" {
+		a.description = object.Description()
+	}
+
+	route := &importedRoute{segments: segments, waypointLaps: true}
+	for _, point := range waypoints {
+		route.waypoints = append(route.waypoints, waypoint{name: point.Name, lat: point.Lat, lon: point.Lon})
+	}
+	return a.build(route)
+}
+
+// MarshalGeoJSON exports the built activity as a GeoJSON Feature with a LineString
+// geometry. The altitude is added as the third coordinate when it's known, and the
+// timestamps of the points are stored in the coordTimes property, like togeojson does.
+func (a *Activity) MarshalGeoJSON() ([]byte, error) {
+	if len(a.records) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	coordinates := make([][]float64, len(a.records))
+	coordTimes := make([]string, len(a.records))
+	for i := range a.records {
+		record := &a.records[i]
+		coordinates[i] = []float64{record.Lon, record.Lat}
+		if record.Altitude != nil {
+			coordinates[i] = append(coordinates[i], *record.Altitude)
+		}
+		coordTimes[i] = record.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	rawCoordinates, err := json.Marshal(coordinates)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&geojson.Object{
+		Type: geojson.TypeFeature,
+		Properties: map[string]interface{}{
+			"name":        a.name,
+			"description": a.description,
+			"type":        a.activityType.String(),
+			"time":        a.startTime.UTC().Format(time.RFC3339Nano),
+			// totals in meters and seconds
+			"distance":   a.TotalDistance() * 1000,
+			"duration":   a.TotalDuration().Seconds(),
+			"coordTimes": coordTimes,
+		},
+		Geometry: &geojson.Object{
+			Type:        geojson.TypeLineString,
+			Coordinates: rawCoordinates,
+		},
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalGeoJSON(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromGPX([]byte(testTimedGPX)))
+	b, err := activity.MarshalGeoJSON()
+	r.NoError(err)
+
+	var feature struct {
+		Type       string
+		Properties struct {
+			Type       string
+			Distance   float64
+			Duration   float64
+			CoordTimes []string
+		}
+		Geometry struct {
+			Type        string
+			Coordinates [][]float64
+		}
+	}
+	r.NoError(json.Unmarshal(b, &feature))
+	a.Equal("Feature", feature.Type)
+	a.Equal("Run", feature.Properties.Type)
+	a.InDelta(activity.TotalDistance()*1000, feature.Properties.Distance, 0.001)
+	a.Equal(500.5, feature.Properties.Duration)
+	a.Equal("LineString", feature.Geometry.Type)
+	r.Len(feature.Geometry.Coordinates, len(activity.Records()))
+	r.Len(feature.Properties.CoordTimes, len(activity.Records()))
+	a.Equal("2022-04-01T06:38:20.5Z", feature.Properties.CoordTimes[len(activity.Records())-1])
+	r.Len(feature.Geometry.Coordinates[0], 3)
+	a.InDelta(30.3052, feature.Geometry.Coordinates[0][0], 1e-9)
+	a.InDelta(59.9234, feature.Geometry.Coordinates[0][1], 1e-9)
+	a.Equal(20.0, feature.Geometry.Coordinates[0][2])
+
+	// the exported route can be imported again
+	records, err := ParseGeoJSON(b)
+	r.NoError(err)
+	r.Len(records, len(activity.Records()))
+	a.InDelta(activity.TotalDistance(), pathDistance(records), 0.001)
+
+	// Error tests
+	_, err = newActivity(r, nil).MarshalGeoJSON()
+	a.ErrorIs(err, ErrNoRecords)
+}
@@ -0,0 +1,167 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"time"
+
+	"github.com/renbou/jogmock/activities/internal/gpx"
+)
+
+// gpxCreator is the creator of the exported GPX files
+const gpxCreator = "jogmock"
+
+// GPXOptions configures how the contents of a GPX file are used to build an activity
+type GPXOptions struct {
+	// SegmentLaps adds a lap marker at the beginning of each track segment or
+	// route after the first one, otherwise the segments are simply joined
+	SegmentLaps bool
+	// WaypointLaps adds a lap marker at the point of the activity closest to each
+	// named waypoint, as long as the waypoint lies within 200 m of the activity
+	WaypointLaps bool
+}
+
+// DefaultGPXOptions joins all of the segments and uses named waypoints as lap markers
+var DefaultGPXOptions = GPXOptions{
+	WaypointLaps: true,
+}
+
+// BuildFromGPX builds the activity from a GPX file using DefaultGPXOptions
+func (a *Activity) BuildFromGPX(b []byte) error {
+	return a.BuildFromGPXWithOptions(b, &DefaultGPXOptions)
+}
+
+// BuildFromGPXWithOptions builds the activity from all of the track segments of
+// a GPX file, or its routes if it doesn't contain any tracks, and finalizes it.
+// The name and description of the GPX file are used unless they were already set.
+// The timestamps of the points are used if ActivityOptions.SourceTiming was set.
+func (a *Activity) BuildFromGPXWithOptions(b []byte, options *GPXOptions) error {
+	gpxFile, err := gpx.UnmarshalGPX(b)
+	if err != nil {
+		return err
+	}
+	segments, err := gpxFile.Segments()
+	if err != nil {
+		return err
+	}
+
+	if a.name == "" {
+		a.name = gpxFile.Name()
+	}
+	if a.description == "" {
+		a.description = gpxFile.Description()
+	}
+
+	pointSegments := make([]pointSegment, len(segments))
+	for i, segment := range segments {
+		pointSegments[i].name = segment.Name
+		for _, point := range segment.Points {
+			record := Record{
+				Lat:       point.Lat,
+				Lon:       point.Lon,
+				Altitude:  point.Elevation,
+				HeartRate: point.HeartRate(),
+				Cadence:   point.Cadence(),
+			}
+			if point.Time != nil {
+				record.Timestamp = *point.Time
+			}
+			pointSegments[i].points = append(pointSegments[i].points, record)
+		}
+	}
+	var waypoints []waypoint
+	for _, point := range gpxFile.Waypoints {
+		if point.Name != "" {
+			waypoints = append(waypoints, waypoint{name: point.Name, lat: point.Lat, lon: point.Lon})
+		}
+	}
+	return a.build(&importedRoute{
+		segments:     pointSegments,
+		waypoints:    waypoints,
+		segmentLaps:  options.SegmentLaps,
+		waypointLaps: options.WaypointLaps,
+	})
+}
+
+// gpxExtensions returns the track point extensions containing the
+// heart rate and cadence of the record, or nil if both are unknown
+func gpxExtensions(record *Record) *gpx.Extensions {
+	if record.HeartRate == 0 && record.Cadence == 0 {
+		return nil
+	}
+	extension := new(gpx.TrackPointExtension)
+	if record.HeartRate != 0 {
+		heartRate := record.HeartRate
+		extension.HeartRate = &heartRate
+	}
+	if record.Cadence != 0 {
+		cadence := record.Cadence
+		extension.Cadence = &cadence
+	}
+	return &gpx.Extensions{TrackPointExtension: extension}
+}
+
+// gpxPoint converts the record to a GPX point with the given timestamp
+func gpxPoint(record *Record, timestamp time.Time) gpx.Point {
+	timestamp = timestamp.UTC()
+	return gpx.Point{
+		Lat:        record.Lat,
+		Lon:        record.Lon,
+		Elevation:  record.Altitude,
+		Time:       &timestamp,
+		Extensions: gpxExtensions(record),
+	}
+}
+
+// MarshalGPX exports the built activity as a GPX 1.1 file containing all of the records
+// with their timestamps. Each lap of the activity is exported as a separate track named
+// after the lap, which can be imported again using GPXOptions.SegmentLaps. The tracks
+// are split into segments at the stops, so that the stops are seen as pauses.
+func (a *Activity) MarshalGPX() ([]byte, error) {
+	if len(a.records) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	trackType := ""
+	switch a.activityType {
+	case RunActivity:
+		trackType = "running"
+	case RideActivity:
+		trackType = "cycling"
+	}
+
+	start := a.startTime.UTC()
+	stops := a.stops
+	var tracks []gpx.Track
+	for _, lap := range a.Laps() {
+		name := lap.Name
+		if len(tracks) == 0 {
+			name = a.name
+		}
+		segments := []gpx.TrackSegment{{}}
+		for i := lap.first; i < lap.last; i++ {
+			record := &a.records[i]
+			segment := &segments[len(segments)-1]
+			segment.Points = append(segment.Points, gpxPoint(record, record.Timestamp))
+
+			// a new segment begins at the same position after each stop
+			if len(stops) > 0 && stops[0].Start.Equal(record.Timestamp) {
+				segments = append(segments, gpx.TrackSegment{
+					Points: []gpx.Point{gpxPoint(record, stops[0].End())},
+				})
+				stops = stops[1:]
+			}
+		}
+		tracks = append(tracks, gpx.Track{Name: name, Type: trackType, Segments: segments})
+	}
+
+	return gpx.MarshalGPX(&gpx.GPX{
+		Creator: gpxCreator,
+		Metadata: &gpx.Metadata{
+			Name:        a.name,
+			Description: a.description,
+			Time:        &start,
+		},
+		Tracks: tracks,
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// each segment is about 1.1 km long, the second one has no elevation
+const testSegmentsGPX = `<gpx version="1.1">
+  <wpt lat="59.9334" lon="30.3052"><name>Turn</name></wpt>
+  <wpt lat="59.9334" lon="30.3052"></wpt>
+  <wpt lat="60.5" lon="30.3052"><name>Far away</name></wpt>
+  <trk>
+    <name>Out</name>
+    <trkseg>
+      <trkpt lat="59.9234" lon="30.3052"><ele>20</ele></trkpt>
+      <trkpt lat="59.9284" lon="30.3052"><ele>25</ele></trkpt>
+      <trkpt lat="59.9334" lon="30.3052"><ele>30</ele></trkpt>
+    </trkseg>
+  </trk>
+  <trk>
+    <name>Back</name>
+    <trkseg>
+      <trkpt lat="59.9284" lon="30.3052"></trkpt>
+      <trkpt lat="59.9234" lon="30.3052"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestBuildFromGPX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, nil)
+	r.NoError(activity.BuildFromGPXWithOptions([]byte(testSegmentsGPX), &GPXOptions{
+		SegmentLaps:  true,
+		WaypointLaps: true,
+	}))
+	a.Equal("Out", activity.Name())
+
+	// the segment lap and the waypoint lap both begin at the turn
+	markers := activity.LapMarkers()
+	r.Len(markers, 2)
+	a.InDelta(1.11, markers[0].Distance, 0.01)
+	a.InDelta(markers[0].Distance, markers[1].Distance, 0.01)
+	a.ElementsMatch([]string{"Back", "Turn"}, []string{markers[0].Name, markers[1].Name})
+	a.InDelta(2.22, activity.TotalDistance(), 0.01)
+
+	// the altitude is only known along the first segment
+	records := activity.Records()
+	r.NotNil(records[0].Altitude)
+	a.Equal(20.0, *records[0].Altitude)
+	for _, record := range records {
+		if record.Distance < markers[0].Distance {
+			r.NotNil(record.Altitude)
+			a.InDelta(20+record.Distance/markers[0].Distance*10, *record.Altitude, 0.1)
+		} else if record.Distance > markers[0].Distance+0.01 {
+			a.Nil(record.Altitude)
+		}
+	}
+
+	// by default segments are joined without lap markers
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromGPX([]byte(testSegmentsGPX)))
+	r.Len(activity.LapMarkers(), 1)
+	a.Equal("Turn", activity.LapMarkers()[0].Name)
+}
+
+func TestBuildFromGPXWithoutElevation(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, nil)
+	r.NoError(activity.BuildFromGPX([]byte(`<gpx version="1.1"><rte>
+		<rtept lat="59.9234" lon="30.3052"></rtept><rtept lat="59.9334" lon="30.3052"></rtept>
+	</rte></gpx>`)))
+	for _, record := range activity.Records() {
+		a.Nil(record.Altitude)
+	}
+
+	// Error tests
+	a.Error(newActivity(r, nil).BuildFromGPX([]byte(`<gpx version="1.1"></gpx>`)))
+}
+
+func TestMarshalGPX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromGPX([]byte(testTimedGPX)))
+	b, err := activity.MarshalGPX()
+	r.NoError(err)
+	a.Contains(string(b), `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="jogmock">`)
+	a.Contains(string(b), `<type>running</type>`)
+
+	// the exported track keeps all of the records along with their timestamps
+	imported := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(imported.BuildFromGPX(b))
+	a.Equal(activity.Start(), imported.Start())
+	a.Equal(activity.TotalDuration(), imported.TotalDuration())
+	a.InDelta(activity.TotalDistance(), imported.TotalDistance(), 0.001)
+	r.Len(imported.Records(), len(activity.Records()))
+	for i, record := range imported.Records() {
+		a.Equal(activity.Records()[i].Timestamp, record.Timestamp)
+		a.Equal(*activity.Records()[i].Altitude, *record.Altitude)
+	}
+
+	// heart rate and cadence are kept in the track point extensions
+	activity = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	b, err = activity.MarshalGPX()
+	r.NoError(err)
+	a.Contains(string(b), `<TrackPointExtension xmlns="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">`)
+	imported = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(imported.BuildFromGPX(b))
+	r.Len(imported.Records(), len(activity.Records()))
+	for i, record := range imported.Records() {
+		a.Equal(activity.Records()[i].HeartRate, record.HeartRate)
+		a.Equal(activity.Records()[i].Cadence, record.Cadence)
+	}
+	a.Equal(uint8(100), imported.Records()[0].HeartRate)
+
+	// Error tests
+	_, err = newActivity(r, nil).MarshalGPX()
+	a.ErrorIs(err, ErrNoRecords)
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+)
+
+// GradePoint is a single point of the grade-adjusted pace curve
+type GradePoint struct {
+	// grade as rise over run, e.g. 0.1 for a 10% climb
+	Grade float64
+	// factor by which the speed on the flat is multiplied at this grade
+	Factor float64
+}
+
+// GradeOptions configures how the grade between the added records affects the
+// speed. Runs use the grade-adjusted pace curve, while rides keep the same power
+// as on the flat against gravity, rolling resistance and air drag, as modeled by
+// ActivityOptions.Ride. The speed is normalized over the whole route so that the
+// average speed is still DesiredSpeed.
+type GradeOptions struct {
+	// The grade-adjusted pace curve used for runs. The factor is linearly
+	// interpolated between the points, which must be sorted by grade
+	PaceCurve []GradePoint
+}
+
+const (
+	// grades are clamped to this range, steeper grades are usually caused by altitude noise
+	maxGrade = 0.3
+	// bounds of the speed factor for the ride power model
+	minRideGradeFactor = 0.2
+	maxRideGradeFactor = 2
+	// fraction by which the current grade factor approaches
+	// the factor of the next point during each second
+	gradeSmoothing = 0.2
+)
+
+var (
+	// DefaultPaceCurve is the grade-adjusted pace curve used for runs
+	// by default, runners are slowest on steep climbs and fastest on
+	// gentle descents, which can't be run down as fast when steep
+	DefaultPaceCurve = []GradePoint{
+		{Grade: -0.3, Factor: 0.7},
+		{Grade: -0.2, Factor: 0.95},
+		{Grade: -0.1, Factor: 1.1},
+		{Grade: -0.05, Factor: 1.07},
+		{Grade: 0, Factor: 1},
+		{Grade: 0.05, Factor: 0.85},
+		{Grade: 0.1, Factor: 0.72},
+		{Grade: 0.2, Factor: 0.52},
+		{Grade: 0.3, Factor: 0.4},
+	}
+)
+
+func (o *GradeOptions) validateAndSetDefaults() error {
+	if len(o.PaceCurve) == 0 {
+		o.PaceCurve = DefaultPaceCurve
+	}
+	for i, point := range o.PaceCurve {
+		if point.Factor <= 0 {
+			return errors.New("pace curve factors must be positive")
+		}
+		if i > 0 && point.Grade <= o.PaceCurve[i-1].Grade {
+			return errors.New("pace curve points must be sorted by grade")
+		}
+	}
+	return nil
+}
+
+// paceFactor returns the factor of the pace curve at the given grade
+func (o *GradeOptions) paceFactor(grade float64) float64 {
+	curve := o.PaceCurve
+	if grade <= curve[0].Grade {
+		return curve[0].Factor
+	}
+	for i := 1; i < len(curve); i++ {
+		if grade <= curve[i].Grade {
+			fraction := (grade - curve[i-1].Grade) / (curve[i].Grade - curve[i-1].Grade)
+			return curve[i-1].Factor + (curve[i].Factor-curve[i-1].Factor)*fraction
+		}
+	}
+	return curve[len(curve)-1].Factor
+}
+
+// gradeFactor returns the factor by which the speed on the flat changes at the given
+// grade, if the rider keeps the same power as needed for riding on the flat at speed
+func (o *RideOptions) gradeFactor(speed, grade float64) float64 {
+	// speed in m/s
+	flatSpeed := speed / 3.6
+	drag := 0.5 * airDensity * o.CdA
+	power := o.flatPower(flatSpeed)
+
+	angle := math.Atan(grade)
+	resistance := o.Mass * gravityAcceleration * (o.Crr*math.Cos(angle) + math.Sin(angle))
+	// the power needed to ride at speed v, which has a single positive root
+	// for the flat power since it is negative between 0 and the root
+	excess := func(v float64) float64 {
+		return v*(drag*v*v+resistance) - power
+	}
+
+	low, high := 0.0, flatSpeed
+	for excess(high) < 0 && high < flatSpeed*maxRideGradeFactor {
+		low, high = high, high*2
+	}
+	for i := 0; i < 50; i++ {
+		mid := (low + high) / 2
+		if excess(mid) < 0 {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return math.Max(minRideGradeFactor, math.Min(maxRideGradeFactor, (low+high)/2/flatSpeed))
+}
+
+// grade returns the grade between the records, which is 0 if any of the altitudes is unknown
+func grade(from, to *Record) float64 {
+	if from.Altitude == nil || to.Altitude == nil {
+		return 0
+	}
+	distance := from.DistanceTo(to) * 1000
+	if distance == 0 {
+		return 0
+	}
+	return math.Max(-maxGrade, math.Min(maxGrade, (*to.Altitude-*from.Altitude)/distance))
+}
+
+// gradeFactor returns the factor by which the speed changes when moving between the records
+func (a *Activity) gradeFactor(from, to *Record) float64 {
+	if a.grade == nil {
+		return 1
+	}
+	g := grade(from, to)
+	if a.activityType == RideActivity {
+		return a.ride.gradeFactor(a.desiredSpeed, g)
+	}
+	return a.grade.paceFactor(g)
+}
+
+// normalizeGrades computes the normalization of the grade factors over the
+// route, which is the distance-weighted harmonic mean of the factors. The average
+// speed stays the same since the time spent at each grade is inversely
+// proportional to the factor, making the time-weighted mean factor equal 1.
+func (a *Activity) normalizeGrades(points []Record) {
+	if a.grade == nil {
+		return
+	}
+	var distance, normalizedDistance float64
+	for i := 1; i < len(points); i++ {
+		d := points[i-1].DistanceTo(&points[i])
+		distance += d
+		normalizedDistance += d / a.gradeFactor(&points[i-1], &points[i])
+	}
+	if normalizedDistance > 0 {
+		a.gradeNormalization = distance / normalizedDistance
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gradeTestRoute returns a route of points about 100 m apart, which is flat
+// for the first 3 km, then climbs at 8% for 2 km and then descends at 4% for 4 km
+func gradeTestRoute() []Record {
+	var records []Record
+	altitude := 100.0
+	for i := 0; i <= 90; i++ {
+		switch {
+		case i > 50:
+			altitude -= 4
+		case i > 30:
+			altitude += 8
+		}
+		pointAltitude := altitude
+		records = append(records, Record{Lat: 59.9 + float64(i)*0.0009, Lon: 30.3, Altitude: &pointAltitude})
+	}
+	return records
+}
+
+// averageSpeed returns the average speed between the given distances in km
+func averageSpeed(activity *Activity, from, to float64) float64 {
+	var start, end *Record
+	for i := range activity.Records() {
+		record := &activity.Records()[i]
+		if start == nil && record.Distance >= from {
+			start = record
+		}
+		if record.Distance <= to {
+			end = record
+		}
+	}
+	return (end.Distance - start.Distance) / end.Timestamp.Sub(start.Timestamp).Hours()
+}
+
+func TestGradeAdjustedSpeed(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	for _, activityType := range []ActivityType{RunActivity, RideActivity} {
+		activity := newActivity(r, &ActivityOptions{Type: activityType, DesiredSpeed: 12, Grade: &GradeOptions{}})
+		r.NoError(activity.BuildFromRecords(gradeTestRoute()))
+
+		flat := averageSpeed(activity, 0.5, 2.9)
+		climb := averageSpeed(activity, 3.2, 4.9)
+		descent := averageSpeed(activity, 5.2, 8.5)
+		a.Less(climb, flat*0.9, activityType.String())
+		a.Greater(descent, flat, activityType.String())
+
+		// the speed over the whole route is the desired one, the fades are skipped since their duration
+		// is random, so the average speed is compared to the expected one for the sections in between
+		route := gradeTestRoute()
+		expectedDuration := 0.0
+		for _, section := range []struct {
+			point    int
+			distance float64
+		}{{0, 2.4}, {40, 1.7}, {60, 3.3}} {
+			factor := activity.gradeFactor(&route[section.point], &route[section.point+1]) / activity.gradeNormalization
+			expectedDuration += section.distance / (12 * factor)
+		}
+		a.InEpsilon(7.4/expectedDuration, 7.4/(2.4/flat+1.7/climb+3.3/descent), 0.05, activityType.String())
+
+		// the time-weighted mean of the factors over the route is 1
+		var distance, duration float64
+		for i := 1; i < len(route); i++ {
+			d := route[i-1].DistanceTo(&route[i])
+			distance += d
+			duration += d / (activity.gradeFactor(&route[i-1], &route[i]) / activity.gradeNormalization)
+		}
+		a.InDelta(1, distance/duration, 1e-9)
+	}
+}
+
+func TestGradeFactors(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	options := &GradeOptions{PaceCurve: []GradePoint{{-0.1, 1.2}, {0.1, 0.8}}}
+	r.NoError(options.validateAndSetDefaults())
+	a.Equal(1.2, options.paceFactor(-0.2))
+	a.InDelta(1.0, options.paceFactor(0), 1e-9)
+	a.InDelta(0.9, options.paceFactor(0.05), 1e-9)
+	a.Equal(0.8, options.paceFactor(0.3))
+
+	ride := &RideOptions{}
+	r.NoError(ride.validateAndSetDefaults())
+	a.Equal(DefaultRideMass, ride.Mass)
+
+	// the power on the flat is kept, so the speed doesn't change
+	a.InDelta(1, ride.gradeFactor(25, 0), 1e-6)
+	a.Less(ride.gradeFactor(25, 0.05), 0.6)
+	a.Greater(ride.gradeFactor(25, -0.02), 1.1)
+	a.InDelta(maxRideGradeFactor, ride.gradeFactor(25, -0.3), 1e-9)
+	// and heavier riders slow down more on climbs
+	a.Less((&RideOptions{Mass: 100, CdA: DefaultRideCdA, Crr: DefaultRideCrr}).gradeFactor(25, 0.05), ride.gradeFactor(25, 0.05))
+
+	// Error tests
+	a.Error((&GradeOptions{PaceCurve: []GradePoint{{0.1, 1}, {-0.1, 1}}}).validateAndSetDefaults())
+	a.Error((&GradeOptions{PaceCurve: []GradePoint{{0, 0}}}).validateAndSetDefaults())
+	a.Error((&RideOptions{Mass: -1}).validateAndSetDefaults())
+}
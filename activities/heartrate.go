@@ -0,0 +1,135 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// HeartRateOptions configures the synthetic heart rate of the records. The heart rate
+// follows the effort, which depends on the speed and the grade of runs, and on the power
+// needed to ride at the speed and grade, or the simulated power, of rides. The heart rate
+// lags behind the changes of the effort and slowly drifts up over time, as it does
+// during longer activities. The known heart rates of imported points are kept.
+type HeartRateOptions struct {
+	// Resting heart rate in bpm
+	Resting float64
+	// Maximum heart rate in bpm
+	Max float64
+	// Fraction of the heart rate reserve (between the resting and maximum heart rate)
+	// which is reached when moving at DesiredSpeed on the flat
+	Intensity float64
+	// Time in which the heart rate covers about 63% of the change after the effort changes
+	Lag time.Duration
+	// Cardiac drift in bpm per hour at the same effort
+	Drift float64
+}
+
+const (
+	maxHeartRate = 250
+	// fraction of the heart rate reserve at the start of the activity, since it usually
+	// doesn't begin at rest. The effort of runs changes by this fraction per unit of grade,
+	// running downhill doesn't get easier as fast as running uphill gets harder
+	initialHeartRateFraction = 0.25
+	runClimbEffort           = 6.0
+	runDescentEffort         = 3.0
+)
+
+var (
+	DefaultRestingHeartRate float64 = 60
+	DefaultMaxHeartRate     float64 = 190
+	DefaultHeartRateLag             = 30 * time.Second
+	DefaultHeartRateDrift   float64 = 5
+	DefaultIntensity                = 0.7
+)
+
+func (o *HeartRateOptions) validateAndSetDefaults() error {
+	if o.Resting < 0 || o.Max < 0 || o.Intensity < 0 || o.Lag < 0 || o.Drift < 0 {
+		return errors.New("heart rate options cannot be negative")
+	}
+	if o.Intensity > 1 {
+		return errors.New("heart rate intensity should be in range (0, 1)")
+	}
+
+	if o.Resting == 0 {
+		o.Resting = DefaultRestingHeartRate
+	}
+	if o.Max == 0 {
+		o.Max = DefaultMaxHeartRate
+	}
+	if o.Intensity == 0 {
+		o.Intensity = DefaultIntensity
+	}
+	if o.Lag == 0 {
+		o.Lag = DefaultHeartRateLag
+	}
+	if o.Drift == 0 {
+		o.Drift = DefaultHeartRateDrift
+	}
+
+	if o.Max > maxHeartRate {
+		return errors.New("max heart rate is too high (over 250 bpm)")
+	}
+	if o.Resting >= o.Max {
+		return errors.New("resting heart rate must be lower than the max heart rate")
+	}
+	return nil
+}
+
+// effort returns the effort of moving from prev to record relative to the effort
+// of moving at the desired speed on the flat, which is 1
+func (a *Activity) effort(prev, record *Record) float64 {
+	if a.power != nil {
+		return float64(record.Power) / a.power.Power
+	}
+
+	g := grade(prev, record)
+	if a.activityType == RideActivity {
+		return math.Max(0, a.ride.power(record.Speed/3.6, g)/a.ride.flatPower(a.desiredSpeed/3.6))
+	}
+
+	gradeEffort := runClimbEffort
+	if g < 0 {
+		gradeEffort = runDescentEffort
+	}
+	return math.Max(0, record.Speed/a.desiredSpeed*(1+gradeEffort*g))
+}
+
+// response returns the fraction of the change covered by the heart rate during the duration
+func (o *HeartRateOptions) response(duration time.Duration) float64 {
+	return 1 - math.Exp(-float64(duration)/float64(o.Lag))
+}
+
+// addHeartRate synthesizes the heart rate of all records for which it is unknown.
+// Known heart rates are kept, and the synthesized heart rate continues from them.
+func (a *Activity) addHeartRate() {
+	o := a.heartRate
+	if o == nil || len(a.records) == 0 {
+		return
+	}
+
+	reserve := o.Max - o.Resting
+	heartRate := o.Resting + reserve*initialHeartRateFraction
+	for i := range a.records {
+		record := &a.records[i]
+		if record.HeartRate != 0 {
+			heartRate = float64(record.HeartRate)
+			continue
+		}
+
+		if i > 0 {
+			prev := &a.records[i-1]
+			// the heart rate recovers towards the resting heart rate during stops
+			stopped := a.StoppedDuration(prev.Timestamp, record.Timestamp)
+			heartRate += (o.Resting - heartRate) * o.response(stopped)
+
+			target := o.Resting + reserve*math.Min(1, o.Intensity*a.effort(prev, record)) +
+				o.Drift*record.Timestamp.Sub(a.startTime).Hours()
+			// the heart rate approaches the target exponentially
+			heartRate += (math.Min(o.Max, target) - heartRate) * o.response(record.Timestamp.Sub(prev.Timestamp)-stopped)
+		}
+		record.HeartRate = uint8(math.Round(heartRate))
+	}
+}
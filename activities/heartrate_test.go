@@ -0,0 +1,87 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordAt returns the first record at or after the distance in km
+func recordAt(activity *Activity, distance float64) *Record {
+	records := activity.Records()
+	for i := range records {
+		if records[i].Distance >= distance {
+			return &records[i]
+		}
+	}
+	return &records[len(records)-1]
+}
+
+func heartRateAt(activity *Activity, distance float64) uint8 {
+	return recordAt(activity, distance).HeartRate
+}
+
+func TestSynthesizedHeartRate(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	for _, activityType := range []ActivityType{RunActivity, RideActivity} {
+		activity := newActivity(r, &ActivityOptions{
+			Type:         activityType,
+			DesiredSpeed: 12,
+			HeartRate:    &HeartRateOptions{Resting: 50, Max: 180},
+		})
+		r.NoError(activity.BuildFromRecords(gradeTestRoute()))
+
+		for _, record := range activity.Records() {
+			r.GreaterOrEqual(record.HeartRate, uint8(50), activityType.String())
+			r.LessOrEqual(record.HeartRate, uint8(180), activityType.String())
+		}
+		// the heart rate settles at the intensity on the flat, rises during the climb and
+		// falls during the descent, lagging behind the changes of the grade at 3 km and 5 km
+		flat := heartRateAt(activity, 2.9)
+		a.InDelta(50+130*DefaultIntensity, float64(flat), 10, activityType.String())
+		a.Less(heartRateAt(activity, 3.02), heartRateAt(activity, 3.3), activityType.String())
+		a.Greater(heartRateAt(activity, 4.9), flat+10, activityType.String())
+		a.Less(heartRateAt(activity, 6), heartRateAt(activity, 4.9), activityType.String())
+	}
+
+	// the heart rate drifts up at the same effort
+	activity := newActivity(r, &ActivityOptions{
+		Start:        time.Now().Add(-2 * time.Hour).Truncate(time.Second),
+		DesiredSpeed: 12,
+		HeartRate:    &HeartRateOptions{Drift: 10},
+		CommonSpeed:  &SpeedOptions{MinDuration: 20, MaxDuration: 20},
+		RareSpeed:    &SpeedOptions{MinDuration: 20, MaxDuration: 20},
+	})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 60.08, Lon: 30.3}}))
+	from, to := recordAt(activity, 5), recordAt(activity, 15)
+	a.InDelta(10*to.Timestamp.Sub(from.Timestamp).Hours(), float64(to.HeartRate)-float64(from.HeartRate), 2)
+
+	// known heart rates are kept, the first lap of the test file contains them
+	activity = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	activity.heartRate = &HeartRateOptions{}
+	r.NoError(activity.heartRate.validateAndSetDefaults())
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	a.Equal(uint8(100), activity.Records()[0].HeartRate)
+	last := activity.Records()[len(activity.Records())-1]
+	a.NotZero(last.HeartRate)
+	a.NotEqual(uint8(140), last.HeartRate)
+
+	// Error tests
+	for _, options := range []HeartRateOptions{
+		{Resting: -1},
+		{Max: 300},
+		{Resting: 100, Max: 90},
+		{Intensity: 1.5},
+	} {
+		_, err := NewActivity(&ActivityOptions{
+			Type: RunActivity, Start: time.Now(), DesiredSpeed: 10, HeartRate: &options,
+		})
+		a.Error(err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+)
+
+// pointSegment is a continuous sequence of imported points,
+// only containing the position and, optionally, the timestamp
+type pointSegment struct {
+	name   string
+	points []Record
+}
+
+// maximum distance in km between a waypoint and the
+// activity for the waypoint to be used as a lap marker
+const maxWaypointDistance = 0.2
+
+// waypoint is a named point of an imported route
+type waypoint struct {
+	name string
+	lat  float64
+	lon  float64
+}
+
+// importedRoute contains everything used to build the activity from an imported file
+type importedRoute struct {
+	segments  []pointSegment
+	waypoints []waypoint
+	// segmentLaps adds a lap marker at the beginning of each segment after the first one
+	segmentLaps bool
+	// waypointLaps adds a lap marker at the record closest to each of the waypoints
+	waypointLaps bool
+}
+
+// BuildFromRecords builds and finalizes the activity from records parsed from any
+// source, e.g. using ParseGeoJSON. Only the fields used by AddRecord are kept, and
+// the timestamps are used as well if ActivityOptions.SourceTiming was set.
+func (a *Activity) BuildFromRecords(records []Record) error {
+	if len(records) == 0 {
+		return errors.New("no records to build the activity from")
+	}
+	return a.build(&importedRoute{segments: []pointSegment{{points: records}}})
+}
+
+// joinSegments returns the points of all segments
+func joinSegments(segments []pointSegment) []Record {
+	var points []Record
+	for _, segment := range segments {
+		points = append(points, segment.points...)
+	}
+	return points
+}
+
+// build builds and finalizes the activity from the imported route. The timestamps
+// of the points are used if they are present and the activity was configured to keep
+// them, otherwise the speed of the activity is generated. The waypoints are matched
+// to the built records, which are used for the lap markers and the stops.
+func (a *Activity) build(route *importedRoute) error {
+	segments := route.segments
+	var points []Record
+	// distances at which the segments after the first one begin
+	var segmentStarts []float64
+	for i, segment := range segments {
+		if i > 0 {
+			segmentStarts = append(segmentStarts, pathDistance(points))
+		}
+		points = append(points, segment.points...)
+	}
+
+	// the whole route is known, so the speed needed for the total duration is known as well
+	if a.targetDuration > 0 {
+		if err := a.setDesiredSpeed(pathDistance(points) / a.targetDuration.Hours()); err != nil {
+			return err
+		}
+	}
+
+	timed, err := a.hasTimestamps(points)
+	if err != nil {
+		return err
+	}
+	a.timed = timed
+	if timed {
+		if err := a.buildFromTimedPoints(points); err != nil {
+			return err
+		}
+	} else {
+		a.normalizeGrades(points)
+		for i := range points {
+			if err := a.AddRecord(&points[i]); err != nil {
+				return err
+			}
+		}
+		if err := a.addFadeOut(); err != nil {
+			return err
+		}
+	}
+
+	if route.segmentLaps {
+		for i, distance := range segmentStarts {
+			a.addLapMarker(LapMarker{Name: segments[i+1].name, Distance: distance})
+		}
+	}
+	// records closest to each of the waypoints, which are used for the stops
+	var waypointRecords []int
+	for _, waypoint := range route.waypoints {
+		closest := a.closestRecord(waypoint.lat, waypoint.lon)
+		if closest < 0 {
+			continue
+		}
+		waypointRecords = append(waypointRecords, closest)
+		if route.waypointLaps {
+			a.addLapMarker(LapMarker{
+				Name:     waypoint.name,
+				Distance: a.records[closest].Distance,
+				Trigger:  PositionLapTrigger,
+			})
+		}
+	}
+
+	return a.finish(waypointRecords)
+}
+
+// closestRecord returns the index of the record closest to the point,
+// or -1 if the point lies farther than maxWaypointDistance from the activity
+func (a *Activity) closestRecord(lat, lon float64) int {
+	target := &Record{Lat: lat, Lon: lon}
+	closest, closestDistance := -1, math.Inf(1)
+	for i := range a.records {
+		if distance := a.records[i].DistanceTo(target); distance < closestDistance {
+			closest, closestDistance = i, distance
+		}
+	}
+
+	if closest >= 0 && closestDistance <= maxWaypointDistance {
+		return closest
+	}
+	return -1
+}
+
+// pathDistance returns the distance in km traversed when moving through all of the points
+func pathDistance(points []Record) float64 {
+	distance := 0.0
+	for i := 1; i < len(points); i++ {
+		distance += points[i-1].DistanceTo(&points[i])
+	}
+	return distance
+}
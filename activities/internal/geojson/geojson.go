@@ -0,0 +1,206 @@
+// Copyright 2022 Artem Mikheev
+
+// Package geojson implements unmarshaling of the line geometries of GeoJSON (RFC 7946) files.
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// GeoJSON object types
+const (
+	TypeFeatureCollection  = "FeatureCollection"
+	TypeFeature            = "Feature"
+	TypeGeometryCollection = "GeometryCollection"
+	TypePoint              = "Point"
+	TypeLineString         = "LineString"
+	TypeMultiLineString    = "MultiLineString"
+)
+
+var (
+	ErrNoPoints        = errors.New("geojson contains no line strings")
+	ErrInvalidPosition = errors.New("geojson position must contain at least longitude and latitude")
+)
+
+// Object is any GeoJSON object, only the members of the object's type are set
+type Object struct {
+	Type string `json:"type"`
+	// members of FeatureCollection
+	Features []Object `json:"features,omitempty"`
+	// members of Feature
+	Geometry   *Object                `json:"geometry,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	// members of GeometryCollection
+	Geometries []Object `json:"geometries,omitempty"`
+	// members of the other geometries, the structure depends on the type
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+}
+
+// Point is a single GeoJSON position, Elevation is nil if it isn't specified
+type Point struct {
+	Lat       float64
+	Lon       float64
+	Elevation *float64
+}
+
+// Segment is a single line string
+type Segment struct {
+	Name   string
+	Points []Point
+}
+
+// Waypoint is a Point geometry of a named feature
+type Waypoint struct {
+	Name string
+	Point
+}
+
+func UnmarshalGeoJSON(b []byte) (*Object, error) {
+	object := new(Object)
+	if err := json.Unmarshal(b, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// Segments returns all of the line strings of the object, including the lines of multi line strings,
+// in the order they appear. The segments are named after the name property of their feature.
+func (object *Object) Segments() ([]Segment, error) {
+	var segments []Segment
+	err := object.walk("", func(name string, geometry *Object) error {
+		var lines [][][]float64
+		switch geometry.Type {
+		case TypeLineString:
+			var line [][]float64
+			if err := json.Unmarshal(geometry.Coordinates, &line); err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		case TypeMultiLineString:
+			if err := json.Unmarshal(geometry.Coordinates, &lines); err != nil {
+				return err
+			}
+		}
+
+		for _, line := range lines {
+			segment := Segment{Name: name, Points: make([]Point, 0, len(line))}
+			for _, position := range line {
+				point, err := positionToPoint(position)
+				if err != nil {
+					return err
+				}
+				segment.Points = append(segment.Points, point)
+			}
+			if len(segment.Points) > 0 {
+				segments = append(segments, segment)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return nil, ErrNoPoints
+	}
+	return segments, nil
+}
+
+// Waypoints returns the points of all named features with a Point geometry
+func (object *Object) Waypoints() ([]Waypoint, error) {
+	var waypoints []Waypoint
+	err := object.walk("", func(name string, geometry *Object) error {
+		if geometry.Type != TypePoint || name == "" {
+			return nil
+		}
+		var position []float64
+		if err := json.Unmarshal(geometry.Coordinates, &position); err != nil {
+			return err
+		}
+		point, err := positionToPoint(position)
+		if err != nil {
+			return err
+		}
+		waypoints = append(waypoints, Waypoint{Name: name, Point: point})
+		return nil
+	})
+	return waypoints, err
+}
+
+// Name returns the name property of the object or the first named feature with a line geometry
+func (object *Object) Name() string {
+	if name := object.property("name"); name != "" {
+		return name
+	}
+	var lineName string
+	_ = object.walk("", func(name string, geometry *Object) error {
+		if lineName == "" && (geometry.Type == TypeLineString || geometry.Type == TypeMultiLineString) {
+			lineName = name
+		}
+		return nil
+	})
+	return lineName
+}
+
+// Description returns the description property of the object or the first described feature
+func (object *Object) Description() string {
+	if description := object.property("description"); description != "" {
+		return description
+	}
+	for i := range object.Features {
+		if description := object.Features[i].Description(); description != "" {
+			return description
+		}
+	}
+	return ""
+}
+
+func (object *Object) property(key string) string {
+	if value, ok := object.Properties[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// walk calls f for each of the geometries contained in the object along
+// with the name of the feature which the geometry belongs to
+func (object *Object) walk(name string, f func(name string, geometry *Object) error) error {
+	switch object.Type {
+	case TypeFeatureCollection:
+		for i := range object.Features {
+			if err := object.Features[i].walk(name, f); err != nil {
+				return err
+			}
+		}
+	case TypeFeature:
+		if object.Geometry != nil {
+			return object.Geometry.walk(object.property("name"), f)
+		}
+	case TypeGeometryCollection:
+		for i := range object.Geometries {
+			if err := object.Geometries[i].walk(name, f); err != nil {
+				return err
+			}
+		}
+	case "":
+		return errors.New("geojson object type is missing")
+	default:
+		return f(name, object)
+	}
+	return nil
+}
+
+func positionToPoint(position []float64) (Point, error) {
+	if len(position) < 2 {
+		return Point{}, fmt.Errorf("%w, but got %v", ErrInvalidPosition, position)
+	}
+	point := Point{Lon: position[0], Lat: position[1]}
+	if len(position) > 2 {
+		elevation := position[2]
+		point.Elevation = &elevation
+	}
+	return point, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Artem Mikheev
+
+package geojson
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGeoJSON = `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "properties": {"name": "Bridge"},
+      "geometry": {"type": "Point", "coordinates": [30.31, 59.93]}
+    },
+    {
+      "type": "Feature",
+      "properties": {"name": "Morning loop", "description": "Along the river"},
+      "geometry": {"type": "LineString", "coordinates": [[30.30, 59.92, 10.5], [30.31, 59.93]]}
+    },
+    {
+      "type": "Feature",
+      "properties": null,
+      "geometry": {
+        "type": "GeometryCollection",
+        "geometries": [
+          {"type": "MultiLineString", "coordinates": [[[30.32, 59.94]], [], [[30.33, 59.95], [30.34, 59.96]]]},
+          {"type": "Point", "coordinates": [30.35, 59.97]}
+        ]
+      }
+    }
+  ]
+}`
+
+func TestUnmarshalGeoJSON(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	object, err := UnmarshalGeoJSON([]byte(testGeoJSON))
+	r.NoError(err)
+	a.Equal("Morning loop", object.Name())
+	a.Equal("Along the river", object.Description())
+
+	// empty lines are skipped
+	segments, err := object.Segments()
+	r.NoError(err)
+	r.Len(segments, 3)
+	a.Equal("Morning loop", segments[0].Name)
+	r.Len(segments[0].Points, 2)
+	a.Equal(59.92, segments[0].Points[0].Lat)
+	a.Equal(30.30, segments[0].Points[0].Lon)
+	r.NotNil(segments[0].Points[0].Elevation)
+	a.Equal(10.5, *segments[0].Points[0].Elevation)
+	a.Nil(segments[0].Points[1].Elevation)
+	a.Equal("", segments[1].Name)
+	a.Len(segments[1].Points, 1)
+	a.Len(segments[2].Points, 2)
+
+	// only named points are waypoints
+	waypoints, err := object.Waypoints()
+	r.NoError(err)
+	r.Len(waypoints, 1)
+	a.Equal("Bridge", waypoints[0].Name)
+	a.Equal(59.93, waypoints[0].Lat)
+
+	// a bare geometry is a valid GeoJSON object too
+	object, err = UnmarshalGeoJSON([]byte(`{"type": "LineString", "coordinates": [[30.3, 59.9], [30.4, 60]]}`))
+	r.NoError(err)
+	segments, err = object.Segments()
+	r.NoError(err)
+	r.Len(segments, 1)
+	a.Len(segments[0].Points, 2)
+
+	// Error tests
+	_, err = UnmarshalGeoJSON([]byte(`{"type":`))
+	a.Error(err)
+	object, err = UnmarshalGeoJSON([]byte(`{"type": "Point", "coordinates": [30.3, 59.9]}`))
+	r.NoError(err)
+	_, err = object.Segments()
+	a.ErrorIs(err, ErrNoPoints)
+	object, err = UnmarshalGeoJSON([]byte(`{"type": "LineString", "coordinates": [[30.3]]}`))
+	r.NoError(err)
+	_, err = object.Segments()
+	a.ErrorIs(err, ErrInvalidPosition)
+	object, err = UnmarshalGeoJSON([]byte(`{"type": "LineString", "coordinates": "30.3,59.9"}`))
+	r.NoError(err)
+	_, err = object.Segments()
+	a.Error(err)
+	object, err = UnmarshalGeoJSON([]byte(`{"features": []}`))
+	r.NoError(err)
+	_, err = object.Segments()
+	a.Error(err)
+}
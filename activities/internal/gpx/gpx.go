@@ -1,32 +1,91 @@
+// Copyright 2022 Artem Mikheev
+
+// Package gpx implements marshaling and unmarshaling of GPX 1.1 files.
 package gpx
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+const (
+	Namespace = "http://www.topografix.com/GPX/1/1"
+	// TrackPointExtensionNamespace is the namespace of the Garmin track point extension
+	TrackPointExtensionNamespace = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+)
+
+const xmlIndentation = "  "
 
-// GPX file representation of a track with only needed parts
-// Latitude, Longitude and Elevation must be contained within the GPX file
+var ErrNoPoints = errors.New("gpx file contains no track or route points")
+
+// GPX file representation with the parts of GPX 1.1 needed to build activities
 type GPX struct {
-	XMLName xml.Name `xml:"gpx"`
-	Track   Track    `xml:"trk"`
+	XMLName   xml.Name  `xml:"gpx"`
+	Xmlns     string    `xml:"xmlns,attr,omitempty"`
+	Version   string    `xml:"version,attr"`
+	Creator   string    `xml:"creator,attr"`
+	Metadata  *Metadata `xml:"metadata,omitempty"`
+	Waypoints []Point   `xml:"wpt,omitempty"`
+	Routes    []Route   `xml:"rte,omitempty"`
+	Tracks    []Track   `xml:"trk,omitempty"`
+}
+
+// GPX metadata
+type Metadata struct {
+	Name        string     `xml:"name,omitempty"`
+	Description string     `xml:"desc,omitempty"`
+	Time        *time.Time `xml:"time,omitempty"`
+}
+
+// GPX wptType, which is used for waypoints, route points and track points.
+// Elevation and Time are nil if the optional elements are absent.
+type Point struct {
+	Lat         float64     `xml:"lat,attr"`
+	Lon         float64     `xml:"lon,attr"`
+	Elevation   *float64    `xml:"ele,omitempty"`
+	Time        *time.Time  `xml:"time,omitempty"`
+	Name        string      `xml:"name,omitempty"`
+	Description string      `xml:"desc,omitempty"`
+	Extensions  *Extensions `xml:"extensions,omitempty"`
+}
+
+// GPX extensionsType, only containing the supported extensions
+type Extensions struct {
+	TrackPointExtension *TrackPointExtension `xml:"TrackPointExtension,omitempty"`
+}
+
+// TrackPointExtension of the Garmin track point extension schema
+type TrackPointExtension struct {
+	Xmlns string `xml:"xmlns,attr,omitempty"`
+	// heart rate in bpm
+	HeartRate *uint8 `xml:"hr,omitempty"`
+	// cadence in rpm
+	Cadence *uint8 `xml:"cad,omitempty"`
+}
+type Route struct {
+	Name        string  `xml:"name,omitempty"`
+	Description string  `xml:"desc,omitempty"`
+	Points      []Point `xml:"rtept"`
 }
 
 // GPX trk
 type Track struct {
-	XMLName      xml.Name     `xml:"trk"`
-	TrackSegment TrackSegment `xml:"trkseg"`
+	Name        string         `xml:"name,omitempty"`
+	Description string         `xml:"desc,omitempty"`
+	Type        string         `xml:"type,omitempty"`
+	Segments    []TrackSegment `xml:"trkseg"`
 }
 
 // GPX trkseg
 type TrackSegment struct {
-	XMLName    xml.Name    `xml:"trkseg"`
-	TrackParts []TrackPart `xml:"trkpt"`
+	Points []Point `xml:"trkpt"`
 }
 
-// GPX trkpt
-type TrackPart struct {
-	XMLName   xml.Name `xml:"trkpt"`
-	Lat       string   `xml:"lat,attr"`
-	Lon       string   `xml:"lon,attr"`
-	Elevation float64  `xml:"ele"`
+// Segment is a continuous sequence of points from either a track segment or a route
+type Segment struct {
+	Name   string
+	Points []Point
 }
 
 func UnmarshalGPX(b []byte) (*GPX, error) {
@@ -36,3 +95,106 @@ func UnmarshalGPX(b []byte) (*GPX, error) {
 	}
 	return gpx, nil
 }
+
+// MarshalGPX marshals the file along with the XML header, setting the GPX 1.1 namespace and version
+// as well as the namespace of the track point extensions
+func MarshalGPX(gpx *GPX) ([]byte, error) {
+	gpx.Xmlns = Namespace
+	gpx.Version = "1.1"
+	for i := range gpx.Tracks {
+		for j := range gpx.Tracks[i].Segments {
+			points := gpx.Tracks[i].Segments[j].Points
+			for k := range points {
+				if ext := points[k].Extensions; ext != nil && ext.TrackPointExtension != nil {
+					ext.TrackPointExtension.Xmlns = TrackPointExtensionNamespace
+				}
+			}
+		}
+	}
+	b, err := xml.MarshalIndent(gpx, "", xmlIndentation)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// Segments returns all of the non-empty track segments in the order they appear
+// in the file. Routes are only used if the file doesn't contain any track points,
+// since files exported with both usually contain the same path twice.
+func (gpx *GPX) Segments() ([]Segment, error) {
+	var segments []Segment
+	for _, track := range gpx.Tracks {
+		for _, trackSegment := range track.Segments {
+			if len(trackSegment.Points) > 0 {
+				segments = append(segments, Segment{Name: track.Name, Points: trackSegment.Points})
+			}
+		}
+	}
+
+	if len(segments) == 0 {
+		for _, route := range gpx.Routes {
+			if len(route.Points) > 0 {
+				segments = append(segments, Segment{Name: route.Name, Points: route.Points})
+			}
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, ErrNoPoints
+	}
+	return segments, nil
+}
+
+// Name returns the name of the file from its metadata or the first named track or route
+func (gpx *GPX) Name() string {
+	if gpx.Metadata != nil && gpx.Metadata.Name != "" {
+		return gpx.Metadata.Name
+	}
+	for _, track := range gpx.Tracks {
+		if track.Name != "" {
+			return track.Name
+		}
+	}
+	for _, route := range gpx.Routes {
+		if route.Name != "" {
+			return route.Name
+		}
+	}
+	return ""
+}
+
+// Description returns the description of the file from its metadata or the first described track or route
+func (gpx *GPX) Description() string {
+	if gpx.Metadata != nil && gpx.Metadata.Description != "" {
+		return gpx.Metadata.Description
+	}
+	for _, track := range gpx.Tracks {
+		if track.Description != "" {
+			return track.Description
+		}
+	}
+	for _, route := range gpx.Routes {
+		if route.Description != "" {
+			return route.Description
+		}
+	}
+	return ""
+}
+
+// HeartRate returns the heart rate of the point from its extensions, or 0 if unknown
+func (point *Point) HeartRate() uint8 {
+	if point.Extensions != nil && point.Extensions.TrackPointExtension != nil &&
+		point.Extensions.TrackPointExtension.HeartRate != nil {
+		return *point.Extensions.TrackPointExtension.HeartRate
+	}
+	return 0
+}
+
+// Cadence returns the cadence of the point from its extensions, or 0 if unknown
+func (point *Point) Cadence() uint8 {
+	if point.Extensions != nil && point.Extensions.TrackPointExtension != nil &&
+		point.Extensions.TrackPointExtension.Cadence != nil {
+		return *point.Extensions.TrackPointExtension.Cadence
+	}
+	return 0
+}
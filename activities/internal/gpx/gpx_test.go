@@ -0,0 +1,123 @@
+// Copyright 2022 Artem Mikheev
+
+package gpx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="gpx.studio"
+  xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <metadata>
+    <name>Morning loop</name>
+    <time>2022-04-01T06:30:00Z</time>
+  </metadata>
+  <wpt lat="59.93" lon="30.31"><name>Bridge</name></wpt>
+  <rte>
+    <name>Planned</name>
+    <rtept lat="59.92" lon="30.30"></rtept>
+  </rte>
+  <trk>
+    <name>First</name>
+    <trkseg>
+      <trkpt lat="59.92" lon="30.30"><ele>10.5</ele><time>2022-04-01T06:30:00Z</time></trkpt>
+      <trkpt lat="59.93" lon="30.31"><extensions><gpxtpx:TrackPointExtension>
+        <gpxtpx:hr>142</gpxtpx:hr><gpxtpx:cad>80</gpxtpx:cad>
+      </gpxtpx:TrackPointExtension></extensions></trkpt>
+    </trkseg>
+    <trkseg></trkseg>
+    <trkseg>
+      <trkpt lat="59.94" lon="30.32"><ele>0</ele></trkpt>
+    </trkseg>
+  </trk>
+  <trk>
+    <name>Second</name>
+    <desc>Way back</desc>
+    <trkseg>
+      <trkpt lat="59.92" lon="30.30"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestUnmarshalGPX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	gpx, err := UnmarshalGPX([]byte(testGPX))
+	r.NoError(err)
+	a.Equal("1.1", gpx.Version)
+	a.Equal("Morning loop", gpx.Name())
+	a.Equal("Way back", gpx.Description())
+	r.NotNil(gpx.Metadata.Time)
+	a.Equal(time.Date(2022, time.April, 1, 6, 30, 0, 0, time.UTC), *gpx.Metadata.Time)
+
+	r.Len(gpx.Waypoints, 1)
+	a.Equal("Bridge", gpx.Waypoints[0].Name)
+
+	// empty segments are skipped and routes are ignored when tracks are present
+	segments, err := gpx.Segments()
+	r.NoError(err)
+	r.Len(segments, 3)
+	a.Equal([]string{"First", "First", "Second"}, []string{segments[0].Name, segments[1].Name, segments[2].Name})
+
+	first := segments[0].Points
+	r.Len(first, 2)
+	a.Equal(59.92, first[0].Lat)
+	a.Equal(30.30, first[0].Lon)
+	r.NotNil(first[0].Elevation)
+	a.Equal(10.5, *first[0].Elevation)
+	r.NotNil(first[0].Time)
+	a.Nil(first[1].Elevation, "absent elevation must stay absent")
+	a.Nil(first[1].Time)
+	a.Equal(uint8(0), first[0].HeartRate())
+	a.Equal(uint8(142), first[1].HeartRate())
+	a.Equal(uint8(80), first[1].Cadence())
+	r.NotNil(segments[1].Points[0].Elevation)
+	a.Equal(0.0, *segments[1].Points[0].Elevation)
+}
+
+func TestRouteSegments(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	gpx, err := UnmarshalGPX([]byte(`<gpx version="1.1"><rte><name>Route</name>
+		<rtept lat="1" lon="2"><ele>3</ele></rtept><rtept lat="4" lon="5"></rtept></rte></gpx>`))
+	r.NoError(err)
+	segments, err := gpx.Segments()
+	r.NoError(err)
+	r.Len(segments, 1)
+	a.Equal("Route", segments[0].Name)
+	a.Len(segments[0].Points, 2)
+	a.Equal("Route", gpx.Name())
+
+	// Error tests
+	gpx, err = UnmarshalGPX([]byte(`<gpx version="1.1"><trk><trkseg></trkseg></trk></gpx>`))
+	r.NoError(err)
+	_, err = gpx.Segments()
+	a.ErrorIs(err, ErrNoPoints)
+
+	_, err = UnmarshalGPX([]byte(`<gpx><trk><trkseg><trkpt lat="north" lon="2"></trkpt></trkseg></trk></gpx>`))
+	a.Error(err)
+}
+
+func TestMarshalGPX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	gpx, err := UnmarshalGPX([]byte(testGPX))
+	r.NoError(err)
+	b, err := MarshalGPX(gpx)
+	r.NoError(err)
+	a.Contains(string(b), `<gpx xmlns="`+Namespace+`" version="1.1" creator="gpx.studio">`)
+	a.NotContains(string(b), `<desc></desc>`)
+
+	// the marshaled file must contain the same data
+	unmarshaled, err := UnmarshalGPX(b)
+	r.NoError(err)
+	a.Equal(gpx, unmarshaled)
+}
@@ -0,0 +1,276 @@
+// Copyright 2022 Artem Mikheev
+
+// Package kml implements unmarshaling of the line
+// geometries of KML 2.2 files, including gx:Track.
+package kml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoPoints          = errors.New("kml file contains no line strings or tracks")
+	ErrInvalidCoordinate = errors.New("kml coordinate must contain at least longitude and latitude")
+	ErrTrackMismatch     = errors.New("kml track must contain a timestamp for each coordinate")
+)
+
+// KML file representation, the root may contain any of the containers or a single placemark
+type KML struct {
+	XMLName xml.Name `xml:"kml"`
+	Container
+}
+
+// Container is a KML Document or Folder
+type Container struct {
+	Name        string      `xml:"name"`
+	Description string      `xml:"description"`
+	Documents   []Container `xml:"Document"`
+	Folders     []Container `xml:"Folder"`
+	Placemarks  []Placemark `xml:"Placemark"`
+}
+
+// KML Placemark with the supported geometries
+type Placemark struct {
+	Name          string         `xml:"name"`
+	Description   string         `xml:"description"`
+	Point         *Coordinates   `xml:"Point"`
+	LineString    *Coordinates   `xml:"LineString"`
+	Track         *Track         `xml:"Track"`
+	MultiTrack    *MultiGeometry `xml:"MultiTrack"`
+	MultiGeometry *MultiGeometry `xml:"MultiGeometry"`
+}
+
+// Coordinates of a Point or a LineString, specified as whitespace-separated lon,lat[,alt] tuples
+type Coordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// KML gx:Track, with the coordinates specified as space-separated lon lat alt triples
+type Track struct {
+	When   []string `xml:"when"`
+	Coords []string `xml:"coord"`
+}
+
+// KML MultiGeometry or gx:MultiTrack
+type MultiGeometry struct {
+	LineStrings []Coordinates `xml:"LineString"`
+	Tracks      []Track       `xml:"Track"`
+}
+
+// Point is a single KML coordinate, Altitude and Time are nil if unspecified
+type Point struct {
+	Lat      float64
+	Lon      float64
+	Altitude *float64
+	Time     *time.Time
+}
+
+// Segment is a single line string or track
+type Segment struct {
+	Name   string
+	Points []Point
+}
+
+// Waypoint is a named placemark with a Point geometry
+type Waypoint struct {
+	Name string
+	Point
+}
+
+func UnmarshalKML(b []byte) (*KML, error) {
+	kml := new(KML)
+	if err := xml.Unmarshal(b, kml); err != nil {
+		return nil, err
+	}
+	return kml, nil
+}
+
+// Segments returns all of the line strings and tracks of the placemarks in the order they appear
+func (kml *KML) Segments() ([]Segment, error) {
+	var segments []Segment
+	var err error
+	kml.walk(func(placemark *Placemark) {
+		if err != nil {
+			return
+		}
+		var lineStrings []Coordinates
+		var tracks []Track
+		if placemark.LineString != nil {
+			lineStrings = append(lineStrings, *placemark.LineString)
+		}
+		if placemark.Track != nil {
+			tracks = append(tracks, *placemark.Track)
+		}
+		for _, multi := range []*MultiGeometry{placemark.MultiGeometry, placemark.MultiTrack} {
+			if multi != nil {
+				lineStrings = append(lineStrings, multi.LineStrings...)
+				tracks = append(tracks, multi.Tracks...)
+			}
+		}
+
+		for _, lineString := range lineStrings {
+			var points []Point
+			if points, err = parseCoordinates(lineString.Coordinates); err != nil {
+				return
+			}
+			segments = appendSegment(segments, placemark.Name, points)
+		}
+		for _, track := range tracks {
+			var points []Point
+			if points, err = parseTrack(&track); err != nil {
+				return
+			}
+			segments = appendSegment(segments, placemark.Name, points)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return nil, ErrNoPoints
+	}
+	return segments, nil
+}
+
+// Waypoints returns the named placemarks with a Point geometry
+func (kml *KML) Waypoints() ([]Waypoint, error) {
+	var waypoints []Waypoint
+	var err error
+	kml.walk(func(placemark *Placemark) {
+		if err != nil || placemark.Point == nil || placemark.Name == "" {
+			return
+		}
+		var points []Point
+		if points, err = parseCoordinates(placemark.Point.Coordinates); err != nil {
+			return
+		}
+		if len(points) > 0 {
+			waypoints = append(waypoints, Waypoint{Name: placemark.Name, Point: points[0]})
+		}
+	})
+	return waypoints, err
+}
+
+// Name returns the name of the first named container or placemark
+func (kml *KML) Name() string {
+	name := kml.Container.Name
+	kml.walkContainers(func(container *Container) {
+		if name == "" {
+			name = container.Name
+		}
+	})
+	kml.walk(func(placemark *Placemark) {
+		if name == "" {
+			name = placemark.Name
+		}
+	})
+	return name
+}
+
+// Description returns the description of the first described container or placemark
+func (kml *KML) Description() string {
+	description := kml.Container.Description
+	kml.walkContainers(func(container *Container) {
+		if description == "" {
+			description = container.Description
+		}
+	})
+	kml.walk(func(placemark *Placemark) {
+		if description == "" {
+			description = placemark.Description
+		}
+	})
+	return description
+}
+
+func (kml *KML) walkContainers(f func(container *Container)) {
+	var walk func(container *Container)
+	walk = func(container *Container) {
+		f(container)
+		for i := range container.Documents {
+			walk(&container.Documents[i])
+		}
+		for i := range container.Folders {
+			walk(&container.Folders[i])
+		}
+	}
+	walk(&kml.Container)
+}
+
+// walk calls f for all placemarks in document order of the containers
+func (kml *KML) walk(f func(placemark *Placemark)) {
+	kml.walkContainers(func(container *Container) {
+		for i := range container.Placemarks {
+			f(&container.Placemarks[i])
+		}
+	})
+}
+
+func appendSegment(segments []Segment, name string, points []Point) []Segment {
+	if len(points) == 0 {
+		return segments
+	}
+	return append(segments, Segment{Name: name, Points: points})
+}
+
+// parseCoordinates parses whitespace-separated lon,lat[,alt] tuples
+func parseCoordinates(coordinates string) ([]Point, error) {
+	var points []Point
+	for _, tuple := range strings.Fields(coordinates) {
+		point, err := parsePoint(strings.Split(tuple, ","))
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+func parseTrack(track *Track) ([]Point, error) {
+	if len(track.When) != 0 && len(track.When) != len(track.Coords) {
+		return nil, ErrTrackMismatch
+	}
+
+	points := make([]Point, len(track.Coords))
+	for i, coord := range track.Coords {
+		point, err := parsePoint(strings.Fields(coord))
+		if err != nil {
+			return nil, err
+		}
+		if len(track.When) != 0 {
+			timestamp, err := time.Parse(time.RFC3339, strings.TrimSpace(track.When[i]))
+			if err != nil {
+				return nil, err
+			}
+			point.Time = &timestamp
+		}
+		points[i] = point
+	}
+	return points, nil
+}
+
+func parsePoint(values []string) (Point, error) {
+	if len(values) < 2 {
+		return Point{}, fmt.Errorf("%w, but got %v", ErrInvalidCoordinate, values)
+	}
+
+	parsed := make([]float64, len(values))
+	for i := range values {
+		var err error
+		if parsed[i], err = strconv.ParseFloat(values[i], 64); err != nil {
+			return Point{}, err
+		}
+	}
+
+	point := Point{Lon: parsed[0], Lat: parsed[1]}
+	if len(parsed) > 2 {
+		point.Altitude = &parsed[2]
+	}
+	return point, nil
+}
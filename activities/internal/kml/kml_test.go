@@ -0,0 +1,100 @@
+// Copyright 2022 Artem Mikheev
+
+package kml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+  <Document>
+    <name>Morning loop</name>
+    <Placemark>
+      <name>Bridge</name>
+      <Point><coordinates>30.31,59.93,0</coordinates></Point>
+    </Placemark>
+    <Folder>
+      <description>Along the river</description>
+      <Placemark>
+        <name>Out</name>
+        <LineString>
+          <coordinates>
+            30.30,59.92,10.5
+            30.31,59.93
+          </coordinates>
+        </LineString>
+      </Placemark>
+      <Placemark>
+        <name>Back</name>
+        <gx:Track>
+          <when>2022-04-01T06:30:00Z</when>
+          <when>2022-04-01T06:31:00Z</when>
+          <gx:coord>30.31 59.93 12</gx:coord>
+          <gx:coord>30.30 59.92 11</gx:coord>
+        </gx:Track>
+      </Placemark>
+      <Placemark>
+        <MultiGeometry>
+          <LineString><coordinates>30.32,59.94 30.33,59.95</coordinates></LineString>
+          <LineString><coordinates></coordinates></LineString>
+        </MultiGeometry>
+      </Placemark>
+    </Folder>
+  </Document>
+</kml>`
+
+func TestUnmarshalKML(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	kml, err := UnmarshalKML([]byte(testKML))
+	r.NoError(err)
+	a.Equal("Morning loop", kml.Name())
+	a.Equal("Along the river", kml.Description())
+
+	segments, err := kml.Segments()
+	r.NoError(err)
+	r.Len(segments, 3)
+	a.Equal("Out", segments[0].Name)
+	r.Len(segments[0].Points, 2)
+	a.Equal(59.92, segments[0].Points[0].Lat)
+	a.Equal(30.30, segments[0].Points[0].Lon)
+	r.NotNil(segments[0].Points[0].Altitude)
+	a.Equal(10.5, *segments[0].Points[0].Altitude)
+	a.Nil(segments[0].Points[1].Altitude)
+	a.Nil(segments[0].Points[1].Time)
+
+	a.Equal("Back", segments[1].Name)
+	r.Len(segments[1].Points, 2)
+	r.NotNil(segments[1].Points[1].Time)
+	a.Equal(time.Date(2022, time.April, 1, 6, 31, 0, 0, time.UTC), *segments[1].Points[1].Time)
+	a.Equal(11.0, *segments[1].Points[1].Altitude)
+	a.Len(segments[2].Points, 2)
+
+	waypoints, err := kml.Waypoints()
+	r.NoError(err)
+	r.Len(waypoints, 1)
+	a.Equal("Bridge", waypoints[0].Name)
+	a.Equal(30.31, waypoints[0].Lon)
+
+	// Error tests
+	_, err = UnmarshalKML([]byte(`<kml>`))
+	a.Error(err)
+	for _, contents := range []string{
+		`<kml><Placemark></Placemark></kml>`,
+		`<kml><Placemark><LineString><coordinates>30.3</coordinates></LineString></Placemark></kml>`,
+		`<kml><Placemark><LineString><coordinates>30.3,north</coordinates></LineString></Placemark></kml>`,
+		`<kml><Placemark><Track><when>2022-04-01T06:30:00Z</when></Track></Placemark></kml>`,
+		`<kml><Placemark><Track><when>yesterday</when><coord>30.3 59.9</coord></Track></Placemark></kml>`,
+	} {
+		kml, err = UnmarshalKML([]byte(contents))
+		r.NoError(err)
+		_, err = kml.Segments()
+		a.Error(err, contents)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 Artem Mikheev
+
+// Package polyline implements decoding of the Google encoded polyline algorithm format.
+package polyline
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var (
+	ErrInvalidCharacter = errors.New("polyline contains a character outside of the encoding range")
+	ErrTruncated        = errors.New("polyline ends in the middle of a value")
+	ErrNoPoints         = errors.New("polyline contains no points")
+	ErrOverflow         = errors.New("polyline value is too large")
+)
+
+// characters used by the encoding are in the range [minChar, maxChar]
+const (
+	minChar      = 63
+	maxChar      = 126
+	chunkBits    = 5
+	chunkMask    = 0x1f
+	continuation = 0x20
+)
+
+// Point is a single decoded point
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// IsPolyline reports whether s contains only the characters used by the encoding
+func IsPolyline(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < minChar || s[i] > maxChar {
+			return false
+		}
+	}
+	return true
+}
+
+// Decode decodes the polyline with coordinates encoded with the
+// given precision, which is 5 for Google and 6 for OSRM and Valhalla.
+// Surrounding whitespace, which is usually left in files, is ignored.
+func Decode(s string, precision int) ([]Point, error) {
+	s = strings.TrimSpace(s)
+	factor := math.Pow10(precision)
+
+	var points []Point
+	var lat, lon int64
+	for i := 0; i < len(s); {
+		var deltaLat, deltaLon int64
+		var err error
+		if deltaLat, i, err = decodeValue(s, i); err != nil {
+			return nil, err
+		}
+		if deltaLon, i, err = decodeValue(s, i); err != nil {
+			return nil, err
+		}
+		lat += deltaLat
+		lon += deltaLon
+		points = append(points, Point{Lat: float64(lat) / factor, Lon: float64(lon) / factor})
+	}
+
+	if len(points) == 0 {
+		return nil, ErrNoPoints
+	}
+	return points, nil
+}
+
+// decodeValue decodes a single signed value beginning at s[i],
+// returning it along with the index of the next value
+func decodeValue(s string, i int) (int64, int, error) {
+	var result int64
+	shift := 0
+	for {
+		if i >= len(s) {
+			return 0, i, ErrTruncated
+		}
+		if shift > 60 {
+			return 0, i, ErrOverflow
+		}
+		c := s[i]
+		if c < minChar || c > maxChar {
+			return 0, i, ErrInvalidCharacter
+		}
+		chunk := int64(c - minChar)
+		i++
+		result |= (chunk & chunkMask) << shift
+		shift += chunkBits
+		if chunk&continuation == 0 {
+			break
+		}
+	}
+
+	if result&1 != 0 {
+		return ^(result >> 1), i, nil
+	}
+	return result >> 1, i, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2022 Artem Mikheev
+
+package polyline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// example from the documentation of the algorithm
+const testPolyline = "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+
+func TestDecode(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	points, err := Decode(" "+testPolyline+"\n", 5)
+	r.NoError(err)
+	expected := []Point{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}
+	r.Len(points, len(expected))
+	for i := range expected {
+		a.InDelta(expected[i].Lat, points[i].Lat, 1e-9)
+		a.InDelta(expected[i].Lon, points[i].Lon, 1e-9)
+	}
+
+	// the same polyline decoded with a higher precision
+	points, err = Decode(testPolyline, 6)
+	r.NoError(err)
+	a.InDelta(3.85, points[0].Lat, 1e-9)
+
+	a.True(IsPolyline(testPolyline + "\n"))
+	a.False(IsPolyline("<gpx>"))
+	a.False(IsPolyline(" "))
+
+	// Error tests
+	_, err = Decode("", 5)
+	a.ErrorIs(err, ErrNoPoints)
+	_, err = Decode("_p~iF", 5)
+	a.ErrorIs(err, ErrTruncated)
+	_, err = Decode("_p~iF~ps|", 5)
+	a.ErrorIs(err, ErrTruncated)
+	_, err = Decode("_p~iF ~ps|U", 5)
+	a.ErrorIs(err, ErrInvalidCharacter)
+	_, err = Decode("~~~~~~~~~~~~~~~", 5)
+	a.ErrorIs(err, ErrOverflow)
+}
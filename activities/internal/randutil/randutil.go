@@ -7,14 +7,34 @@ import (
 	"time"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// Rand is a source of random values, which always
+// generates the same values when created with the same seed
+type Rand struct {
+	rnd *rand.Rand
 }
 
-func Float64InRange(a, b float64) float64 {
-	return rand.Float64()*(b-a) + a
+// NewSeed returns a seed based on the current time
+func NewSeed() int64 {
+	return time.Now().UnixNano()
 }
 
-func IntInRange(a, b int) int {
-	return a + rand.Intn(b-a+1)
+func New(seed int64) *Rand {
+	return &Rand{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (r *Rand) Float64() float64 {
+	return r.rnd.Float64()
+}
+
+func (r *Rand) Float64InRange(a, b float64) float64 {
+	return r.rnd.Float64()*(b-a) + a
+}
+
+func (r *Rand) IntInRange(a, b int) int {
+	return a + r.rnd.Intn(b-a+1)
+}
+
+// NormFloat64 returns a normally distributed value with mean 0 and standard deviation 1
+func (r *Rand) NormFloat64() float64 {
+	return r.rnd.NormFloat64()
 }
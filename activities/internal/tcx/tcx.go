@@ -0,0 +1,260 @@
+// Copyright 2022 Artem Mikheev
+
+// Package tcx implements marshaling and unmarshaling of Garmin TCX v2 files.
+package tcx
+
+import (
+	"encoding/xml"
+	"errors"
+	"time"
+)
+
+const (
+	Namespace          = "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+	ExtensionNamespace = "http://www.garmin.com/xmlschemas/ActivityExtension/v2"
+)
+
+// Sports supported by TCX activities
+const (
+	SportRunning = "Running"
+	SportBiking  = "Biking"
+	SportOther   = "Other"
+)
+
+// Lap intensities and trigger methods
+const (
+	IntensityActive  = "Active"
+	IntensityResting = "Resting"
+	TriggerManual    = "Manual"
+	TriggerDistance  = "Distance"
+	TriggerLocation  = "Location"
+	TriggerTime      = "Time"
+	TriggerHeartRate = "HeartRate"
+)
+
+const xmlIndentation = "  "
+
+var ErrNoPoints = errors.New("tcx file contains no trackpoints with a position")
+
+// TrainingCenterDatabase is the root element of TCX files,
+// only the activities and courses are represented
+type TrainingCenterDatabase struct {
+	XMLName    xml.Name   `xml:"TrainingCenterDatabase"`
+	Xmlns      string     `xml:"xmlns,attr,omitempty"`
+	Activities []Activity `xml:"Activities>Activity,omitempty"`
+	Courses    []Course   `xml:"Courses>Course,omitempty"`
+}
+
+// TCX Activity_t
+type Activity struct {
+	Sport string    `xml:"Sport,attr"`
+	Id    time.Time `xml:"Id"`
+	Laps  []Lap     `xml:"Lap"`
+	Notes string    `xml:"Notes,omitempty"`
+}
+
+// TCX ActivityLap_t
+type Lap struct {
+	StartTime        time.Time `xml:"StartTime,attr"`
+	TotalTimeSeconds float64   `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64   `xml:"DistanceMeters"`
+	// maximum speed in m/s
+	MaximumSpeed        *float64   `xml:"MaximumSpeed,omitempty"`
+	Calories            uint16     `xml:"Calories"`
+	AverageHeartRateBpm *HeartRate `xml:"AverageHeartRateBpm,omitempty"`
+	MaximumHeartRateBpm *HeartRate `xml:"MaximumHeartRateBpm,omitempty"`
+	Intensity           string     `xml:"Intensity"`
+	Cadence             *uint8     `xml:"Cadence,omitempty"`
+	TriggerMethod       string     `xml:"TriggerMethod"`
+	Tracks              []Track    `xml:"Track"`
+	Notes               string     `xml:"Notes,omitempty"`
+}
+
+// TCX Track_t
+type Track struct {
+	Trackpoints []Trackpoint `xml:"Trackpoint"`
+}
+
+// TCX Trackpoint_t, all of the elements are optional.
+// Cadence is the bike cadence, the running cadence is specified in the extensions.
+type Trackpoint struct {
+	Time           *time.Time           `xml:"Time,omitempty"`
+	Position       *Position            `xml:"Position,omitempty"`
+	AltitudeMeters *float64             `xml:"AltitudeMeters,omitempty"`
+	DistanceMeters *float64             `xml:"DistanceMeters,omitempty"`
+	HeartRateBpm   *HeartRate           `xml:"HeartRateBpm,omitempty"`
+	Cadence        *uint8               `xml:"Cadence,omitempty"`
+	Extensions     *TrackpointExtension `xml:"Extensions,omitempty"`
+}
+
+// TCX Position_t
+type Position struct {
+	LatitudeDegrees  float64 `xml:"LatitudeDegrees"`
+	LongitudeDegrees float64 `xml:"LongitudeDegrees"`
+}
+
+// TCX HeartRateInBeatsPerMinute_t
+type HeartRate struct {
+	Value uint8 `xml:"Value"`
+}
+
+// TrackpointExtension contains the trackpoint
+// extensions from the activity extension schema
+type TrackpointExtension struct {
+	TPX *TPX `xml:"TPX,omitempty"`
+}
+
+// TPX is the trackpoint extension of the activity extension schema
+type TPX struct {
+	Xmlns string `xml:"xmlns,attr,omitempty"`
+	// speed in m/s
+	Speed      *float64 `xml:"Speed,omitempty"`
+	RunCadence *uint8   `xml:"RunCadence,omitempty"`
+	// power in watts
+	Watts *uint16 `xml:"Watts,omitempty"`
+}
+
+// TCX Course_t
+type Course struct {
+	Name         string        `xml:"Name"`
+	Tracks       []Track       `xml:"Track"`
+	Notes        string        `xml:"Notes,omitempty"`
+	CoursePoints []CoursePoint `xml:"CoursePoint"`
+}
+
+// TCX CoursePoint_t
+type CoursePoint struct {
+	Name      string    `xml:"Name"`
+	Time      time.Time `xml:"Time"`
+	Position  Position  `xml:"Position"`
+	PointType string    `xml:"PointType"`
+	Notes     string    `xml:"Notes,omitempty"`
+}
+
+// Segment is a continuous sequence of trackpoints with a position from
+// a single lap of an activity or a course. Trackpoints without a position,
+// which are usually recorded during pauses, are skipped.
+type Segment struct {
+	Name        string
+	Trackpoints []Trackpoint
+}
+
+func UnmarshalTCX(b []byte) (*TrainingCenterDatabase, error) {
+	tcx := new(TrainingCenterDatabase)
+	if err := xml.Unmarshal(b, tcx); err != nil {
+		return nil, err
+	}
+	return tcx, nil
+}
+
+// MarshalTCX marshals the database along with the XML header,
+// setting the namespaces of the database and the extensions
+func MarshalTCX(tcx *TrainingCenterDatabase) ([]byte, error) {
+	tcx.Xmlns = Namespace
+	for i := range tcx.Activities {
+		for j := range tcx.Activities[i].Laps {
+			setExtensionNamespace(tcx.Activities[i].Laps[j].Tracks)
+		}
+	}
+	for i := range tcx.Courses {
+		setExtensionNamespace(tcx.Courses[i].Tracks)
+	}
+
+	b, err := xml.MarshalIndent(tcx, "", xmlIndentation)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+func setExtensionNamespace(tracks []Track) {
+	for i := range tracks {
+		for j := range tracks[i].Trackpoints {
+			if ext := tracks[i].Trackpoints[j].Extensions; ext != nil && ext.TPX != nil {
+				ext.TPX.Xmlns = ExtensionNamespace
+			}
+		}
+	}
+}
+
+// Segments returns a segment for each of the laps of all activities,
+// or for each course if the file doesn't contain any activity trackpoints
+func (tcx *TrainingCenterDatabase) Segments() ([]Segment, error) {
+	var segments []Segment
+	for _, activity := range tcx.Activities {
+		for _, lap := range activity.Laps {
+			segments = appendSegment(segments, lap.Notes, lap.Tracks)
+		}
+	}
+
+	if len(segments) == 0 {
+		for _, course := range tcx.Courses {
+			segments = appendSegment(segments, course.Name, course.Tracks)
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, ErrNoPoints
+	}
+	return segments, nil
+}
+
+func appendSegment(segments []Segment, name string, tracks []Track) []Segment {
+	segment := Segment{Name: name}
+	for _, track := range tracks {
+		for _, trackpoint := range track.Trackpoints {
+			if trackpoint.Position != nil {
+				segment.Trackpoints = append(segment.Trackpoints, trackpoint)
+			}
+		}
+	}
+	if len(segment.Trackpoints) == 0 {
+		return segments
+	}
+	return append(segments, segment)
+}
+
+// Name returns the name of the first named course
+func (tcx *TrainingCenterDatabase) Name() string {
+	for _, course := range tcx.Courses {
+		if course.Name != "" {
+			return course.Name
+		}
+	}
+	return ""
+}
+
+// Description returns the notes of the first activity or course with notes
+func (tcx *TrainingCenterDatabase) Description() string {
+	for _, activity := range tcx.Activities {
+		if activity.Notes != "" {
+			return activity.Notes
+		}
+	}
+	for _, course := range tcx.Courses {
+		if course.Notes != "" {
+			return course.Notes
+		}
+	}
+	return ""
+}
+
+// HeartRate returns the heart rate of the trackpoint, 0 if unknown
+func (tp *Trackpoint) HeartRate() uint8 {
+	if tp.HeartRateBpm == nil {
+		return 0
+	}
+	return tp.HeartRateBpm.Value
+}
+
+// CadenceValue returns the bike cadence of the trackpoint, or the running
+// cadence from the extensions if the bike cadence isn't specified, 0 if unknown
+func (tp *Trackpoint) CadenceValue() uint8 {
+	if tp.Cadence != nil {
+		return *tp.Cadence
+	}
+	if tp.Extensions != nil && tp.Extensions.TPX != nil && tp.Extensions.TPX.RunCadence != nil {
+		return *tp.Extensions.TPX.RunCadence
+	}
+	return 0
+}
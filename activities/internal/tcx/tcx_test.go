@@ -0,0 +1,123 @@
+// Copyright 2022 Artem Mikheev
+
+package tcx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+  xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2022-04-01T06:30:00Z</Id>
+      <Lap StartTime="2022-04-01T06:30:00Z">
+        <TotalTimeSeconds>10</TotalTimeSeconds>
+        <DistanceMeters>30</DistanceMeters>
+        <Calories>2</Calories>
+        <Intensity>Active</Intensity>
+        <TriggerMethod>Manual</TriggerMethod>
+        <Track>
+          <Trackpoint>
+            <Time>2022-04-01T06:30:00Z</Time>
+            <Position><LatitudeDegrees>59.92</LatitudeDegrees><LongitudeDegrees>30.30</LongitudeDegrees></Position>
+            <AltitudeMeters>10.5</AltitudeMeters>
+            <HeartRateBpm><Value>120</Value></HeartRateBpm>
+            <Extensions><ns3:TPX><ns3:RunCadence>82</ns3:RunCadence></ns3:TPX></Extensions>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2022-04-01T06:30:05Z</Time>
+            <HeartRateBpm><Value>121</Value></HeartRateBpm>
+          </Trackpoint>
+        </Track>
+        <Track>
+          <Trackpoint>
+            <Time>2022-04-01T06:30:10Z</Time>
+            <Position><LatitudeDegrees>59.93</LatitudeDegrees><LongitudeDegrees>30.31</LongitudeDegrees></Position>
+            <Cadence>90</Cadence>
+          </Trackpoint>
+        </Track>
+      </Lap>
+      <Lap StartTime="2022-04-01T06:30:10Z">
+        <TotalTimeSeconds>0</TotalTimeSeconds>
+        <DistanceMeters>0</DistanceMeters>
+        <Calories>0</Calories>
+        <Intensity>Active</Intensity>
+        <TriggerMethod>Manual</TriggerMethod>
+      </Lap>
+      <Notes>Easy run</Notes>
+    </Activity>
+  </Activities>
+  <Courses>
+    <Course>
+      <Name>Planned</Name>
+      <Track>
+        <Trackpoint>
+          <Position><LatitudeDegrees>59.92</LatitudeDegrees><LongitudeDegrees>30.30</LongitudeDegrees></Position>
+        </Trackpoint>
+      </Track>
+    </Course>
+  </Courses>
+</TrainingCenterDatabase>`
+
+func TestUnmarshalTCX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	tcx, err := UnmarshalTCX([]byte(testTCX))
+	r.NoError(err)
+	a.Equal("Planned", tcx.Name())
+	a.Equal("Easy run", tcx.Description())
+	r.Len(tcx.Activities, 1)
+	a.Equal(SportRunning, tcx.Activities[0].Sport)
+	a.Equal(time.Date(2022, time.April, 1, 6, 30, 0, 0, time.UTC), tcx.Activities[0].Id)
+
+	// trackpoints without a position and empty laps are skipped,
+	// the courses are ignored when activities are present
+	segments, err := tcx.Segments()
+	r.NoError(err)
+	r.Len(segments, 1)
+	r.Len(segments[0].Trackpoints, 2)
+
+	first, second := &segments[0].Trackpoints[0], &segments[0].Trackpoints[1]
+	a.Equal(59.92, first.Position.LatitudeDegrees)
+	r.NotNil(first.AltitudeMeters)
+	a.Equal(10.5, *first.AltitudeMeters)
+	a.Equal(uint8(120), first.HeartRate())
+	a.Equal(uint8(82), first.CadenceValue())
+	a.Nil(second.AltitudeMeters)
+	a.Equal(uint8(0), second.HeartRate())
+	a.Equal(uint8(90), second.CadenceValue())
+	r.NotNil(second.Time)
+	a.Equal(time.Date(2022, time.April, 1, 6, 30, 10, 0, time.UTC), *second.Time)
+
+	// Error tests
+	_, err = UnmarshalTCX([]byte(`<TrainingCenterDatabase>`))
+	a.Error(err)
+	tcx, err = UnmarshalTCX([]byte(`<TrainingCenterDatabase></TrainingCenterDatabase>`))
+	r.NoError(err)
+	_, err = tcx.Segments()
+	a.ErrorIs(err, ErrNoPoints)
+}
+
+func TestMarshalTCX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	tcx, err := UnmarshalTCX([]byte(testTCX))
+	r.NoError(err)
+	b, err := MarshalTCX(tcx)
+	r.NoError(err)
+	a.Contains(string(b), `<TrainingCenterDatabase xmlns="`+Namespace+`">`)
+	a.Contains(string(b), `<TPX xmlns="`+ExtensionNamespace+`">`)
+
+	// the marshaled file must contain the same data
+	unmarshaled, err := UnmarshalTCX(b)
+	r.NoError(err)
+	a.Equal(tcx, unmarshaled)
+}
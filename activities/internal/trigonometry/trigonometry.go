@@ -65,3 +65,16 @@ func CoordsBetween(lat1, lon1, lat2, lon2 Degree, fraction float64) (Degree, Deg
 
 	return lat3.ToDegrees(), lon3.ToDegrees()
 }
+
+// BearingBetweenCoords returns the initial bearing when moving from the first
+// to the second coordinate, clockwise from the north in the range [0, 360)
+func BearingBetweenCoords(lat1, lon1, lat2, lon2 Degree) Degree {
+	la1, la2 := lat1.ToRadians(), lat2.ToRadians()
+	deltaLon := (lon2 - lon1).ToRadians()
+
+	y := RadSin(deltaLon) * RadCos(la2)
+	x := RadCos(la1)*RadSin(la2) - RadSin(la1)*RadCos(la2)*RadCos(deltaLon)
+
+	bearing := Radian(math.Atan2(y, x)).ToDegrees()
+	return Degree(math.Mod(float64(bearing)+360, 360))
+}
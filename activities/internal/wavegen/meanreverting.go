@@ -0,0 +1,35 @@
+// Copyright 2022 Artem Mikheev
+
+package wavegen
+
+import (
+	"math"
+
+	"github.com/renbou/jogmock/activities/internal/randutil"
+)
+
+// MeanReverting is an Ornstein–Uhlenbeck process, which randomly drifts away from
+// the average while being pulled back to it, so its long-run mean is the average
+type MeanReverting struct {
+	// the average value of the process
+	Average float64
+	// standard deviation of the random change of each value
+	Volatility float64
+	// number of values in which about 63% of the deviation from the average is reverted
+	ReversionTime float64
+	// values are never lower than the minimum
+	Min float64
+	// source of the random changes
+	Rand *randutil.Rand
+
+	started bool
+	value   float64
+}
+
+func (m *MeanReverting) Next() float64 {
+	if !m.started {
+		m.value, m.started = m.Average, true
+	}
+	m.value += (m.Average-m.value)/m.ReversionTime + m.Volatility*m.Rand.NormFloat64()
+	return math.Max(m.Min, m.value)
+}
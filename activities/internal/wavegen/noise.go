@@ -0,0 +1,78 @@
+// Copyright 2022 Artem Mikheev
+
+package wavegen
+
+import "github.com/renbou/jogmock/activities/internal/randutil"
+
+// octave is a single octave of one-dimensional gradient noise,
+// which is generated as the position moves along the lattice
+type octave struct {
+	// position between the current lattice points and the step per value
+	position float64
+	step     float64
+	// gradients at the current lattice points
+	prevGradient float64
+	nextGradient float64
+}
+
+// Noise is multi-octave Perlin (gradient) noise around the average. Each octave has
+// twice the frequency of the previous one, and its amplitude is multiplied by the
+// persistence. Since the gradients are symmetric, the mean of the noise is the average.
+type Noise struct {
+	// the average value of the noise
+	Average float64
+	// maximum deviation of the noise from the average
+	Amplitude float64
+	// period of the first octave, which is the number of values between its lattice points
+	Period int
+	// number of octaves
+	Octaves int
+	// amplitude factor of each next octave
+	Persistence float64
+	// source of the random gradients
+	Rand *randutil.Rand
+
+	octaves []octave
+}
+
+// fade is the quintic fade curve of Perlin noise
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func (n *Noise) init() {
+	n.octaves = make([]octave, n.Octaves)
+	step := 1 / float64(n.Period)
+	for i := range n.octaves {
+		n.octaves[i] = octave{
+			step:         step,
+			prevGradient: n.Rand.Float64InRange(-1, 1),
+			nextGradient: n.Rand.Float64InRange(-1, 1),
+		}
+		step *= 2
+	}
+}
+
+func (n *Noise) Next() float64 {
+	if n.octaves == nil {
+		n.init()
+	}
+
+	var value, total float64
+	amplitude := 1.0
+	for i := range n.octaves {
+		o := &n.octaves[i]
+		for o.position >= 1 {
+			o.position--
+			o.prevGradient, o.nextGradient = o.nextGradient, n.Rand.Float64InRange(-1, 1)
+		}
+		// the values of gradient noise lie in range (-0.5, 0.5), so they are doubled
+		t := o.position
+		lo, hi := o.prevGradient*t, o.nextGradient*(t-1)
+		value += 2 * amplitude * (lo + (hi-lo)*fade(t))
+		total += amplitude
+		amplitude *= n.Persistence
+		o.position += o.step
+	}
+	return n.Average + n.Amplitude*value/total
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 Artem Mikheev
+
+package wavegen
+
+// Replay repeats the reference values scaled so that their mean
+// is the average, starting over after reaching the last one
+type Replay struct {
+	// the average value of the replayed values
+	Average float64
+	// reference values, which must be positive
+	Values []float64
+
+	scale float64
+	index int
+}
+
+func (r *Replay) Next() float64 {
+	if r.scale == 0 {
+		var sum float64
+		for _, value := range r.Values {
+			sum += value
+		}
+		r.scale = r.Average / (sum / float64(len(r.Values)))
+	}
+
+	value := r.Values[r.index] * r.scale
+	r.index = (r.index + 1) % len(r.Values)
+	return value
+}
@@ -2,11 +2,7 @@
 
 package wavegen
 
-import (
-	"math/rand"
-
-	"github.com/renbou/jogmock/activities/internal/randutil"
-)
+import "github.com/renbou/jogmock/activities/internal/randutil"
 
 // SlopeOptions are the options used to generate
 // a single wave slope. Pretty much a more general version
@@ -32,6 +28,8 @@ type Wave struct {
 	RareSlopeChance float64
 	// the average value of the slope
 	Average float64
+	// source of the random slopes
+	Rand *randutil.Rand
 	// roughly equals the period of the current slope
 	slopePeriod int
 	// index of the current segment in the slope
@@ -44,7 +42,7 @@ type Wave struct {
 }
 
 func (w *Wave) generateSlopeType() slopeType {
-	if rand.Float64() < w.RareSlopeChance {
+	if w.Rand.Float64() < w.RareSlopeChance {
 		return rareSlope
 	} else {
 		return commonSlope
@@ -56,7 +54,7 @@ func (w *Wave) generateSlope() float64 {
 		// rare slope should be generated as the maximum common slope
 		// size with an added random slope to the maximum rare slope
 		randomRange := w.RareSlope.Slope - w.CommonSlope.Slope
-		rnd := randutil.Float64InRange(-randomRange, randomRange)
+		rnd := w.Rand.Float64InRange(-randomRange, randomRange)
 		if rnd <= 0 {
 			return -w.CommonSlope.Slope + rnd
 		} else {
@@ -64,7 +62,7 @@ func (w *Wave) generateSlope() float64 {
 		}
 	} else {
 		// common slope is simply a random slope with the given bounds
-		return randutil.Float64InRange(-w.CommonSlope.Slope, w.CommonSlope.Slope)
+		return w.Rand.Float64InRange(-w.CommonSlope.Slope, w.CommonSlope.Slope)
 	}
 }
 
@@ -75,7 +73,7 @@ func (w *Wave) generateSlopePeriod() int {
 	} else {
 		min, max = w.CommonSlope.MinPeriod, w.CommonSlope.MaxPeriod
 	}
-	return randutil.IntInRange(min, max)
+	return w.Rand.IntInRange(min, max)
 }
 
 func (w *Wave) nextCycle() {
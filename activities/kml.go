@@ -0,0 +1,76 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import "github.com/renbou/jogmock/activities/internal/kml"
+
+// ParseKML returns the points of all LineString and gx:Track geometries of
+// a KML file as records containing the position, the altitude if specified,
+// and the timestamp for the points of tracks with timestamps
+func ParseKML(b []byte) ([]Record, error) {
+	kmlFile, err := kml.UnmarshalKML(b)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := kmlSegments(kmlFile)
+	if err != nil {
+		return nil, err
+	}
+	return joinSegments(segments), nil
+}
+
+func kmlSegments(kmlFile *kml.KML) ([]pointSegment, error) {
+	segments, err := kmlFile.Segments()
+	if err != nil {
+		return nil, err
+	}
+
+	pointSegments := make([]pointSegment, len(segments))
+	for i, segment := range segments {
+		pointSegments[i].name = segment.Name
+		for _, point := range segment.Points {
+			record := Record{
+				Lat:      point.Lat,
+				Lon:      point.Lon,
+				Altitude: point.Altitude,
+			}
+			if point.Time != nil {
+				record.Timestamp = *point.Time
+			}
+			pointSegments[i].points = append(pointSegments[i].points, record)
+		}
+	}
+	return pointSegments, nil
+}
+
+// BuildFromKML builds the activity from all of the line strings and tracks of a KML
+// file and finalizes it. Named Point placemarks are used as lap markers the same way
+// as GPX waypoints, and the timestamps of tracks are used if ActivityOptions.SourceTiming
+// was set. The name and description of the document are used unless they were already set.
+func (a *Activity) BuildFromKML(b []byte) error {
+	kmlFile, err := kml.UnmarshalKML(b)
+	if err != nil {
+		return err
+	}
+	segments, err := kmlSegments(kmlFile)
+	if err != nil {
+		return err
+	}
+	waypoints, err := kmlFile.Waypoints()
+	if err != nil {
+		return err
+	}
+
+	if a.name == "" {
+		a.name = kmlFile.Name()
+	}
+	if a.description == "" {
+		a.description = kmlFile.Description()
+	}
+
+	route := &importedRoute{segments: segments, waypointLaps: true}
+	for _, point := range waypoints {
+		route.waypoints = append(route.waypoints, waypoint{name: point.Name, lat: point.Lat, lon: point.Lon})
+	}
+	return a.build(route)
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// LapTrigger describes what caused a lap to end
+type LapTrigger int
+
+const (
+	ManualLapTrigger LapTrigger = iota
+	// the lap ended after covering the auto-lap distance
+	DistanceLapTrigger
+	// the lap ended at a marked position, e.g. a waypoint
+	PositionLapTrigger
+	// the lap ended along with the activity
+	SessionEndLapTrigger
+)
+
+// LapOptions configures the laps added to the activity in addition
+// to the lap markers of imported routes, e.g. their waypoints
+type LapOptions struct {
+	// Distance of the auto-laps in km, no auto-laps are added if 0
+	AutoLapDistance float64
+	// Distances from the start of the activity in km at which new laps begin
+	Distances []float64
+}
+
+const (
+	// MileDistance is the length of a mile in km, which can be used for auto-laps every mile
+	MileDistance       = 1.609344
+	minAutoLapDistance = 0.1
+)
+
+func (o *LapOptions) validateAndSetDefaults() error {
+	if o.AutoLapDistance < 0 {
+		return errors.New("auto-lap distance cannot be negative")
+	}
+	if o.AutoLapDistance != 0 && o.AutoLapDistance < minAutoLapDistance {
+		return errors.New("auto-lap distance is too small (less than 100 m)")
+	}
+	for i, distance := range o.Distances {
+		if distance <= 0 {
+			return errors.New("lap distances must be positive")
+		}
+		if i > 0 && distance <= o.Distances[i-1] {
+			return errors.New("lap distances must be sorted")
+		}
+	}
+	return nil
+}
+
+// Lap contains the totals of a single lap of the activity. The totals are calculated
+// up to the first record of the next lap if there is one, so that they add up to
+// the totals of the activity, while the averages only use the records of the lap.
+type Lap struct {
+	// Name of the lap marker at which the lap began
+	Name string
+	// What caused the lap to end
+	Trigger LapTrigger
+	Start   time.Time
+	End     time.Time
+	// Durations of the lap with and without the stops made during it
+	ElapsedDuration time.Duration
+	MovingDuration  time.Duration
+	// Distance from the start of the activity at which the lap began, in km
+	StartDistance float64
+	// Distance covered during the lap in km
+	Distance float64
+	// Average speed while moving and the maximum speed in km/h
+	AverageSpeed float64
+	MaxSpeed     float64
+	// Total elevation gain and loss in m, 0 if the altitude is unknown
+	ElevationGain float64
+	ElevationLoss float64
+	// Averages and maximums of the known values, 0 if unknown
+	AverageHeartRate uint8
+	MaxHeartRate     uint8
+	AverageCadence   uint8
+	AveragePower     uint16
+	MaxPower         uint16
+	// records of the lap are in range [first, last)
+	first int
+	last  int
+}
+
+// addOptionLapMarkers adds the lap markers configured by the lap options
+func (a *Activity) addOptionLapMarkers() {
+	o := a.lapOptions
+	if o == nil {
+		return
+	}
+	if o.AutoLapDistance > 0 {
+		for distance := o.AutoLapDistance; distance < a.TotalDistance(); distance += o.AutoLapDistance {
+			a.addLapMarker(LapMarker{Distance: distance, Trigger: DistanceLapTrigger})
+		}
+	}
+	for _, distance := range o.Distances {
+		a.addLapMarker(LapMarker{Distance: distance, Trigger: ManualLapTrigger})
+	}
+}
+
+// lapStarts returns the indices of the records at which each of the laps begins along
+// with the markers of the laps, the first lap always begins at the first record. Lap
+// markers at the start of the activity only name the first lap, while markers at the
+// end of the activity and other markers in the same place are ignored.
+func (a *Activity) lapStarts() ([]int, []*LapMarker) {
+	starts := []int{0}
+	markers := []*LapMarker{nil}
+	i := 0
+	for j := range a.lapMarkers {
+		marker := &a.lapMarkers[j]
+		for i < len(a.records) && a.records[i].Distance < marker.Distance {
+			i++
+		}
+		if i >= len(a.records)-1 {
+			break
+		}
+		if i == 0 && markers[0] == nil {
+			markers[0] = marker
+		} else if i > starts[len(starts)-1] {
+			starts = append(starts, i)
+			markers = append(markers, marker)
+		}
+	}
+	return starts, markers
+}
+
+// Laps returns the laps of the activity, which begin at the lap markers. An activity
+// without lap markers consists of a single lap. This should be used only after fully
+// constructing the activity.
+func (a *Activity) Laps() []Lap {
+	if len(a.records) == 0 {
+		return nil
+	}
+
+	starts, markers := a.lapStarts()
+	laps := make([]Lap, len(starts))
+	for i, start := range starts {
+		end, trigger := len(a.records), SessionEndLapTrigger
+		if i+1 < len(starts) {
+			end, trigger = starts[i+1], markers[i+1].Trigger
+		}
+		laps[i] = a.lap(start, end)
+		laps[i].Trigger = trigger
+		if markers[i] != nil {
+			laps[i].Name = markers[i].Name
+		}
+	}
+	return laps
+}
+
+// lap calculates the totals of the lap containing the records in range [start, end)
+func (a *Activity) lap(start, end int) Lap {
+	first, last := &a.records[start], &a.records[len(a.records)-1]
+	if end < len(a.records) {
+		last = &a.records[end]
+	}
+
+	lap := Lap{
+		Start:         first.Timestamp,
+		End:           last.Timestamp,
+		StartDistance: first.Distance,
+		Distance:      last.Distance - first.Distance,
+		first:         start,
+		last:          end,
+	}
+	if start == 0 {
+		lap.Start = a.startTime
+	}
+	lap.ElapsedDuration = lap.End.Sub(lap.Start)
+	lap.MovingDuration = lap.ElapsedDuration - a.StoppedDuration(lap.Start, lap.End)
+	if lap.MovingDuration > 0 {
+		lap.AverageSpeed = lap.Distance / lap.MovingDuration.Hours()
+	}
+
+	var heartRateSum, heartRateCount, cadenceSum, cadenceCount, powerSum, powerCount int
+	for i := start; i < end; i++ {
+		record := &a.records[i]
+		lap.MaxSpeed = math.Max(lap.MaxSpeed, record.Speed)
+		if record.HeartRate != 0 {
+			heartRateSum += int(record.HeartRate)
+			heartRateCount++
+			if record.HeartRate > lap.MaxHeartRate {
+				lap.MaxHeartRate = record.HeartRate
+			}
+		}
+		if record.Cadence != 0 {
+			cadenceSum += int(record.Cadence)
+			cadenceCount++
+		}
+		// the power is known for all of the records, including while coasting
+		if a.power != nil {
+			powerSum += int(record.Power)
+			powerCount++
+			if record.Power > lap.MaxPower {
+				lap.MaxPower = record.Power
+			}
+		}
+
+		// the elevation is counted up to the first record of the next lap
+		next := last
+		if i+1 < len(a.records) {
+			next = &a.records[i+1]
+		}
+		if record.Altitude != nil && next.Altitude != nil {
+			if climb := *next.Altitude - *record.Altitude; climb > 0 {
+				lap.ElevationGain += climb
+			} else {
+				lap.ElevationLoss -= climb
+			}
+		}
+	}
+
+	if heartRateCount > 0 {
+		lap.AverageHeartRate = uint8(math.Round(float64(heartRateSum) / float64(heartRateCount)))
+	}
+	if cadenceCount > 0 {
+		lap.AverageCadence = uint8(math.Round(float64(cadenceSum) / float64(cadenceCount)))
+	}
+	if powerCount > 0 {
+		lap.AveragePower = uint16(math.Round(float64(powerSum) / float64(powerCount)))
+	}
+	return lap
+}
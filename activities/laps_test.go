@@ -0,0 +1,110 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/renbou/jogmock/activities/internal/tcx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLaps(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// a lap every km of the 5.5 km route, along with the explicit lap
+	activity := newActivity(r, &ActivityOptions{Laps: &LapOptions{AutoLapDistance: 1, Distances: []float64{2.5}}})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	laps := activity.Laps()
+	r.Len(laps, 7)
+	var distance float64
+	var duration time.Duration
+	for i, lap := range laps {
+		distance += lap.Distance
+		duration += lap.ElapsedDuration
+		a.Equal(lap.ElapsedDuration, lap.MovingDuration)
+		a.Equal(lap.End.Sub(lap.Start), lap.ElapsedDuration)
+		a.GreaterOrEqual(lap.MaxSpeed, lap.AverageSpeed)
+		if i > 0 {
+			a.Equal(laps[i-1].End, lap.Start)
+		}
+	}
+	a.InDelta(activity.TotalDistance(), distance, 1e-9)
+	a.Equal(activity.TotalDuration(), duration)
+	a.Equal(activity.Start(), laps[0].Start)
+	a.InDelta(1, laps[0].Distance, 0.01)
+	a.InDelta(0.5, laps[2].Distance, 0.01)
+	a.InDelta(2.5, laps[3].StartDistance, 0.01)
+	a.Equal(DistanceLapTrigger, laps[0].Trigger)
+	a.Equal(ManualLapTrigger, laps[2].Trigger)
+	a.Equal(SessionEndLapTrigger, laps[6].Trigger)
+	a.InDelta(10, laps[1].AverageSpeed, 2)
+
+	// the laps are exported to TCX
+	b, err := activity.MarshalTCX()
+	r.NoError(err)
+	file, err := tcx.UnmarshalTCX(b)
+	r.NoError(err)
+	r.Len(file.Activities[0].Laps, 7)
+	a.Equal(tcx.TriggerDistance, file.Activities[0].Laps[0].TriggerMethod)
+	a.Equal(tcx.TriggerManual, file.Activities[0].Laps[2].TriggerMethod)
+	a.InDelta(laps[1].MovingDuration.Seconds(), file.Activities[0].Laps[1].TotalTimeSeconds, 1e-9)
+
+	// and to GPX as separate tracks, which are imported as laps again,
+	// though the imported records may begin the laps a second apart
+	b, err = activity.MarshalGPX()
+	r.NoError(err)
+	imported := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(imported.BuildFromGPXWithOptions(b, &GPXOptions{SegmentLaps: true}))
+	importedLaps := imported.Laps()
+	r.Len(importedLaps, 7)
+	for i := range laps {
+		a.InDelta(laps[i].Distance, importedLaps[i].Distance, 0.01)
+		a.InDelta(laps[i].ElapsedDuration.Seconds(), importedLaps[i].ElapsedDuration.Seconds(), 2)
+	}
+
+	// the elevation is counted along with the named laps of the route
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromGPXWithOptions([]byte(testSegmentsGPX), &GPXOptions{SegmentLaps: true}))
+	laps = activity.Laps()
+	r.Len(laps, 2)
+	a.Equal("", laps[0].Name)
+	a.Equal("Back", laps[1].Name)
+	a.InDelta(10, laps[0].ElevationGain, 0.01)
+	a.Zero(laps[0].ElevationLoss)
+	a.Zero(laps[1].ElevationGain)
+
+	// the laps of imported activities end manually, and the averages only use the known values
+	activity = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	activity.lapOptions = &LapOptions{AutoLapDistance: MileDistance}
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	laps = activity.Laps()
+	r.Len(laps, 2)
+	a.Equal(ManualLapTrigger, laps[0].Trigger)
+	a.Equal(uint8(120), laps[0].AverageHeartRate)
+	a.Equal(uint8(140), laps[0].MaxHeartRate)
+	a.Zero(laps[0].AverageCadence)
+	a.Equal(uint8(85), laps[1].AverageCadence)
+	a.InDelta(10, laps[0].ElevationGain, 0.01)
+	a.InDelta(20, laps[1].ElevationLoss, 0.01)
+
+	// Error tests
+	for _, laps := range []*LapOptions{
+		{AutoLapDistance: -1},
+		{AutoLapDistance: 0.01},
+		{Distances: []float64{0}},
+		{Distances: []float64{2, 1}},
+	} {
+		_, err := NewActivity(&ActivityOptions{
+			Type:         RunActivity,
+			Start:        time.Now(),
+			DesiredSpeed: 10,
+			Laps:         laps,
+		})
+		a.Error(err)
+	}
+	a.Nil(newActivity(r, nil).Laps())
+}
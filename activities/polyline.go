@@ -0,0 +1,32 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import "github.com/renbou/jogmock/activities/internal/polyline"
+
+// DefaultPolylinePrecision is the precision used by Google, OSRM and Valhalla use 6
+const DefaultPolylinePrecision = 5
+
+// ParsePolyline decodes a Google encoded polyline with coordinates encoded
+// with the given precision into records containing only the position
+func ParsePolyline(s string, precision int) ([]Record, error) {
+	points, err := polyline.Decode(s, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(points))
+	for i, point := range points {
+		records[i] = Record{Lat: point.Lat, Lon: point.Lon}
+	}
+	return records, nil
+}
+
+// BuildFromPolyline builds the activity from a Google encoded polyline and finalizes it
+func (a *Activity) BuildFromPolyline(s string, precision int) error {
+	records, err := ParsePolyline(s, precision)
+	if err != nil {
+		return err
+	}
+	return a.BuildFromRecords(records)
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// PowerStep is a single step of the target power profile
+type PowerStep struct {
+	// distance from the start of the activity in km at which the step begins
+	Distance float64
+	// target power in watts
+	Power float64
+}
+
+// PowerOptions configures the physics-based simulation of rides. Instead of following
+// the speed wave, the rider holds the target power, and the speed is derived from the
+// forces of gravity, rolling resistance and air drag on the route, including the wind,
+// as modeled by ActivityOptions.Ride.
+// The generated speed is still used to add some variation to the held power. Since the speed
+// depends on the route, the average speed only approximately equals DesiredSpeed, and
+// only if Power isn't set.
+type PowerOptions struct {
+	// Target power in watts. By default, the power needed for riding on the flat
+	// in still air at DesiredSpeed is used
+	Power float64
+	// Target power profile, overriding Power from the distance of the first step.
+	// The steps must be sorted by distance
+	Profile []PowerStep
+	// Speed of the wind in km/h
+	WindSpeed float64
+	// Direction from which the wind blows, in degrees clockwise from the north
+	WindDirection float64
+	// Average cadence in rpm while pedaling
+	Cadence float64
+}
+
+const (
+	// bounds of the simulated speed in m/s, riders brake on
+	// steep descents and would fall over when going too slowly
+	minSimulatedSpeed = 1
+	maxSimulatedSpeed = 70 / 3.6
+	// cadence changes slower than the power, e.g. with 10% more power the cadence is about 2.4% higher
+	cadencePowerExponent = 0.25
+	maxCadence           = 150
+)
+
+var DefaultRideCadence float64 = 88
+
+func (o *PowerOptions) validateAndSetDefaults(activityType ActivityType, desiredSpeed float64, ride *RideOptions) error {
+	if activityType != RideActivity {
+		return errors.New("power simulation is only supported for rides")
+	}
+	if o.Power < 0 || o.WindSpeed < 0 || o.Cadence < 0 {
+		return errors.New("power, wind speed and cadence cannot be negative")
+	}
+	for i, step := range o.Profile {
+		if step.Power < 0 {
+			return errors.New("power profile steps cannot have negative power")
+		}
+		if i > 0 && step.Distance <= o.Profile[i-1].Distance {
+			return errors.New("power profile steps must be sorted by distance")
+		}
+	}
+
+	if o.Cadence == 0 {
+		o.Cadence = DefaultRideCadence
+	}
+	if o.Power == 0 {
+		o.Power = ride.flatPower(desiredSpeed / 3.6)
+	}
+	return nil
+}
+
+// targetPower returns the target power at the given distance in km
+func (o *PowerOptions) targetPower(distance float64) float64 {
+	i := sort.Search(len(o.Profile), func(i int) bool {
+		return o.Profile[i].Distance > distance
+	})
+	if i == 0 {
+		return o.Power
+	}
+	return o.Profile[i-1].Power
+}
+
+// simulatePower simulates a second of riding from the last record towards next,
+// returning the new speed in km/h along with the held power and cadence.
+// variation is the relative variation of the power, e.g. 1.1 for 10% more power.
+func (a *Activity) simulatePower(next *Record, variation float64) (speed, power, cadence float64) {
+	o, ride := a.power, a.ride
+	prev := a.lastRecord()
+	velocity := prev.Speed / 3.6
+
+	power = o.targetPower(prev.Distance) * variation
+	if velocity >= maxSimulatedSpeed {
+		// coasting
+		power = 0
+	} else {
+		cadence = math.Min(maxCadence, o.Cadence*math.Pow(variation, cadencePowerExponent))
+	}
+
+	angle := math.Atan(grade(prev, next))
+	headwind := o.WindSpeed / 3.6 * math.Cos((o.WindDirection-prev.BearingTo(next))*math.Pi/180)
+	airspeed := velocity + headwind
+
+	force := power/math.Max(velocity, minSimulatedSpeed) -
+		0.5*airDensity*ride.CdA*airspeed*math.Abs(airspeed) -
+		ride.Mass*gravityAcceleration*(ride.Crr*math.Cos(angle)+math.Sin(angle))
+	velocity = math.Max(minSimulatedSpeed, math.Min(maxSimulatedSpeed, velocity+force/ride.Mass))
+	return velocity * 3.6, power, cadence
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatedPower(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// the default power is enough for riding at the desired speed on the flat
+	activity := newActivity(r, &ActivityOptions{Type: RideActivity, DesiredSpeed: 25, Power: &PowerOptions{}})
+	r.NoError(activity.BuildFromRecords(gradeTestRoute()))
+	flat := averageSpeed(activity, 1, 2.8)
+	a.InEpsilon(25, flat, 0.1)
+	a.Less(averageSpeed(activity, 3.4, 4.8), flat*0.6)
+	a.Greater(averageSpeed(activity, 5.5, 8), flat*1.2)
+
+	expectedPower := (&RideOptions{Mass: DefaultRideMass, CdA: DefaultRideCdA, Crr: DefaultRideCrr}).flatPower(25 / 3.6)
+	var powerSum, cadenceSum float64
+	var count int
+	for _, record := range activity.Records() {
+		if record.Distance > 1 && record.Distance < 2.8 {
+			powerSum += float64(record.Power)
+			cadenceSum += float64(record.Cadence)
+			count++
+		}
+	}
+	r.Greater(count, 0)
+	a.InEpsilon(expectedPower, powerSum/float64(count), 0.1)
+	a.InEpsilon(DefaultRideCadence, cadenceSum/float64(count), 0.05)
+
+	// the power is exported along with the records
+	b, err := activity.MarshalTCX()
+	r.NoError(err)
+	a.Contains(string(b), "<Watts>")
+	b, err = activity.MarshalCSV()
+	r.NoError(err)
+	a.Contains(string(b), ",power\n")
+
+	// the route heads north, so the wind from the north is a headwind
+	headwind := newActivity(r, &ActivityOptions{Type: RideActivity, DesiredSpeed: 25, Power: &PowerOptions{WindSpeed: 15, WindDirection: 0}})
+	r.NoError(headwind.BuildFromRecords(gradeTestRoute()))
+	tailwind := newActivity(r, &ActivityOptions{Type: RideActivity, DesiredSpeed: 25, Power: &PowerOptions{WindSpeed: 15, WindDirection: 180}})
+	r.NoError(tailwind.BuildFromRecords(gradeTestRoute()))
+	a.Less(averageSpeed(headwind, 1, 2.8), flat*0.9)
+	a.Greater(averageSpeed(tailwind, 1, 2.8), flat*1.1)
+
+	// the profile overrides the power from the distance of the first step
+	profiled := newActivity(r, &ActivityOptions{
+		Type:         RideActivity,
+		DesiredSpeed: 25,
+		Power:        &PowerOptions{Power: 100, Profile: []PowerStep{{Distance: 1.5, Power: 250}}},
+	})
+	r.NoError(profiled.BuildFromRecords(gradeTestRoute()))
+	a.Greater(averageSpeed(profiled, 1.7, 2.8), averageSpeed(profiled, 0.5, 1.4)*1.2)
+	a.Equal(100.0, profiled.power.targetPower(1))
+	a.Equal(250.0, profiled.power.targetPower(2))
+
+	// the ride options are used for the simulation, so a heavier rider climbs slower at the same power
+	heavy := newActivity(r, &ActivityOptions{
+		Type:         RideActivity,
+		DesiredSpeed: 25,
+		Ride:         &RideOptions{Mass: 120},
+		Power:        &PowerOptions{Power: activity.power.Power},
+	})
+	r.NoError(heavy.BuildFromRecords(gradeTestRoute()))
+	a.Less(averageSpeed(heavy, 3.4, 4.8), averageSpeed(activity, 3.4, 4.8)*0.8)
+
+	// Error tests
+	_, err = NewActivity(&ActivityOptions{
+		Type: RunActivity, Start: time.Now(), DesiredSpeed: 10, Power: &PowerOptions{},
+	})
+	a.Error(err)
+	for _, options := range []PowerOptions{
+		{Power: -1},
+		{WindSpeed: -5},
+		{Profile: []PowerStep{{Distance: 2, Power: 100}, {Distance: 1, Power: 100}}},
+		{Profile: []PowerStep{{Distance: 1, Power: -100}}},
+	} {
+		_, err := NewActivity(&ActivityOptions{
+			Type: RideActivity, Start: time.Now(), DesiredSpeed: 25, Power: &options,
+		})
+		a.Error(err)
+	}
+	_, err = NewActivity(&ActivityOptions{
+		Type: RideActivity, Start: time.Now(), DesiredSpeed: 25, Ride: &RideOptions{CdA: -1}, Power: &PowerOptions{},
+	})
+	a.Error(err)
+}
@@ -4,6 +4,7 @@ package activities
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/renbou/jogmock/activities/internal/trigonometry"
@@ -12,15 +13,24 @@ import (
 // Record represents a single activity record.
 // Only Lat, Lon and Altitude must be specified for
 // building the activity, the other parameters will be
-// calculated automagically.
+// calculated automagically. HeartRate and Cadence are
+// optional and kept from the imported points if known.
 type Record struct {
 	Lat float64 `json:"latitude"`
 	Lon float64 `json:"longitude"`
-	// altitude in meters
-	Altitude  float64   `json:"altitude"`
+	// altitude in meters, nil if unknown
+	Altitude  *float64  `json:"altitude,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 	Speed     float64   `json:"speed"`
 	Distance  float64   `json:"distance"`
+	// heart rate in bpm, 0 if unknown
+	HeartRate uint8 `json:"heart_rate,omitempty"`
+	// cadence in rpm, 0 if unknown. The cadence of runs is in strides (pairs of steps)
+	// per minute, and FractionalCadence is 0.5 if the number of steps is odd
+	Cadence           uint8   `json:"cadence,omitempty"`
+	FractionalCadence float64 `json:"fractional_cadence,omitempty"`
+	// power in watts, 0 if unknown or not pedaling
+	Power uint16 `json:"power,omitempty"`
 }
 
 // DistanceTo calculates the distance between this and another record on the globe, in kilometres
@@ -31,25 +41,69 @@ func (r *Record) DistanceTo(other *Record) float64 {
 	)
 }
 
+// BearingTo calculates the initial bearing when moving from this to another record,
+// in degrees clockwise from the north
+func (r *Record) BearingTo(other *Record) float64 {
+	return float64(trigonometry.BearingBetweenCoords(
+		trigonometry.Degree(r.Lat), trigonometry.Degree(r.Lon),
+		trigonometry.Degree(other.Lat), trigonometry.Degree(other.Lon),
+	))
+}
+
 // String implements the Stringer interface
 func (r *Record) String() string {
+	altitude := "unknown"
+	if r.Altitude != nil {
+		altitude = fmt.Sprint(*r.Altitude)
+	}
 	return fmt.Sprintf("@%d:%d:%d.%v: lat=%v lon=%v alt=%v speed=%v distance=%v",
 		r.Timestamp.Hour(), r.Timestamp.Minute(), r.Timestamp.Second(), r.Timestamp.Nanosecond(),
-		r.Lat, r.Lon, r.Altitude, r.Speed, r.Distance,
+		r.Lat, r.Lon, altitude, r.Speed, r.Distance,
 	)
 }
 
+// altitudeInBetween interpolates the altitude between a and b, which is
+// only known if both of the altitudes are known or it lies at one of them
+func altitudeInBetween(a, b *float64, fraction float64) *float64 {
+	if fraction == 0 {
+		return a
+	} else if fraction == 1 {
+		return b
+	}
+	if a == nil || b == nil {
+		return nil
+	}
+	altitude := *a + (*b-*a)*fraction
+	return &altitude
+}
+
+// uint8InBetween interpolates a value which is unknown if 0 between a and b, which
+// is only known if both of the values are known or it lies at one of them
+func uint8InBetween(a, b uint8, fraction float64) uint8 {
+	if fraction == 0 {
+		return a
+	} else if fraction == 1 {
+		return b
+	}
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*fraction))
+}
+
 // RecordInBetween calculates the record c that lies in between records a, b such that
 // a.DistanceTo(c)/a.DistanceTo(b) == fraction
 func RecordInBetween(a, b *Record, fraction float64) Record {
-	altitude := a.Altitude + (b.Altitude-a.Altitude)*fraction
+	altitude := altitudeInBetween(a.Altitude, b.Altitude, fraction)
 	lat, lon := trigonometry.CoordsBetween(
 		trigonometry.Degree(a.Lat), trigonometry.Degree(a.Lon),
 		trigonometry.Degree(b.Lat), trigonometry.Degree(b.Lon),
 		fraction)
 	return Record{
-		Lat:      float64(lat),
-		Lon:      float64(lon),
-		Altitude: altitude,
+		Lat:       float64(lat),
+		Lon:       float64(lon),
+		Altitude:  altitude,
+		HeartRate: uint8InBetween(a.HeartRate, b.HeartRate, fraction),
+		Cadence:   uint8InBetween(a.Cadence, b.Cadence, fraction),
 	}
 }
@@ -0,0 +1,59 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+)
+
+// RideOptions describes the rider along with the bike, which is used to model
+// the physics of rides by the grade adjustment, the power simulation and the effort
+// followed by the synthesized heart rate. The defaults are used for any unset values.
+type RideOptions struct {
+	// Mass of the rider along with the bike in kg
+	Mass float64
+	// Drag area of the rider in m²
+	CdA float64
+	// Coefficient of rolling resistance
+	Crr float64
+}
+
+const (
+	// physical constants used by the ride model
+	airDensity          = 1.225
+	gravityAcceleration = 9.81
+)
+
+var (
+	DefaultRideMass float64 = 80
+	DefaultRideCdA          = 0.32
+	DefaultRideCrr          = 0.005
+)
+
+func (o *RideOptions) validateAndSetDefaults() error {
+	if o.Mass < 0 || o.CdA < 0 || o.Crr < 0 {
+		return errors.New("mass, drag area and rolling resistance cannot be negative")
+	}
+	if o.Mass == 0 {
+		o.Mass = DefaultRideMass
+	}
+	if o.CdA == 0 {
+		o.CdA = DefaultRideCdA
+	}
+	if o.Crr == 0 {
+		o.Crr = DefaultRideCrr
+	}
+	return nil
+}
+
+// power returns the power in watts needed for riding at the grade in still air at speed in m/s
+func (o *RideOptions) power(speed, grade float64) float64 {
+	angle := math.Atan(grade)
+	return speed * (0.5*airDensity*o.CdA*speed*speed + o.Mass*gravityAcceleration*(o.Crr*math.Cos(angle)+math.Sin(angle)))
+}
+
+// flatPower returns the power in watts needed for riding on the flat in still air at speed in m/s
+func (o *RideOptions) flatPower(speed float64) float64 {
+	return o.power(speed, 0)
+}
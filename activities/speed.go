@@ -0,0 +1,259 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/renbou/jogmock/activities/internal/randutil"
+	"github.com/renbou/jogmock/activities/internal/wavegen"
+)
+
+// SpeedGenerator generates the speed in km/h for each second of the activity after
+// the fade-in. The long-run mean of the generated speed must equal the desired speed
+// with which it was created.
+type SpeedGenerator interface {
+	Next() float64
+}
+
+// SpeedGeneratorType is the type of the built-in speed generator
+type SpeedGeneratorType string
+
+const (
+	// WaveGenerator generates the speed using the slopes of CommonSpeed and RareSpeed
+	WaveGenerator SpeedGeneratorType = "wave"
+	// NoiseGenerator generates the speed using multi-octave Perlin noise
+	NoiseGenerator SpeedGeneratorType = "noise"
+	// MeanRevertingGenerator generates the speed using an Ornstein–Uhlenbeck process,
+	// which randomly drifts away from DesiredSpeed while being pulled back to it
+	MeanRevertingGenerator SpeedGeneratorType = "mean-reverting"
+	// ReplayGenerator replays the speed of a reference activity scaled to DesiredSpeed
+	ReplayGenerator SpeedGeneratorType = "replay"
+)
+
+// SpeedGeneratorOptions selects and configures the generator of the speed. The speed
+// wave of CommonSpeed and RareSpeed is used by default. Only the options of the
+// selected generator are used. The amplitude and volatility are relative to
+// DesiredSpeed, so the same options can be used for any speed.
+type SpeedGeneratorOptions struct {
+	// Type of the generator, WaveGenerator by default
+	Type SpeedGeneratorType
+	// Creates the custom generator used instead of the built-in ones for the desired speed,
+	// which is derived from the route when TotalDuration is set. The generated speed is
+	// still retimed afterwards to exactly match the total duration.
+	NewGenerator func(desiredSpeed float64) SpeedGenerator
+
+	// Relative amplitude of the noise, e.g. 0.15 for up to 15% faster or slower
+	NoiseAmplitude float64
+	// Period of the first octave of the noise
+	NoisePeriod time.Duration
+	// Number of octaves of the noise, each with twice the frequency of the previous one
+	NoiseOctaves int
+	// Amplitude factor of each next octave of the noise
+	NoisePersistence float64
+
+	// Relative standard deviation of the change of the speed in a second
+	Volatility float64
+	// Time in which about 63% of the deviation from DesiredSpeed is reverted
+	ReversionTime time.Duration
+
+	// Speed of the reference activity in km/h for each second, e.g. taken from
+	// a timed route using ReferenceSpeedProfile
+	Reference []float64
+}
+
+const (
+	maxNoiseAmplitude = 0.5
+	maxNoiseOctaves   = 8
+	minNoisePeriod    = 5 * time.Second
+	// the stationary standard deviation of the mean-reverting speed is limited,
+	// so that the speed, which can't be lower than the minimum, rarely reaches it
+	maxMeanRevertingDeviation = 0.25
+	minMeanRevertingSpeed     = 0.1
+)
+
+var (
+	DefaultNoiseAmplitude   = 0.15
+	DefaultNoisePeriod      = 60 * time.Second
+	DefaultNoiseOctaves     = 3
+	DefaultNoisePersistence = 0.5
+	DefaultVolatility       = 0.03
+	DefaultReversionTime    = 30 * time.Second
+)
+
+var (
+	ErrNoReferenceSpeed      = errors.New("reference activity contains no timed movement")
+	ErrNoReferenceTimestamps = errors.New("reference route doesn't contain timestamps")
+)
+
+func (o *SpeedGeneratorOptions) validateAndSetDefaults() error {
+	if o.NewGenerator != nil {
+		return nil
+	}
+
+	switch o.Type {
+	case "":
+		o.Type = WaveGenerator
+	case WaveGenerator:
+	case NoiseGenerator:
+		if o.NoiseAmplitude < 0 || o.NoisePeriod < 0 || o.NoiseOctaves < 0 || o.NoisePersistence < 0 {
+			return errors.New("noise options cannot be negative")
+		}
+		if o.NoiseAmplitude == 0 {
+			o.NoiseAmplitude = DefaultNoiseAmplitude
+		}
+		if o.NoisePeriod == 0 {
+			o.NoisePeriod = DefaultNoisePeriod
+		}
+		if o.NoiseOctaves == 0 {
+			o.NoiseOctaves = DefaultNoiseOctaves
+		}
+		if o.NoisePersistence == 0 {
+			o.NoisePersistence = DefaultNoisePersistence
+		}
+		if o.NoiseAmplitude > maxNoiseAmplitude {
+			return errors.New("noise amplitude is too high (over 0.5)")
+		}
+		if o.NoisePeriod < minNoisePeriod {
+			return errors.New("noise period cannot be less than 5 seconds")
+		}
+		if o.NoiseOctaves > maxNoiseOctaves {
+			return errors.New("too many noise octaves (over 8)")
+		}
+		if o.NoisePersistence > 1 {
+			return errors.New("noise persistence should be in range (0, 1)")
+		}
+	case MeanRevertingGenerator:
+		if o.Volatility < 0 || o.ReversionTime < 0 {
+			return errors.New("mean-reverting speed options cannot be negative")
+		}
+		if o.Volatility == 0 {
+			o.Volatility = DefaultVolatility
+		}
+		if o.ReversionTime == 0 {
+			o.ReversionTime = DefaultReversionTime
+		}
+		if o.ReversionTime < time.Second {
+			return errors.New("reversion time cannot be less than a second")
+		}
+		if o.Volatility*math.Sqrt(o.ReversionTime.Seconds()/2) > maxMeanRevertingDeviation {
+			return errors.New("mean-reverting speed is too volatile for the reversion time")
+		}
+	case ReplayGenerator:
+		if len(o.Reference) == 0 {
+			return ErrNoReferenceSpeed
+		}
+		for _, speed := range o.Reference {
+			if speed <= 0 {
+				return errors.New("reference speeds must be positive")
+			}
+		}
+	default:
+		return errors.New("unknown speed generator, only wave, noise, mean-reverting and replay are known")
+	}
+	return nil
+}
+
+// speedGenerator creates the speed generator configured by the options
+func (o *ActivityOptions) speedGenerator(rnd *randutil.Rand) SpeedGenerator {
+	generator := o.SpeedGenerator
+	if generator == nil {
+		generator = &SpeedGeneratorOptions{Type: WaveGenerator}
+	}
+	if generator.NewGenerator != nil {
+		return generator.NewGenerator(o.DesiredSpeed)
+	}
+
+	switch generator.Type {
+	case NoiseGenerator:
+		return &wavegen.Noise{
+			Average:     o.DesiredSpeed,
+			Amplitude:   o.DesiredSpeed * generator.NoiseAmplitude,
+			Period:      int(generator.NoisePeriod / time.Second),
+			Octaves:     generator.NoiseOctaves,
+			Persistence: generator.NoisePersistence,
+			Rand:        rnd,
+		}
+	case MeanRevertingGenerator:
+		return &wavegen.MeanReverting{
+			Average:       o.DesiredSpeed,
+			Volatility:    o.DesiredSpeed * generator.Volatility,
+			ReversionTime: generator.ReversionTime.Seconds(),
+			Min:           o.DesiredSpeed * minMeanRevertingSpeed,
+			Rand:          rnd,
+		}
+	case ReplayGenerator:
+		return &wavegen.Replay{Average: o.DesiredSpeed, Values: generator.Reference}
+	default:
+		return &wavegen.Wave{
+			CommonSlope: wavegen.SlopeOptions{
+				Slope:     o.CommonSpeed.Slope,
+				Amplitude: o.CommonSpeed.Amplitude,
+				MinPeriod: o.CommonSpeed.MinDuration,
+				MaxPeriod: o.CommonSpeed.MaxDuration,
+			},
+			RareSlope: wavegen.SlopeOptions{
+				Slope:     o.RareSpeed.Slope,
+				Amplitude: o.RareSpeed.Amplitude,
+				MinPeriod: o.RareSpeed.MinDuration,
+				MaxPeriod: o.RareSpeed.MaxDuration,
+			},
+			RareSlopeChance: o.RareSpeedChance,
+			Average:         o.DesiredSpeed,
+			Rand:            rnd,
+		}
+	}
+}
+
+// SpeedProfile returns the speed of the activity in km/h for each second during which
+// it was moving, which can be replayed by other activities using ReplayGenerator.
+// This should be used only after fully constructing the activity.
+func (a *Activity) SpeedProfile() []float64 {
+	var profile []float64
+	for i := 1; i < len(a.records); i++ {
+		prev, record := &a.records[i-1], &a.records[i]
+		// stops and the time spent stationary aren't replayed
+		duration := record.Timestamp.Sub(prev.Timestamp) - a.StoppedDuration(prev.Timestamp, record.Timestamp)
+		distance := record.Distance - prev.Distance
+		if duration <= 0 || distance <= 0 {
+			continue
+		}
+		speed := distance / duration.Hours()
+		if speed < minDesiredSpeed {
+			continue
+		}
+		// records which are more than a second apart repeat the speed for each second
+		for seconds := int(math.Max(1, math.Round(duration.Seconds()))); seconds > 0; seconds-- {
+			profile = append(profile, speed)
+		}
+	}
+	return profile
+}
+
+// ReferenceSpeedProfile builds a reference activity of the given type from a route in
+// any of the supported formats, which must contain timestamps, and returns its SpeedProfile
+func ReferenceSpeedProfile(activityType ActivityType, b []byte) ([]float64, error) {
+	reference, err := NewActivity(&ActivityOptions{
+		Type:         activityType,
+		Start:        time.Now(),
+		DesiredSpeed: minDesiredSpeed,
+		SourceTiming: &SourceTimingOptions{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := reference.BuildFromRoute(b); err != nil {
+		return nil, err
+	}
+	if !reference.timed {
+		return nil, ErrNoReferenceTimestamps
+	}
+
+	profile := reference.SpeedProfile()
+	if len(profile) == 0 {
+		return nil, ErrNoReferenceSpeed
+	}
+	return profile, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constantSpeed is a custom generator of a constant speed
+type constantSpeed float64
+
+func (speed constantSpeed) Next() float64 {
+	return float64(speed)
+}
+
+func TestSpeedGenerators(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// the long-run mean of all generators is the desired speed
+	for _, generator := range []*SpeedGeneratorOptions{
+		nil,
+		{Type: NoiseGenerator},
+		{Type: NoiseGenerator, NoiseAmplitude: 0.4, NoisePeriod: 10 * time.Second, NoiseOctaves: 8, NoisePersistence: 1},
+		{Type: MeanRevertingGenerator},
+		{Type: ReplayGenerator, Reference: []float64{8, 9, 10, 11, 14}},
+	} {
+		activity := newActivity(r, &ActivityOptions{SpeedGenerator: generator})
+		var sum, min, max float64
+		min = activity.desiredSpeed
+		const seconds = 200000
+		for i := 0; i < seconds; i++ {
+			speed := activity.speed.Next()
+			sum += speed
+			if speed < min {
+				min = speed
+			} else if speed > max {
+				max = speed
+			}
+		}
+		a.InDelta(10, sum/seconds, 0.1)
+		a.Greater(min, 0.0)
+		a.Greater(max, 10.0)
+		if generator != nil && generator.Type == NoiseGenerator {
+			// the noise never exceeds its amplitude
+			a.LessOrEqual(max, 10*(1+generator.NoiseAmplitude))
+			a.GreaterOrEqual(min, 10*(1-generator.NoiseAmplitude))
+		}
+	}
+
+	// the speed profile of a timed activity is replayed by other activities
+	reference := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(reference.BuildFromGPX([]byte(testTimedGPX)))
+	profile := reference.SpeedProfile()
+	r.NotEmpty(profile)
+	a.InDelta(reference.TotalDuration().Seconds(), float64(len(profile)), 2)
+	referenceProfile, err := ReferenceSpeedProfile(RunActivity, []byte(testTimedGPX))
+	r.NoError(err)
+	a.Equal(profile, referenceProfile)
+	activity := newActivity(r, &ActivityOptions{SpeedGenerator: &SpeedGeneratorOptions{Type: ReplayGenerator, Reference: profile}})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	a.InDelta(10, averageSpeed(activity, 0.5, 5), 1)
+
+	// and custom generators are created for the desired speed
+	var created []float64
+	newConstantSpeed := func(desiredSpeed float64) SpeedGenerator {
+		created = append(created, desiredSpeed)
+		return constantSpeed(desiredSpeed)
+	}
+	activity = newActivity(r, &ActivityOptions{DesiredSpeed: 12, SpeedGenerator: &SpeedGeneratorOptions{NewGenerator: newConstantSpeed}})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	a.Equal([]float64{12}, created)
+	a.InDelta(12, averageSpeed(activity, 0.5, 5), 0.1)
+
+	// Error tests
+	for _, generator := range []*SpeedGeneratorOptions{
+		{Type: "brownian"},
+		{Type: NoiseGenerator, NoiseAmplitude: -0.1},
+		{Type: NoiseGenerator, NoiseAmplitude: 0.6},
+		{Type: NoiseGenerator, NoisePeriod: time.Second},
+		{Type: NoiseGenerator, NoiseOctaves: 9},
+		{Type: NoiseGenerator, NoisePersistence: 2},
+		{Type: MeanRevertingGenerator, Volatility: -1},
+		{Type: MeanRevertingGenerator, ReversionTime: time.Millisecond},
+		{Type: MeanRevertingGenerator, Volatility: 0.2, ReversionTime: time.Minute},
+		{Type: ReplayGenerator},
+		{Type: ReplayGenerator, Reference: []float64{10, 0}},
+	} {
+		_, err := NewActivity(&ActivityOptions{
+			Type:           RunActivity,
+			Start:          time.Now(),
+			DesiredSpeed:   10,
+			SpeedGenerator: generator,
+		})
+		a.Error(err)
+	}
+	_, err = ReferenceSpeedProfile(RunActivity, []byte(testSegmentsGPX))
+	a.ErrorIs(err, ErrNoReferenceTimestamps)
+}
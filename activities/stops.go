@@ -0,0 +1,222 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// TrafficLight is the position of a traffic light on the route
+type TrafficLight struct {
+	Lat float64
+	Lon float64
+}
+
+// StopOptions configures the stops made during the activity, during which the
+// timestamps move on while the position stays the same. The stops are made after
+// generating the speed, so the average moving speed is still DesiredSpeed. Stops are made at the
+// waypoints of imported routes, at random, and at traffic lights, which are red
+// only some of the time. Stops are never made at the first and the last record,
+// and random stops aren't made during the fade-in and fade-out.
+type StopOptions struct {
+	// Duration of the stops at the named waypoints of imported routes, no stops are made at them if 0
+	WaypointDuration time.Duration
+	// Average number of random stops per hour of moving
+	RandomPerHour float64
+	// Bounds of the duration of random stops
+	RandomMinDuration time.Duration
+	RandomMaxDuration time.Duration
+	// Positions of the traffic lights on the route
+	TrafficLights []TrafficLight
+	// Average distance in km between the traffic lights placed along the route
+	// in addition to TrafficLights, no traffic lights are placed if 0
+	TrafficLightDistance float64
+	// The chance of a traffic light being red
+	RedLightChance float64
+	// Maximum duration of waiting at a red light
+	RedLightDuration time.Duration
+}
+
+// Stop is a single stop made during the activity
+type Stop struct {
+	Start    time.Time
+	Duration time.Duration
+}
+
+// End returns the time at which the activity continues after the stop
+func (s *Stop) End() time.Time {
+	return s.Start.Add(s.Duration)
+}
+
+const (
+	maxRandomStopsPerHour = 60
+	// placed traffic lights are between a half and one and a half of the average distance apart
+	trafficLightSpread = 0.5
+)
+
+var (
+	DefaultRandomStopMinDuration = 10 * time.Second
+	DefaultRandomStopMaxDuration = 60 * time.Second
+	DefaultRedLightChance        = 0.5
+	DefaultRedLightDuration      = 60 * time.Second
+)
+
+func (o *StopOptions) validateAndSetDefaults() error {
+	if o.WaypointDuration < 0 || o.RandomPerHour < 0 || o.RandomMinDuration < 0 || o.RandomMaxDuration < 0 ||
+		o.TrafficLightDistance < 0 || o.RedLightChance < 0 || o.RedLightDuration < 0 {
+		return errors.New("stop options cannot be negative")
+	}
+	if o.RandomPerHour > maxRandomStopsPerHour {
+		return errors.New("too many random stops (over 60 per hour)")
+	}
+	if o.RedLightChance > 1 {
+		return errors.New("red light chance should be in range (0, 1)")
+	}
+	for _, light := range o.TrafficLights {
+		if light.Lat < -90 || light.Lat > 90 {
+			return ErrInvalidLatitude
+		} else if light.Lon < -180 || light.Lon > 180 {
+			return ErrInvalidLongitude
+		}
+	}
+
+	if o.RandomMinDuration == 0 {
+		o.RandomMinDuration = DefaultRandomStopMinDuration
+	}
+	if o.RandomMaxDuration == 0 {
+		o.RandomMaxDuration = DefaultRandomStopMaxDuration
+	}
+	if o.RandomMinDuration > o.RandomMaxDuration {
+		return errors.New("random stop min duration cannot be higher than the max duration")
+	}
+	if o.RedLightChance == 0 {
+		o.RedLightChance = DefaultRedLightChance
+	}
+	if o.RedLightDuration == 0 {
+		o.RedLightDuration = DefaultRedLightDuration
+	}
+	return nil
+}
+
+// randomDuration returns a duration in the range (min, max) rounded to seconds
+func (a *Activity) randomDuration(min, max time.Duration) time.Duration {
+	return time.Second * time.Duration(a.rand.IntInRange(int(min/time.Second), int(max/time.Second)))
+}
+
+// trafficLightRecords returns the indices of the records closest to the traffic lights
+func (a *Activity) trafficLightRecords() []int {
+	o := a.stopOptions
+	var lights []int
+	for _, light := range o.TrafficLights {
+		if closest := a.closestRecord(light.Lat, light.Lon); closest >= 0 {
+			lights = append(lights, closest)
+		}
+	}
+
+	if o.TrafficLightDistance > 0 {
+		next := o.TrafficLightDistance * a.rand.Float64InRange(1-trafficLightSpread, 1+trafficLightSpread)
+		for i := range a.records {
+			if a.records[i].Distance >= next {
+				lights = append(lights, i)
+				next += o.TrafficLightDistance * a.rand.Float64InRange(1-trafficLightSpread, 1+trafficLightSpread)
+			}
+		}
+	}
+	return lights
+}
+
+// addStops adds the configured stops to the built records, including the stops
+// at the records closest to the waypoints. Multiple stops at the same record
+// are merged into the longest one, and the following records are shifted in time.
+func (a *Activity) addStops(waypointRecords []int) {
+	o := a.stopOptions
+	if o == nil || len(a.records) < 3 {
+		return
+	}
+
+	durations := make(map[int]time.Duration)
+	addStop := func(i int, duration time.Duration) {
+		if i <= 0 || i >= len(a.records)-1 || duration <= 0 {
+			return
+		}
+		if duration > durations[i] {
+			durations[i] = duration
+		}
+	}
+
+	for _, i := range waypointRecords {
+		addStop(i, o.WaypointDuration)
+	}
+	for _, i := range a.trafficLightRecords() {
+		if a.rand.Float64InRange(0, 1) < o.RedLightChance {
+			addStop(i, a.randomDuration(time.Second, o.RedLightDuration))
+		}
+	}
+	if o.RandomPerHour > 0 {
+		fadeOutStart := a.TotalDuration() - a.fadeOutDuration
+		for i := 1; i < len(a.records); i++ {
+			elapsed := a.records[i].Timestamp.Sub(a.startTime)
+			if elapsed < a.fadeInDuration || elapsed > fadeOutStart {
+				continue
+			}
+			chance := o.RandomPerHour * a.records[i].Timestamp.Sub(a.records[i-1].Timestamp).Hours()
+			if a.rand.Float64InRange(0, 1) < chance {
+				addStop(i, a.randomDuration(o.RandomMinDuration, o.RandomMaxDuration))
+			}
+		}
+	}
+
+	indices := make([]int, 0, len(durations))
+	for i := range durations {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	var shift time.Duration
+	next := 0
+	for i := range a.records {
+		a.records[i].Timestamp = a.records[i].Timestamp.Add(shift)
+		if next < len(indices) && indices[next] == i {
+			// the record at which the stop is made is stationary
+			a.records[i].Speed = 0
+			a.stops = append(a.stops, Stop{Start: a.records[i].Timestamp, Duration: durations[i]})
+			shift += durations[i]
+			next++
+		}
+	}
+}
+
+// Stops returns the stops made during the activity ordered by time
+func (a *Activity) Stops() []Stop {
+	return a.stops
+}
+
+// AutoPause returns whether the stops of the activity should be marked as auto-paused
+func (a *Activity) AutoPause() bool {
+	return a.autoPause
+}
+
+// StoppedDuration returns the time spent stopped between from and to
+func (a *Activity) StoppedDuration(from, to time.Time) time.Duration {
+	var stopped time.Duration
+	for i := range a.stops {
+		start, end := a.stops[i].Start, a.stops[i].End()
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			stopped += end.Sub(start)
+		}
+	}
+	return stopped
+}
+
+// MovingDuration returns the duration of the activity without the stops
+func (a *Activity) MovingDuration() time.Duration {
+	return a.TotalDuration() - a.StoppedDuration(a.startTime, a.startTime.Add(a.TotalDuration()))
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/renbou/jogmock/activities/internal/gpx"
+	"github.com/renbou/jogmock/activities/internal/tcx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStops(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// a stop is made at the named waypoint in the middle of the route
+	activity := newActivity(r, &ActivityOptions{
+		HeartRate: &HeartRateOptions{},
+		Stops:     &StopOptions{WaypointDuration: 2 * time.Minute},
+		AutoPause: true,
+	})
+	r.NoError(activity.BuildFromGPX([]byte(testSegmentsGPX)))
+	r.Len(activity.Stops(), 1)
+	stop := activity.Stops()[0]
+	a.Equal(2*time.Minute, stop.Duration)
+	a.True(activity.AutoPause())
+	a.Equal(activity.TotalDuration()-2*time.Minute, activity.MovingDuration())
+	a.Equal(time.Minute, activity.StoppedDuration(stop.Start.Add(-time.Minute), stop.Start.Add(time.Minute)))
+
+	// the records after the stop are shifted, and the heart rate recovers during it
+	records := activity.Records()
+	for i := 1; i < len(records); i++ {
+		if records[i-1].Timestamp.Equal(stop.Start) {
+			a.InDelta(1.1, records[i-1].Distance, 0.02)
+			a.Zero(records[i-1].Speed)
+			a.Equal(stop.End(), records[i].Timestamp.Add(-time.Second))
+			a.Less(records[i].HeartRate, records[i-1].HeartRate)
+		} else {
+			a.LessOrEqual(records[i].Timestamp.Sub(records[i-1].Timestamp), time.Second)
+		}
+	}
+
+	// the stops are seen as pauses in the exported files
+	b, err := activity.MarshalGPX()
+	r.NoError(err)
+	gpxFile, err := gpx.UnmarshalGPX(b)
+	r.NoError(err)
+	// the waypoint also begins the second lap, which is exported as a separate track
+	r.Len(gpxFile.Tracks, 2)
+	r.Len(gpxFile.Tracks[1].Segments, 2)
+	a.Equal(stop.End().UTC(), *gpxFile.Tracks[1].Segments[1].Points[0].Time)
+	b, err = activity.MarshalTCX()
+	r.NoError(err)
+	tcxFile, err := tcx.UnmarshalTCX(b)
+	r.NoError(err)
+	lapTime := 0.0
+	for _, lap := range tcxFile.Activities[0].Laps {
+		lapTime += lap.TotalTimeSeconds
+	}
+	a.InDelta(activity.MovingDuration().Seconds(), lapTime, 1)
+
+	// random stops and red lights
+	activity = newActivity(r, &ActivityOptions{
+		HeartRate: &HeartRateOptions{},
+		Stops: &StopOptions{
+			RandomPerHour:        60,
+			TrafficLights:        []TrafficLight{{Lat: 59.93, Lon: 30.3}},
+			TrafficLightDistance: 0.5,
+			RedLightChance:       1,
+			RedLightDuration:     30 * time.Second,
+		},
+		AutoPause: true,
+	})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	// there are about 10 traffic lights on the 5.5 km route along with the random stops
+	a.GreaterOrEqual(len(activity.Stops()), 5)
+	for i, stop := range activity.Stops() {
+		a.Greater(stop.Duration, time.Duration(0))
+		a.LessOrEqual(stop.Duration, DefaultRandomStopMaxDuration)
+		if i > 0 {
+			a.True(stop.Start.After(activity.Stops()[i-1].End()))
+		}
+	}
+	var stopped time.Duration
+	for _, stop := range activity.Stops() {
+		stopped += stop.Duration
+	}
+	a.Equal(activity.TotalDuration()-stopped, activity.MovingDuration())
+
+	// no stops are made without the options
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromGPX([]byte(testSegmentsGPX)))
+	a.Empty(activity.Stops())
+	a.Equal(activity.TotalDuration(), activity.MovingDuration())
+
+	// Error tests
+	for _, stops := range []*StopOptions{
+		{WaypointDuration: -time.Second},
+		{RandomPerHour: 100},
+		{RedLightChance: 2},
+		{RandomMinDuration: time.Minute, RandomMaxDuration: time.Second},
+		{TrafficLights: []TrafficLight{{Lat: 100}}},
+	} {
+		_, err := NewActivity(&ActivityOptions{
+			Type:         RunActivity,
+			Start:        time.Now(),
+			DesiredSpeed: 10,
+			Stops:        stops,
+		})
+		a.Error(err)
+	}
+}
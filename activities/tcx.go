@@ -0,0 +1,187 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import "github.com/renbou/jogmock/activities/internal/tcx"
+
+// TCXOptions configures how the contents of a TCX file are used to build an activity
+type TCXOptions struct {
+	// LapLaps adds a lap marker at the beginning of each lap of the
+	// activity or each course after the first one, otherwise they are simply joined
+	LapLaps bool
+	// CoursePointLaps adds a lap marker at the point of the activity closest to each
+	// named course point, as long as the point lies within 200 m of the activity
+	CoursePointLaps bool
+}
+
+// DefaultTCXOptions keeps the laps of the activity and uses named course points as lap markers
+var DefaultTCXOptions = TCXOptions{
+	LapLaps:         true,
+	CoursePointLaps: true,
+}
+
+// BuildFromTCX builds the activity from a TCX file using DefaultTCXOptions
+func (a *Activity) BuildFromTCX(b []byte) error {
+	return a.BuildFromTCXWithOptions(b, &DefaultTCXOptions)
+}
+
+// BuildFromTCXWithOptions builds the activity from all of the laps of the activities
+// in a TCX file, or its courses if it doesn't contain any activities, and finalizes it.
+// The heart rate and cadence of the trackpoints are kept, the timestamps are used
+// if ActivityOptions.SourceTiming was set. Trackpoints without a position are skipped.
+func (a *Activity) BuildFromTCXWithOptions(b []byte, options *TCXOptions) error {
+	tcxFile, err := tcx.UnmarshalTCX(b)
+	if err != nil {
+		return err
+	}
+	segments, err := tcxFile.Segments()
+	if err != nil {
+		return err
+	}
+
+	if a.name == "" {
+		a.name = tcxFile.Name()
+	}
+	if a.description == "" {
+		a.description = tcxFile.Description()
+	}
+
+	pointSegments := make([]pointSegment, len(segments))
+	for i, segment := range segments {
+		pointSegments[i].name = segment.Name
+		for _, trackpoint := range segment.Trackpoints {
+			record := Record{
+				Lat:       trackpoint.Position.LatitudeDegrees,
+				Lon:       trackpoint.Position.LongitudeDegrees,
+				Altitude:  trackpoint.AltitudeMeters,
+				HeartRate: trackpoint.HeartRate(),
+				Cadence:   trackpoint.CadenceValue(),
+			}
+			if trackpoint.Time != nil {
+				record.Timestamp = *trackpoint.Time
+			}
+			pointSegments[i].points = append(pointSegments[i].points, record)
+		}
+	}
+	var waypoints []waypoint
+	for _, course := range tcxFile.Courses {
+		for _, point := range course.CoursePoints {
+			if point.Name != "" {
+				waypoints = append(waypoints, waypoint{
+					name: point.Name,
+					lat:  point.Position.LatitudeDegrees,
+					lon:  point.Position.LongitudeDegrees,
+				})
+			}
+		}
+	}
+	return a.build(&importedRoute{
+		segments:     pointSegments,
+		waypoints:    waypoints,
+		segmentLaps:  options.LapLaps,
+		waypointLaps: options.CoursePointLaps,
+	})
+}
+
+// MarshalTCX exports the built activity as a TCX file with a single activity, which
+// contains a lap for each lap marker of the activity along with the lap totals
+func (a *Activity) MarshalTCX() ([]byte, error) {
+	if len(a.records) == 0 {
+		return nil, ErrNoRecords
+	}
+
+	sport := tcx.SportOther
+	switch a.activityType {
+	case RunActivity:
+		sport = tcx.SportRunning
+	case RideActivity:
+		sport = tcx.SportBiking
+	}
+	activity := tcx.Activity{
+		Sport: sport,
+		Id:    a.startTime.UTC(),
+		Notes: a.description,
+	}
+
+	laps := a.Laps()
+	for i := range laps {
+		activity.Laps = append(activity.Laps, a.tcxLap(&laps[i]))
+	}
+
+	return tcx.MarshalTCX(&tcx.TrainingCenterDatabase{
+		Activities: []tcx.Activity{activity},
+	})
+}
+
+// tcxLap builds the TCX lap containing the records of the lap along with its totals.
+// The total time of the lap doesn't include the stops made during the lap.
+func (a *Activity) tcxLap(lap *Lap) tcx.Lap {
+	trigger := tcx.TriggerManual
+	switch lap.Trigger {
+	case DistanceLapTrigger:
+		trigger = tcx.TriggerDistance
+	case PositionLapTrigger:
+		trigger = tcx.TriggerLocation
+	}
+
+	// speed is stored in km/h
+	maxSpeed := lap.MaxSpeed / 3.6
+	tcxLap := tcx.Lap{
+		StartTime:        lap.Start.UTC(),
+		TotalTimeSeconds: lap.MovingDuration.Seconds(),
+		DistanceMeters:   lap.Distance * 1000,
+		MaximumSpeed:     &maxSpeed,
+		Intensity:        tcx.IntensityActive,
+		TriggerMethod:    trigger,
+	}
+	if lap.AverageHeartRate != 0 {
+		tcxLap.AverageHeartRateBpm = &tcx.HeartRate{Value: lap.AverageHeartRate}
+		tcxLap.MaximumHeartRateBpm = &tcx.HeartRate{Value: lap.MaxHeartRate}
+	}
+	if lap.AverageCadence != 0 && a.activityType == RideActivity {
+		cadence := lap.AverageCadence
+		tcxLap.Cadence = &cadence
+	}
+
+	track := tcx.Track{Trackpoints: make([]tcx.Trackpoint, 0, lap.last-lap.first)}
+	for i := lap.first; i < lap.last; i++ {
+		track.Trackpoints = append(track.Trackpoints, a.tcxTrackpoint(&a.records[i]))
+	}
+	tcxLap.Tracks = []tcx.Track{track}
+	return tcxLap
+}
+
+func (a *Activity) tcxTrackpoint(record *Record) tcx.Trackpoint {
+	timestamp := record.Timestamp.UTC()
+	distance := record.Distance * 1000
+	trackpoint := tcx.Trackpoint{
+		Time: &timestamp,
+		Position: &tcx.Position{
+			LatitudeDegrees:  record.Lat,
+			LongitudeDegrees: record.Lon,
+		},
+		AltitudeMeters: record.Altitude,
+		DistanceMeters: &distance,
+	}
+	if record.HeartRate != 0 {
+		trackpoint.HeartRateBpm = &tcx.HeartRate{Value: record.HeartRate}
+	}
+	// the trackpoint cadence is only valid for rides, runs use the extension
+	var extension tcx.TPX
+	if record.Cadence != 0 {
+		cadence := record.Cadence
+		if a.activityType == RideActivity {
+			trackpoint.Cadence = &cadence
+		} else {
+			extension.RunCadence = &cadence
+		}
+	}
+	if record.Power != 0 {
+		power := record.Power
+		extension.Watts = &power
+	}
+	if extension.RunCadence != nil || extension.Watts != nil {
+		trackpoint.Extensions = &tcx.TrackpointExtension{TPX: &extension}
+	}
+	return trackpoint
+}
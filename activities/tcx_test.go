@@ -0,0 +1,119 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+
+	"github.com/renbou/jogmock/activities/internal/tcx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// two laps of about 556 m each, heart rate is only known during the first one
+const testTimedTCX = `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+<Activities><Activity Sport="Running"><Id>2022-04-01T06:30:00Z</Id>
+  <Lap StartTime="2022-04-01T06:30:00Z"><Track>
+    <Trackpoint><Time>2022-04-01T06:30:00Z</Time>
+      <Position><LatitudeDegrees>59.9234</LatitudeDegrees><LongitudeDegrees>30.3052</LongitudeDegrees></Position>
+      <AltitudeMeters>20</AltitudeMeters><HeartRateBpm><Value>100</Value></HeartRateBpm></Trackpoint>
+    <Trackpoint><Time>2022-04-01T06:33:20Z</Time>
+      <Position><LatitudeDegrees>59.9284</LatitudeDegrees><LongitudeDegrees>30.3052</LongitudeDegrees></Position>
+      <AltitudeMeters>30</AltitudeMeters><HeartRateBpm><Value>140</Value></HeartRateBpm></Trackpoint>
+  </Track></Lap>
+  <Lap StartTime="2022-04-01T06:33:20Z"><Track>
+    <Trackpoint><Time>2022-04-01T06:36:40Z</Time>
+      <Position><LatitudeDegrees>59.9334</LatitudeDegrees><LongitudeDegrees>30.3052</LongitudeDegrees></Position>
+      <AltitudeMeters>10</AltitudeMeters><Cadence>85</Cadence></Trackpoint>
+  </Track></Lap>
+  <Notes>Intervals</Notes>
+</Activity></Activities>
+</TrainingCenterDatabase>`
+
+func TestBuildFromTCX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	a.Equal("Intervals", activity.Description())
+	a.InDelta(1.113, activity.TotalDistance(), 0.001)
+
+	markers := activity.LapMarkers()
+	r.Len(markers, 1)
+	a.InDelta(0.5565, markers[0].Distance, 0.001)
+
+	// heart rate is interpolated only when it's known on both sides
+	records := activity.Records()
+	r.Len(records, 401)
+	a.Equal(uint8(100), records[0].HeartRate)
+	a.Equal(uint8(120), records[100].HeartRate)
+	a.Equal(uint8(0), records[300].HeartRate)
+	a.Equal(uint8(85), records[400].Cadence)
+
+	// without timing the imported values are kept at the points
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromTCXWithOptions([]byte(testTimedTCX), &TCXOptions{}))
+	a.Empty(activity.LapMarkers())
+	records = activity.Records()
+	a.Equal(uint8(100), records[0].HeartRate)
+	a.Equal(uint8(85), records[len(records)-1].Cadence)
+
+	// Error tests
+	a.Error(newActivity(r, nil).BuildFromTCX([]byte(`<TrainingCenterDatabase/>`)))
+	a.Error(newActivity(r, nil).BuildFromTCX([]byte(`<gpx`)))
+}
+
+func TestMarshalTCX(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromTCX([]byte(testTimedTCX)))
+	b, err := activity.MarshalTCX()
+	r.NoError(err)
+
+	file, err := tcx.UnmarshalTCX(b)
+	r.NoError(err)
+	r.Len(file.Activities, 1)
+	exported := file.Activities[0]
+	a.Equal(tcx.SportRunning, exported.Sport)
+	a.Equal(activity.Start(), exported.Id)
+	a.Equal("Intervals", exported.Notes)
+
+	// a lap for each lap marker, with the totals adding up to the activity totals
+	r.Len(exported.Laps, 2)
+	a.Equal(200.0, exported.Laps[0].TotalTimeSeconds)
+	a.Equal(200.0, exported.Laps[1].TotalTimeSeconds)
+	a.InDelta(556, exported.Laps[0].DistanceMeters, 1)
+	a.InDelta(activity.TotalDistance()*1000,
+		exported.Laps[0].DistanceMeters+exported.Laps[1].DistanceMeters, 0.001)
+	r.NotNil(exported.Laps[0].MaximumSpeed)
+	a.InDelta(0.556/200*1000, *exported.Laps[0].MaximumSpeed, 0.01)
+	r.NotNil(exported.Laps[0].AverageHeartRateBpm)
+	a.Equal(uint8(120), exported.Laps[0].AverageHeartRateBpm.Value)
+	a.Equal(uint8(140), exported.Laps[0].MaximumHeartRateBpm.Value)
+	// only the first record of the second lap lies at the point with a known heart rate
+	r.NotNil(exported.Laps[1].AverageHeartRateBpm)
+	a.Equal(uint8(140), exported.Laps[1].AverageHeartRateBpm.Value)
+
+	// every record is exported exactly once
+	trackpoints := 0
+	for _, lap := range exported.Laps {
+		for _, track := range lap.Tracks {
+			trackpoints += len(track.Trackpoints)
+		}
+	}
+	a.Equal(len(activity.Records()), trackpoints)
+
+	// the exported file can be imported again
+	imported := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(imported.BuildFromTCX(b))
+	a.Equal(activity.TotalDuration(), imported.TotalDuration())
+	a.InDelta(activity.TotalDistance(), imported.TotalDistance(), 0.001)
+	a.Len(imported.LapMarkers(), 1)
+
+	// Error tests
+	_, err = newActivity(r, nil).MarshalTCX()
+	a.ErrorIs(err, ErrNoRecords)
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrMissingTimestamps    = errors.New("only some of the points contain timestamps")
+	ErrDecreasingTimestamps = errors.New("point timestamps must not decrease")
+	ErrZeroDuration         = errors.New("unable to scale the timing of points which all have the same timestamp")
+)
+
+// SourceTimingOptions configures how the timestamps of imported points are used.
+// By default the timestamps are kept as is and the activity begins at the first one.
+type SourceTimingOptions struct {
+	// ShiftToStart shifts the timestamps so that the activity begins at ActivityOptions.Start
+	ShiftToStart bool
+	// ScaleToSpeed scales the durations between the points so that
+	// the average speed of the activity is ActivityOptions.DesiredSpeed
+	ScaleToSpeed bool
+}
+
+// hasTimestamps reports whether the activity should be built using
+// the timestamps of the points instead of generating the speed
+func (a *Activity) hasTimestamps(points []Record) (bool, error) {
+	if a.sourceTiming == nil {
+		return false, nil
+	}
+
+	timed := 0
+	for i := range points {
+		if !points[i].Timestamp.IsZero() {
+			timed++
+		}
+	}
+	if timed != 0 && timed != len(points) {
+		return false, ErrMissingTimestamps
+	}
+	return timed != 0, nil
+}
+
+// buildFromTimedPoints builds the activity records by interpolating the timed
+// points to records which are a second apart, keeping the speed between each of
+// the points. The last record is placed exactly at the time of the last point.
+func (a *Activity) buildFromTimedPoints(points []Record) error {
+	for i := range points {
+		if points[i].Lat < -90 || points[i].Lat > 90 {
+			return ErrInvalidLatitude
+		} else if points[i].Lon < -180 || points[i].Lon > 180 {
+			return ErrInvalidLongitude
+		}
+		if i > 0 && points[i].Timestamp.Before(points[i-1].Timestamp) {
+			return ErrDecreasingTimestamps
+		}
+	}
+
+	// compute the distance of each point and the timestamps after shifting and scaling
+	start := points[0].Timestamp
+	if a.sourceTiming.ShiftToStart {
+		start = a.startTime
+	}
+	distances := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		distances[i] = distances[i-1] + points[i-1].DistanceTo(&points[i])
+	}
+	scale := 1.0
+	if a.sourceTiming.ScaleToSpeed {
+		sourceDuration := points[len(points)-1].Timestamp.Sub(points[0].Timestamp)
+		if sourceDuration <= 0 {
+			return ErrZeroDuration
+		}
+		desiredDuration := time.Duration(float64(time.Hour) * distances[len(distances)-1] / a.desiredSpeed)
+		scale = float64(desiredDuration) / float64(sourceDuration)
+	}
+	timestamps := make([]time.Time, len(points))
+	for i := range points {
+		timestamps[i] = start.Add(time.Duration(float64(points[i].Timestamp.Sub(points[0].Timestamp)) * scale))
+	}
+
+	a.startTime = start
+	a.records = a.records[:0]
+	end := timestamps[len(timestamps)-1]
+	next := 0
+	for ts := start; ; ts = ts.Add(time.Second) {
+		if ts.After(end) {
+			ts = end
+		}
+		// find the pair of points with the timestamp in between them
+		for next < len(points)-1 && !timestamps[next+1].After(ts) {
+			next++
+		}
+
+		var record Record
+		if next == len(points)-1 {
+			record = points[next]
+			record.Distance = distances[next]
+			record.Speed = segmentSpeed(distances, timestamps, next-1)
+		} else {
+			fraction := 0.0
+			if segmentDuration := timestamps[next+1].Sub(timestamps[next]); segmentDuration > 0 {
+				fraction = float64(ts.Sub(timestamps[next])) / float64(segmentDuration)
+			}
+			record = RecordInBetween(&points[next], &points[next+1], fraction)
+			record.Distance = distances[next] + (distances[next+1]-distances[next])*fraction
+			record.Speed = segmentSpeed(distances, timestamps, next)
+		}
+		record.Timestamp = ts
+		a.records = append(a.records, record)
+
+		if ts.Equal(end) {
+			return nil
+		}
+	}
+}
+
+// segmentSpeed returns the speed in km/h between the i-th and the next point
+func segmentSpeed(distances []float64, timestamps []time.Time, i int) float64 {
+	if i < 0 || i+1 >= len(timestamps) {
+		return 0
+	}
+	duration := timestamps[i+1].Sub(timestamps[i])
+	if duration <= 0 {
+		return 0
+	}
+	return (distances[i+1] - distances[i]) / duration.Hours()
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// about 556 m in 200 s and then 1112 m in 300.5 s
+const testTimedGPX = `<gpx version="1.1"><trk><trkseg>
+  <trkpt lat="59.9234" lon="30.3052"><ele>20</ele><time>2022-04-01T06:30:00Z</time></trkpt>
+  <trkpt lat="59.9284" lon="30.3052"><ele>30</ele><time>2022-04-01T06:33:20Z</time></trkpt>
+  <trkpt lat="59.9384" lon="30.3052"><ele>10</ele><time>2022-04-01T06:38:20.5Z</time></trkpt>
+</trkseg></trk></gpx>`
+
+func TestSourceTiming(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	activity := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}})
+	r.NoError(activity.BuildFromGPX([]byte(testTimedGPX)))
+
+	sourceStart := time.Date(2022, time.April, 1, 6, 30, 0, 0, time.UTC)
+	a.Equal(sourceStart, activity.Start())
+	a.Equal(500500*time.Millisecond, activity.TotalDuration())
+
+	records := activity.Records()
+	r.Len(records, 502)
+	for i, record := range records[:len(records)-1] {
+		a.Equal(sourceStart.Add(time.Duration(i)*time.Second), record.Timestamp)
+	}
+	a.InDelta(0.556*3600/200, records[100].Speed, 0.1)
+	a.InDelta(1.112*3600/300.5, records[300].Speed, 0.1)
+	a.InDelta(0.278, records[100].Distance, 0.001)
+	r.NotNil(records[100].Altitude)
+	a.InDelta(25, *records[100].Altitude, 0.001)
+	a.InDelta(1.67, activity.TotalDistance(), 0.005)
+	r.NotNil(records[len(records)-1].Altitude)
+	a.Equal(10.0, *records[len(records)-1].Altitude)
+
+	// shifted and scaled to the desired speed of 10 km/h
+	activity = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{ShiftToStart: true, ScaleToSpeed: true}})
+	start := activity.Start()
+	r.NoError(activity.BuildFromGPX([]byte(testTimedGPX)))
+	a.Equal(start, activity.Start())
+	averageSpeed := activity.TotalDistance() / activity.TotalDuration().Hours()
+	a.InDelta(10, averageSpeed, 0.001)
+	// the relative speeds of the segments are kept
+	records = activity.Records()
+	a.InDelta(records[len(records)-2].Speed/records[1].Speed, (1.112/300.5)/(0.556/200), 0.01)
+
+	// without timing options the timestamps are ignored
+	activity = newActivity(r, nil)
+	r.NoError(activity.BuildFromGPX([]byte(testTimedGPX)))
+	a.NotEqual(sourceStart, activity.Start())
+}
+
+func TestSourceTimingErrors(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	err := newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}}).BuildFromGPX([]byte(`<gpx version="1.1"><trk><trkseg>
+		<trkpt lat="59.9234" lon="30.3052"><time>2022-04-01T06:30:00Z</time></trkpt>
+		<trkpt lat="59.9284" lon="30.3052"></trkpt>
+	</trkseg></trk></gpx>`))
+	a.ErrorIs(err, ErrMissingTimestamps)
+
+	err = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{}}).BuildFromGPX([]byte(`<gpx version="1.1"><trk><trkseg>
+		<trkpt lat="59.9234" lon="30.3052"><time>2022-04-01T06:30:00Z</time></trkpt>
+		<trkpt lat="59.9284" lon="30.3052"><time>2022-04-01T06:29:00Z</time></trkpt>
+	</trkseg></trk></gpx>`))
+	a.ErrorIs(err, ErrDecreasingTimestamps)
+
+	err = newActivity(r, &ActivityOptions{SourceTiming: &SourceTimingOptions{ScaleToSpeed: true}}).BuildFromGPX([]byte(`<gpx version="1.1"><trk><trkseg>
+		<trkpt lat="59.9234" lon="30.3052"><time>2022-04-01T06:30:00Z</time></trkpt>
+		<trkpt lat="59.9284" lon="30.3052"><time>2022-04-01T06:30:00Z</time></trkpt>
+	</trkseg></trk></gpx>`))
+	a.ErrorIs(err, ErrZeroDuration)
+}
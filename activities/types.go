@@ -22,3 +22,15 @@ func (t ActivityType) IsKnown() bool {
 	}
 	return true
 }
+
+// String returns the name of the activity type
+func (t ActivityType) String() string {
+	switch t {
+	case RunActivity:
+		return "Run"
+	case RideActivity:
+		return "Ride"
+	default:
+		return "Unknown"
+	}
+}
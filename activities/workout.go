@@ -0,0 +1,165 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// WorkoutIntensity is the intensity of a workout step
+type WorkoutIntensity string
+
+const (
+	ActiveIntensity   WorkoutIntensity = "active"
+	RestIntensity     WorkoutIntensity = "rest"
+	WarmupIntensity   WorkoutIntensity = "warmup"
+	CooldownIntensity WorkoutIntensity = "cooldown"
+)
+
+// WorkoutStep is a single step of the workout plan. A step either covers the distance
+// at the target speed, or repeats the nested steps the given number of times.
+type WorkoutStep struct {
+	Name string
+	// Distance of the step in km. The last step of the plan may have no distance,
+	// in which case it lasts until the end of the route
+	Distance float64
+	// Target speed of the step in km/h, DesiredSpeed by default
+	Speed float64
+	// Intensity of the step, ActiveIntensity by default
+	Intensity WorkoutIntensity
+	// Number of times the nested steps are repeated, e.g. 6 for 6×(400 m, 200 m)
+	Repeat int
+	Steps  []WorkoutStep
+}
+
+// WorkoutOptions configures the workout plan which drives the target speed over the
+// route. The generated speed is still applied within each step, and the speed smoothly
+// changes between the steps. Each of the steps begins a new lap, and the rest of
+// the route after the plan is covered at DesiredSpeed. Since the speed depends on
+// the plan, the average speed of the activity doesn't equal DesiredSpeed.
+type WorkoutOptions struct {
+	Steps []WorkoutStep
+}
+
+// workoutInterval is a single step of the flattened workout plan
+type workoutInterval struct {
+	step *WorkoutStep
+	// distances from the start of the activity in km at which the interval begins and ends
+	start float64
+	end   float64
+	// target speed in km/h
+	speed float64
+}
+
+const (
+	maxWorkoutRepeat = 100
+	// fraction by which the current workout factor approaches
+	// the factor of the current interval during each second
+	workoutSmoothing = 0.1
+)
+
+func validateWorkoutSteps(steps []WorkoutStep, last bool, desiredSpeed float64) error {
+	if len(steps) == 0 {
+		return errors.New("workout steps cannot be empty")
+	}
+	for i := range steps {
+		step := &steps[i]
+		lastStep := last && i == len(steps)-1
+		if step.Distance < 0 || step.Speed < 0 || step.Repeat < 0 {
+			return errors.New("workout step distance, speed and repeat count cannot be negative")
+		}
+		switch step.Intensity {
+		case "":
+			step.Intensity = ActiveIntensity
+		case ActiveIntensity, RestIntensity, WarmupIntensity, CooldownIntensity:
+		default:
+			return errors.New("unknown workout step intensity, only active, rest, warmup and cooldown are known")
+		}
+
+		if len(step.Steps) > 0 {
+			if step.Distance != 0 || step.Speed != 0 {
+				return errors.New("repeated workout steps cannot have their own distance or speed")
+			}
+			if step.Repeat == 0 {
+				step.Repeat = 1
+			}
+			if step.Repeat > maxWorkoutRepeat {
+				return errors.New("workout steps are repeated too many times (over 100)")
+			}
+			// an open step would never end when repeated
+			if err := validateWorkoutSteps(step.Steps, lastStep && step.Repeat == 1, desiredSpeed); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if step.Repeat != 0 {
+			return errors.New("only workout steps with nested steps can be repeated")
+		}
+		if step.Distance == 0 && !lastStep {
+			return errors.New("only the last workout step can last until the end of the route")
+		}
+		if step.Speed == 0 {
+			step.Speed = desiredSpeed
+		}
+	}
+	return nil
+}
+
+func (o *WorkoutOptions) validateAndSetDefaults(desiredSpeed float64) error {
+	return validateWorkoutSteps(o.Steps, true, desiredSpeed)
+}
+
+// flattenWorkout appends the intervals of the steps to the plan beginning
+// at the given distance, and returns the distance at which they end
+func flattenWorkout(plan []workoutInterval, steps []WorkoutStep, start float64) ([]workoutInterval, float64) {
+	for i := range steps {
+		step := &steps[i]
+		if len(step.Steps) > 0 {
+			for j := 0; j < step.Repeat; j++ {
+				plan, start = flattenWorkout(plan, step.Steps, start)
+			}
+			continue
+		}
+
+		interval := workoutInterval{step: step, start: start, end: start + step.Distance, speed: step.Speed}
+		if step.Distance == 0 {
+			interval.end = math.Inf(1)
+		}
+		plan = append(plan, interval)
+		start = interval.end
+	}
+	return plan, start
+}
+
+// Workout returns the workout plan of the activity, or nil if it wasn't set
+func (a *Activity) Workout() *WorkoutOptions {
+	return a.workout
+}
+
+// workoutFactor returns the factor by which the speed is changed
+// at the given distance to follow the target of the workout plan
+func (a *Activity) workoutFactor(distance float64) float64 {
+	i := sort.Search(len(a.workoutPlan), func(i int) bool {
+		return a.workoutPlan[i].end > distance
+	})
+	if i == len(a.workoutPlan) {
+		return 1
+	}
+	return a.workoutPlan[i].speed / a.desiredSpeed
+}
+
+// addWorkoutLapMarkers adds a lap marker at the beginning of each interval of the workout plan
+func (a *Activity) addWorkoutLapMarkers() {
+	for _, interval := range a.workoutPlan {
+		a.addLapMarker(LapMarker{Name: interval.step.Name, Distance: interval.start, Trigger: DistanceLapTrigger})
+	}
+	// the rest of the route after the plan is a separate lap as well
+	if len(a.workoutPlan) > 0 {
+		if end := a.workoutPlan[len(a.workoutPlan)-1].end; !math.IsInf(end, 1) {
+			a.addLapMarker(LapMarker{Distance: end, Trigger: DistanceLapTrigger})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 Artem Mikheev
+
+package activities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkout(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// warm-up 1 km @ 10, 3×(400 m @ 16, 200 m jog @ 8), cool-down until the end of the route
+	workout := &WorkoutOptions{Steps: []WorkoutStep{
+		{Name: "Warm-up", Distance: 1, Speed: 10, Intensity: WarmupIntensity},
+		{Repeat: 3, Steps: []WorkoutStep{
+			{Name: "Interval", Distance: 0.4, Speed: 16},
+			{Name: "Jog", Distance: 0.2, Speed: 8, Intensity: RestIntensity},
+		}},
+		{Name: "Cool-down", Intensity: CooldownIntensity},
+	}}
+	activity := newActivity(r, &ActivityOptions{Workout: workout})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	a.Equal(ActiveIntensity, workout.Steps[1].Steps[0].Intensity)
+	a.Equal(10.0, workout.Steps[2].Speed)
+
+	// each of the steps is a separate lap
+	laps := activity.Laps()
+	r.Len(laps, 8)
+	a.Equal("Warm-up", laps[0].Name)
+	a.InDelta(1, laps[0].Distance, 0.01)
+	for i := 1; i < 7; i += 2 {
+		a.Equal("Interval", laps[i].Name)
+		a.Equal("Jog", laps[i+1].Name)
+		a.InDelta(0.4, laps[i].Distance, 0.01)
+		a.InDelta(0.2, laps[i+1].Distance, 0.01)
+		a.Equal(DistanceLapTrigger, laps[i].Trigger)
+
+		// the speed smoothly changes towards the targets of the steps
+		a.InDelta(16, laps[i].AverageSpeed, 2)
+		a.InDelta(8, laps[i+1].AverageSpeed, 1.5)
+	}
+	a.Equal("Cool-down", laps[7].Name)
+	a.InDelta(activity.TotalDistance()-2.8, laps[7].Distance, 0.01)
+
+	// the rest of the route after the plan is a separate lap
+	activity = newActivity(r, &ActivityOptions{Workout: &WorkoutOptions{Steps: []WorkoutStep{{Name: "Tempo", Distance: 2, Speed: 12}}}})
+	r.NoError(activity.BuildFromRecords([]Record{{Lat: 59.9, Lon: 30.3}, {Lat: 59.95, Lon: 30.3}}))
+	laps = activity.Laps()
+	r.Len(laps, 2)
+	a.Equal("Tempo", laps[0].Name)
+	a.Equal("", laps[1].Name)
+	a.InDelta(12, laps[0].AverageSpeed, 1)
+
+	// Error tests
+	for _, workout := range []*WorkoutOptions{
+		{},
+		{Steps: []WorkoutStep{{Distance: -1}}},
+		{Steps: []WorkoutStep{{Distance: 1, Intensity: "sprint"}}},
+		{Steps: []WorkoutStep{{Speed: 10}, {Distance: 1}}},
+		{Steps: []WorkoutStep{{Distance: 1, Repeat: 2}}},
+		{Steps: []WorkoutStep{{Distance: 1, Steps: []WorkoutStep{{Distance: 1}}}}},
+		{Steps: []WorkoutStep{{Repeat: 2, Steps: []WorkoutStep{{Distance: 1}, {}}}}},
+		{Steps: []WorkoutStep{{Repeat: 1000, Steps: []WorkoutStep{{Distance: 1}}}}},
+	} {
+		_, err := NewActivity(&ActivityOptions{
+			Type:         RunActivity,
+			Start:        time.Now(),
+			DesiredSpeed: 10,
+			Workout:      workout,
+		})
+		a.Error(err)
+	}
+	_, err := NewActivity(&ActivityOptions{
+		Type:         RideActivity,
+		Start:        time.Now(),
+		DesiredSpeed: 25,
+		Power:        &PowerOptions{},
+		Workout:      &WorkoutOptions{Steps: []WorkoutStep{{Distance: 1}}},
+	})
+	a.Error(err)
+}
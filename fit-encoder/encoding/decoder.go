@@ -0,0 +1,35 @@
+// Copyright 2021 Artem Mikheev
+
+package encoding
+
+import "io"
+
+// EndianDecoder interface is a generic decodable value
+type EndianDecoder interface {
+	Decode(io.Reader, Endianness) error
+}
+
+// Decoder is a type which incapsulates decoding of multiple
+// EndianDecoder objects from a single reader with configured endianness
+type Decoder struct {
+	endianness Endianness
+	rd         io.Reader
+}
+
+func NewDecoder(rd io.Reader, endianness Endianness) *Decoder {
+	if !endianness.IsKnown() {
+		panic(ErrUnknownEndianness)
+	}
+	return &Decoder{
+		endianness: endianness,
+		rd:         rd,
+	}
+}
+
+func (dec *Decoder) String() string {
+	return "Decoder(" + dec.endianness.String() + ")"
+}
+
+func (dec *Decoder) Decode(value EndianDecoder) error {
+	return value.Decode(dec.rd, dec.endianness)
+}
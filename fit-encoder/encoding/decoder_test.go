@@ -0,0 +1,56 @@
+// Copyright 2021 Artem Mikheev
+
+package encoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DecodableInt int
+
+func (value *DecodableInt) Decode(rd io.Reader, endianness Endianness) error {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(rd, b); err != nil {
+		return err
+	}
+	switch endianness {
+	case LittleEndian:
+		*value = DecodableInt(binary.LittleEndian.Uint32(b))
+	case BigEndian:
+		*value = DecodableInt(binary.BigEndian.Uint32(b))
+	default:
+		panic(ErrUnknownEndianness)
+	}
+	return nil
+}
+
+func TestDecoder(t *testing.T) {
+	a := assert.New(t)
+
+	var value DecodableInt
+	leDecoder := NewDecoder(bytes.NewReader([]byte{0x78, 0x56, 0x34, 0x12}), LittleEndian)
+	if a.NoError(leDecoder.Decode(&value)) {
+		a.Equal(DecodableInt(305419896), value, "invalid little endian decoding")
+	}
+	a.Error(leDecoder.Decode(&value), "decoding past end of reader")
+	a.Equal(leDecoder.String(), "Decoder(LittleEndian)")
+
+	beDecoder := NewDecoder(bytes.NewReader([]byte{0x12, 0x34, 0x56, 0x78}), BigEndian)
+	if a.NoError(beDecoder.Decode(&value)) {
+		a.Equal(DecodableInt(305419896), value, "invalid big endian decoding")
+	}
+	a.Equal(beDecoder.String(), "Decoder(BigEndian)")
+}
+
+func TestDecoderUnknownEndianness(t *testing.T) {
+	a := assert.New(t)
+
+	a.PanicsWithError(ErrUnknownEndianness.Error(), func() {
+		NewDecoder(nil, Endianness(123))
+	})
+}
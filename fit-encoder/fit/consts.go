@@ -18,10 +18,51 @@ const (
 	FIT_MESG_NUM_DEV_DATA_ID types.FitUint16 = 207
 )
 
+const (
+	FIT_FIELD_NUM_TIMESTAMP types.FitUint8 = 253
+)
+
+const (
+	FIT_FIELD_NUM_DEV_DATA_ID_APPLICATION_ID      types.FitUint8 = 1
+	FIT_FIELD_NUM_DEV_DATA_ID_MANUFACTURER_ID     types.FitUint8 = 2
+	FIT_FIELD_NUM_DEV_DATA_ID_DEV_DATA_INDEX      types.FitUint8 = 3
+	FIT_FIELD_NUM_DEV_DATA_ID_APPLICATION_VERSION types.FitUint8 = 4
+)
+
+const (
+	FIT_FIELD_NUM_FIELD_DESC_DEV_DATA_INDEX   types.FitUint8 = 0
+	FIT_FIELD_NUM_FIELD_DESC_FIELD_DEF_NUM    types.FitUint8 = 1
+	FIT_FIELD_NUM_FIELD_DESC_BASE_TYPE        types.FitUint8 = 2
+	FIT_FIELD_NUM_FIELD_DESC_FIELD_NAME       types.FitUint8 = 3
+	FIT_FIELD_NUM_FIELD_DESC_ARRAY            types.FitUint8 = 4
+	FIT_FIELD_NUM_FIELD_DESC_SCALE            types.FitUint8 = 6
+	FIT_FIELD_NUM_FIELD_DESC_OFFSET           types.FitUint8 = 7
+	FIT_FIELD_NUM_FIELD_DESC_UNITS            types.FitUint8 = 8
+	FIT_FIELD_NUM_FIELD_DESC_NATIVE_MESG_NUM  types.FitUint8 = 14
+	FIT_FIELD_NUM_FIELD_DESC_NATIVE_FIELD_NUM types.FitUint8 = 15
+)
+
+const (
+	maxCompressedLocalMsgType uint8 = 3
+	compressedTimeOffsetMask  uint8 = 0x1F
+)
+
 var (
 	ErrInvalidLocalMsgType = errors.New("invalid local message type (> 15)")
 	ErrInvalidMsgType      = errors.New("invalid message type (> 1)")
 	ErrInvalidMsgSpecific  = errors.New("only definition message can have msg specific set")
 	ErrInvalidMessage      = errors.New("message is not a valid definition or data message type")
 	ErrFieldNumMismatch    = errors.New("unexpected number of fields")
+	ErrArrayLengthMismatch = errors.New("unexpected number of values in array field")
+
+	ErrInvalidHeader         = errors.New("invalid fit file header")
+	ErrHeaderCrcMismatch     = errors.New("fit file header crc mismatch")
+	ErrFileCrcMismatch       = errors.New("fit file crc mismatch")
+	ErrUndefinedLocalMsgType = errors.New("data message uses an undefined local message type")
+	ErrUnknownDevField       = errors.New("developer field used before its field description")
+	ErrEncoderClosed         = errors.New("stream encoder is already closed")
+
+	ErrInvalidCompressedLocalMsgType = errors.New("invalid local message type for compressed timestamp header (> 3)")
+	ErrTimestampFieldDefined         = errors.New("message with compressed timestamp must not define the timestamp field")
+	ErrMissingTimestamp              = errors.New("compressed timestamp used before any full timestamp")
 )
@@ -0,0 +1,395 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/renbou/jogmock/fit-encoder/internal/hash/crc16"
+)
+
+const (
+	fitHeaderSize       = 14
+	fitLegacyHeaderSize = 12
+	fitDataType         = ".FIT"
+)
+
+var architectureEndiannessMap = map[byte]encoding.Endianness{
+	0: encoding.LittleEndian,
+	1: encoding.BigEndian,
+}
+
+// fileHeader is the decoded representation of the fit file header
+type fileHeader struct {
+	size            uint8
+	protocolVersion uint8
+	profileVersion  uint16
+	dataSize        uint32
+}
+
+func decodeFileHeader(rd io.Reader) (*fileHeader, error) {
+	header := make([]byte, 1)
+	if _, err := io.ReadFull(rd, header); err != nil {
+		return nil, err
+	}
+
+	size := header[0]
+	if size != fitHeaderSize && size != fitLegacyHeaderSize {
+		return nil, fmt.Errorf("%w: unexpected header size %d", ErrInvalidHeader, size)
+	}
+
+	header = append(header, make([]byte, size-1)...)
+	if _, err := io.ReadFull(rd, header[1:]); err != nil {
+		return nil, err
+	}
+
+	if string(header[8:12]) != fitDataType {
+		return nil, fmt.Errorf("%w: missing %s data type", ErrInvalidHeader, fitDataType)
+	}
+
+	// the header crc is optional and can be set to 0 if it wasn't computed
+	if size == fitHeaderSize {
+		headerCrc := binary.LittleEndian.Uint16(header[12:14])
+		if headerCrc != 0 && headerCrc != crc16.Checksum(header[:12]) {
+			return nil, ErrHeaderCrcMismatch
+		}
+	}
+
+	return &fileHeader{
+		size:            size,
+		protocolVersion: header[1],
+		profileVersion:  binary.LittleEndian.Uint16(header[2:4]),
+		dataSize:        binary.LittleEndian.Uint32(header[4:8]),
+	}, nil
+}
+
+// decodedDefinition is a local definition message along
+// with the endianness of all data messages using it
+type decodedDefinition struct {
+	*localDefinitionMessage
+	endianness encoding.Endianness
+}
+
+type devFieldKey struct {
+	devDataIndex types.FitUint8
+	defNum       types.FitUint8
+}
+
+// decoder holds the state needed to decode the data records of a single fit file
+type decoder struct {
+	rd         io.Reader
+	localDefs  [16]*decodedDefinition
+	devIds     map[types.FitUint8]*DeveloperDataId
+	fieldDescs map[devFieldKey]*FieldDescription
+	timestamps timestampTracker
+}
+
+func newDecoder(rd io.Reader) *decoder {
+	return &decoder{
+		rd:         rd,
+		devIds:     make(map[types.FitUint8]*DeveloperDataId),
+		fieldDescs: make(map[devFieldKey]*FieldDescription),
+	}
+}
+
+func (dec *decoder) decodeMessage() (encoding.EndianEncoder, error) {
+	header := make([]byte, 1)
+	if _, err := io.ReadFull(dec.rd, header); err != nil {
+		return nil, err
+	}
+
+	if header[0]&(1<<7) != 0 {
+		return dec.decodeCompressedTimestampData(header[0])
+	}
+
+	localMsgType := header[0] & 0x0F
+	if (header[0]>>6)&1 == defMsgType {
+		return dec.decodeDefinition(localMsgType, header[0]&(1<<5) != 0)
+	}
+	return dec.decodeData(localMsgType)
+}
+
+func (dec *decoder) decodeDefinition(localMsgType uint8, hasDevFields bool) (*localDefinitionMessage, error) {
+	// reserved byte and architecture byte
+	preamble := make([]byte, 2)
+	if _, err := io.ReadFull(dec.rd, preamble); err != nil {
+		return nil, err
+	}
+	endianness, ok := architectureEndiannessMap[preamble[1]]
+	if !ok {
+		return nil, fmt.Errorf("unknown architecture %d in definition message", preamble[1])
+	}
+
+	def := new(DefinitionMessage)
+	if err := def.GlobalMsgNum.Decode(dec.rd, endianness); err != nil {
+		return nil, err
+	}
+
+	var numFields types.FitUint8
+	if err := numFields.Decode(dec.rd, endianness); err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(numFields); i++ {
+		fieldDef := new(FieldDefinition)
+		if err := fieldDef.Decode(dec.rd, endianness); err != nil {
+			return nil, err
+		}
+		def.FieldDefs = append(def.FieldDefs, fieldDef)
+	}
+
+	if hasDevFields {
+		var numDevFields types.FitUint8
+		if err := numDevFields.Decode(dec.rd, endianness); err != nil {
+			return nil, err
+		}
+		for i := 0; i < int(numDevFields); i++ {
+			devFieldDef, err := dec.decodeDevFieldDefinition(endianness)
+			if err != nil {
+				return nil, err
+			}
+			def.DevFieldDefs = append(def.DevFieldDefs, devFieldDef)
+		}
+	}
+
+	localDefMsg := def.ConstructLocalMessage(localMsgType)
+	dec.localDefs[localMsgType] = &decodedDefinition{
+		localDefinitionMessage: localDefMsg,
+		endianness:             endianness,
+	}
+	return localDefMsg, nil
+}
+
+// decodeDevFieldDefinition decodes a developer field definition
+// and ties it to a previously decoded field description
+func (dec *decoder) decodeDevFieldDefinition(endianness encoding.Endianness) (*DevFieldDefinition, error) {
+	var defNum, size, devDataIndex types.FitUint8
+	for _, value := range []*types.FitUint8{&defNum, &size, &devDataIndex} {
+		if err := value.Decode(dec.rd, endianness); err != nil {
+			return nil, err
+		}
+	}
+
+	fieldDesc, ok := dec.fieldDescs[devFieldKey{devDataIndex, defNum}]
+	if !ok {
+		return nil, fmt.Errorf("%w: developer data index %d, field %d", ErrUnknownDevField, devDataIndex, defNum)
+	}
+
+	devId, ok := dec.devIds[devDataIndex]
+	if !ok {
+		devId = &DeveloperDataId{DevDataIndex: devDataIndex}
+	}
+
+	return &DevFieldDefinition{
+		Field: fieldDesc,
+		Size:  size,
+		DevId: devId,
+	}, nil
+}
+
+// decodeCompressedTimestampData decodes a data message
+// with a compressed timestamp header
+func (dec *decoder) decodeCompressedTimestampData(header byte) (*localDataMessage, error) {
+	if !dec.timestamps.hasLast {
+		return nil, ErrMissingTimestamp
+	}
+	timestamp := dec.timestamps.expand(header & compressedTimeOffsetMask)
+
+	localDataMsg, err := dec.decodeData((header >> 5) & maxCompressedLocalMsgType)
+	if err != nil {
+		return nil, err
+	}
+	localDataMsg.compressedTimestamp = true
+	localDataMsg.timestamp = timestamp
+	dec.timestamps.update(timestamp)
+	return localDataMsg, nil
+}
+
+func (dec *decoder) decodeData(localMsgType uint8) (*localDataMessage, error) {
+	localDefMsg := dec.localDefs[localMsgType]
+	if localDefMsg == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUndefinedLocalMsgType, localMsgType)
+	}
+
+	values := make([]interface{}, 0, len(localDefMsg.def.FieldDefs)+len(localDefMsg.def.DevFieldDefs))
+	for _, fieldDef := range localDefMsg.def.FieldDefs {
+		value, err := types.DecodeValue(dec.rd, fieldDef.BaseType, fieldDef.Size, localDefMsg.endianness)
+		if err != nil {
+			return nil, fmt.Errorf("decoding field %d of message %d: %w", fieldDef.DefNum, localDefMsg.def.GlobalMsgNum, err)
+		}
+		values = append(values, value)
+	}
+	for _, devFieldDef := range localDefMsg.def.DevFieldDefs {
+		value, err := types.DecodeValue(dec.rd, devFieldDef.Field.BaseType, devFieldDef.Size, localDefMsg.endianness)
+		if err != nil {
+			return nil, fmt.Errorf("decoding developer field %d of message %d: %w",
+				devFieldDef.Field.DefNum, localDefMsg.def.GlobalMsgNum, err)
+		}
+		values = append(values, value)
+	}
+
+	localDataMsg, err := localDefMsg.constructDataImpl(values)
+	if err != nil {
+		return nil, err
+	}
+
+	if timestamp, ok := localDataMsg.Timestamp(); ok {
+		dec.timestamps.update(timestamp)
+	}
+
+	switch localDefMsg.def.GlobalMsgNum {
+	case FIT_MESG_NUM_DEV_DATA_ID:
+		dec.registerDeveloperDataId(localDataMsg)
+	case FIT_MESG_NUM_FIELD_DESC:
+		dec.registerFieldDescription(localDataMsg)
+	}
+	return localDataMsg, nil
+}
+
+// uint8Value extracts a single-byte integer from any of the single-byte fit types
+func uint8Value(value interface{}) (types.FitUint8, bool) {
+	switch v := value.(type) {
+	case types.FitUint8:
+		return v, true
+	case types.FitEnum:
+		return types.FitUint8(v), true
+	case types.FitBaseType:
+		return types.FitUint8(v), true
+	}
+	return 0, false
+}
+
+func (dec *decoder) registerDeveloperDataId(localDataMsg *localDataMessage) {
+	devDataIndex, ok := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_DEV_DATA_ID_DEV_DATA_INDEX))
+	if !ok {
+		return
+	}
+	devId := &DeveloperDataId{DevDataIndex: devDataIndex}
+	if applicationId, ok := localDataMsg.Value(FIT_FIELD_NUM_DEV_DATA_ID_APPLICATION_ID).([]types.FitByte); ok &&
+		len(applicationId) == applicationIdSize {
+		for i, v := range applicationId {
+			devId.ApplicationId[i] = byte(v)
+		}
+	}
+	if manufacturerId, ok := localDataMsg.Value(FIT_FIELD_NUM_DEV_DATA_ID_MANUFACTURER_ID).(types.FitUint16); ok &&
+		!types.FIT_TYPE_UINT16.IsInvalidValue(manufacturerId) {
+		devId.ManufacturerId = manufacturerId
+	}
+	if version, ok := localDataMsg.Value(FIT_FIELD_NUM_DEV_DATA_ID_APPLICATION_VERSION).(types.FitUint32); ok &&
+		!types.FIT_TYPE_UINT32.IsInvalidValue(version) {
+		devId.ApplicationVersion = version
+	}
+	dec.devIds[devDataIndex] = devId
+}
+
+func (dec *decoder) registerFieldDescription(localDataMsg *localDataMessage) {
+	devDataIndex, ok := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_DEV_DATA_INDEX))
+	if !ok {
+		return
+	}
+	defNum, ok := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_FIELD_DEF_NUM))
+	if !ok {
+		return
+	}
+	baseType, ok := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_BASE_TYPE))
+	if !ok {
+		return
+	}
+
+	fieldDesc := &FieldDescription{
+		DevDataIndex: devDataIndex,
+		DefNum:       defNum,
+		BaseType:     types.FitBaseType(baseType),
+	}
+	if name, ok := localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_FIELD_NAME).(types.FitString); ok {
+		fieldDesc.Name = string(name)
+	}
+	if units, ok := localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_UNITS).(types.FitString); ok {
+		fieldDesc.Units = string(units)
+	}
+	if array, ok := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_ARRAY)); ok &&
+		!types.FIT_TYPE_UINT8.IsInvalidValue(array) {
+		fieldDesc.Array = array
+	}
+	if scale, ok := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_SCALE)); ok &&
+		!types.FIT_TYPE_UINT8.IsInvalidValue(scale) {
+		fieldDesc.Scale = scale
+	}
+	if offset, ok := localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_OFFSET).(types.FitSint8); ok &&
+		!types.FIT_TYPE_SINT8.IsInvalidValue(offset) {
+		fieldDesc.Offset = offset
+	}
+	nativeMesgNum, ok1 := localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_NATIVE_MESG_NUM).(types.FitUint16)
+	nativeFieldNum, ok2 := uint8Value(localDataMsg.Value(FIT_FIELD_NUM_FIELD_DESC_NATIVE_FIELD_NUM))
+	if ok1 && ok2 && !types.FIT_TYPE_UINT16.IsInvalidValue(nativeMesgNum) &&
+		!types.FIT_TYPE_UINT8.IsInvalidValue(nativeFieldNum) {
+		fieldDesc.Native = &NativeField{MesgNum: nativeMesgNum, FieldNum: nativeFieldNum}
+	}
+	dec.fieldDescs[devFieldKey{devDataIndex, defNum}] = fieldDesc
+}
+
+// Decode reads a single fit file from rd, validating the header
+// and file crc, and returns all of the definition and data messages
+// in the same order as they were encoded
+func Decode(rd io.Reader) (*FitFile, error) {
+	// the file crc is computed over the header as well, which, for headers with
+	// a crc, is equal to computing it only over the data records
+	fileCrc := crc16.New()
+	crcReader := io.TeeReader(rd, fileCrc)
+
+	header, err := decodeFileHeader(crcReader)
+	if err != nil {
+		return nil, err
+	}
+
+	dataReader := &io.LimitedReader{R: crcReader, N: int64(header.dataSize)}
+	dec := newDecoder(dataReader)
+	file := &FitFile{
+		Header: HeaderOptions{
+			ProtocolVersion: header.protocolVersion,
+			ProfileVersion:  header.profileVersion,
+			Legacy:          header.size == fitLegacyHeaderSize,
+		},
+	}
+	for dataReader.N > 0 {
+		message, err := dec.decodeMessage()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		file.messages = append(file.messages, message)
+	}
+
+	expectedCrc := fileCrc.Sum(nil)
+	actualCrc := make([]byte, 2)
+	if _, err := io.ReadFull(rd, actualCrc); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(expectedCrc, actualCrc) {
+		return nil, ErrFileCrcMismatch
+	}
+	return file, nil
+}
+
+// DecodeChained reads a chained fit stream consisting of one or
+// more fit files from rd until EOF, decoding each file using Decode
+func DecodeChained(rd io.Reader) ([]*FitFile, error) {
+	var files []*FitFile
+	for {
+		file, err := Decode(rd)
+		// EOF is only returned if no bytes of the next header were read
+		if err == io.EOF && len(files) > 0 {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fit file %d in chain: %w", len(files), err)
+		}
+		files = append(files, file)
+	}
+}
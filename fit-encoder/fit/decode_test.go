@@ -0,0 +1,159 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/renbou/jogmock/fit-encoder/internal/hash/crc16"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitFileDecoding(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	fitFileBytes, _ := hex.DecodeString(
+		"0e2054081c0000002e464954b8884000010000040102840202840001000404860001090066043c0e2f3ede5f",
+	)
+	fitFile, err := Decode(bytes.NewReader(fitFileBytes))
+	r.NoError(err, "decoding of valid fit file")
+
+	dataMessages := fitFile.DataMessages()
+	r.Len(dataMessages, 1)
+	a.Equal(FIT_MESG_NUM_FILE_ID, dataMessages[0].Definition().GlobalMsgNum)
+	a.Equal(uint8(0), dataMessages[0].LocalMsgType())
+	a.Equal(types.FitUint16(265), dataMessages[0].Value(1))
+	a.Equal(types.FitUint16(102), dataMessages[0].Value(2))
+	a.Equal(types.FitEnum(4), dataMessages[0].Value(0))
+	a.Equal(types.FitUint32(1007562558), dataMessages[0].Value(4))
+	a.Nil(dataMessages[0].Value(3))
+
+	// decoded files should be encodable back into the same bytes
+	encodeAndValidate(a, fitFile, encoding.BigEndian, true, fitFileBytes)
+
+	// legacy 12-byte header without the header crc
+	legacyFitFileBytes, _ := hex.DecodeString(
+		"0c2054081c0000002e4649544000010000040102840202840001000404860001090066043c0e2f3e",
+	)
+	fileCrc := make([]byte, 2)
+	binary.LittleEndian.PutUint16(fileCrc, crc16.Checksum(legacyFitFileBytes))
+	legacyFitFileBytes = append(legacyFitFileBytes, fileCrc...)
+	fitFile, err = Decode(bytes.NewReader(legacyFitFileBytes))
+	r.NoError(err, "decoding of valid legacy fit file")
+	a.Len(fitFile.DataMessages(), 1)
+	a.Equal(HeaderOptions{ProtocolVersion: 0x20, ProfileVersion: 0x0854, Legacy: true}, fitFile.Header)
+	encodeAndValidate(a, fitFile, encoding.BigEndian, true, legacyFitFileBytes)
+
+	// fields with a size which isn't a multiple of the base type size are read as bytes
+	oddFitFileBytes, _ := hex.DecodeString(
+		"0c2054081f0000002e464954400001000004010284020384000100040686000109006600043c0e2f3e0102",
+	)
+	binary.LittleEndian.PutUint16(fileCrc, crc16.Checksum(oddFitFileBytes))
+	oddFitFileBytes = append(oddFitFileBytes, fileCrc...)
+	fitFile, err = Decode(bytes.NewReader(oddFitFileBytes))
+	r.NoError(err, "decoding of fit file with fields of odd size")
+	r.Len(fitFile.DataMessages(), 1)
+	a.Equal([]types.FitByte{0x00, 0x66, 0x00}, fitFile.DataMessages()[0].Value(2))
+	a.Equal([]types.FitByte{0x3c, 0x0e, 0x2f, 0x3e, 0x01, 0x02}, fitFile.DataMessages()[0].Value(4))
+	// and are encoded back as is
+	encodeAndValidate(a, fitFile, encoding.BigEndian, true, oddFitFileBytes)
+
+	// values which can't be encoded fail the whole file instead of being skipped
+	for _, field := range fitFile.DataMessages()[0].fields {
+		if field.Def.DefNum == 2 {
+			field.Value = types.FitUint16(102)
+		}
+	}
+	encodeAndValidate(a, fitFile, encoding.BigEndian, false, nil)
+}
+
+func TestRealFitFileDecoding(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	fitFileBytes, err := os.ReadFile("../../research/uploaded-activity-real.fit")
+	r.NoError(err)
+
+	fitFile, err := Decode(bytes.NewReader(fitFileBytes))
+	r.NoError(err, "decoding of real fit file")
+
+	dataMessages := fitFile.DataMessages()
+	r.Len(dataMessages, 61)
+	r.Len(fitFile.messages, 61+15)
+
+	// device info with developer fields
+	deviceInfo := dataMessages[9]
+	a.Equal(FIT_MESG_NUM_DEVICE_INFO, deviceInfo.Definition().GlobalMsgNum)
+	r.Len(deviceInfo.DevFields(), 4)
+	a.Equal(types.FitString("247.10 (1223782)"), deviceInfo.DevFields()[0].Value)
+	a.Equal(types.FitString("Xiaomi"), deviceInfo.DevFields()[1].Value)
+	a.Equal(types.FitString("Redmi Note 9 Pro"), deviceInfo.DevFields()[2].Value)
+	a.Equal(types.FitString("10"), deviceInfo.DevFields()[3].Value)
+	a.Equal(types.FitUint8(6), deviceInfo.DevFields()[1].Def.Field.DefNum)
+	a.Equal(types.FIT_TYPE_STRING, deviceInfo.DevFields()[1].Def.Field.BaseType)
+
+	// session message
+	session := dataMessages[11]
+	a.Equal(FIT_MESG_NUM_SESSION, session.Definition().GlobalMsgNum)
+	a.Equal(types.FitUint32(170000), session.Value(7))
+	a.Equal(types.FitUint32(2725), session.Value(9))
+	a.Equal(types.FitString("Run"), session.DevFields()[1].Value)
+
+	// records use a different local message type
+	a.Equal(FIT_MESG_NUM_RECORD, dataMessages[15].Definition().GlobalMsgNum)
+	a.Equal(uint8(1), dataMessages[15].LocalMsgType())
+
+	encodeAndValidate(a, fitFile, encoding.BigEndian, true, fitFileBytes)
+}
+
+func TestFitFileDecodingErrors(t *testing.T) {
+	a := assert.New(t)
+
+	fitFileBytes, _ := hex.DecodeString(
+		"0e2054081c0000002e464954b8884000010000040102840202840001000404860001090066043c0e2f3ede5f",
+	)
+	decode := func(modify func(b []byte) []byte) error {
+		b := make([]byte, len(fitFileBytes))
+		copy(b, fitFileBytes)
+		_, err := Decode(bytes.NewReader(modify(b)))
+		return err
+	}
+
+	a.ErrorIs(decode(func(b []byte) []byte {
+		b[0] = 13
+		return b
+	}), ErrInvalidHeader)
+	a.ErrorIs(decode(func(b []byte) []byte {
+		b[9] = 'X'
+		return b
+	}), ErrInvalidHeader)
+	a.ErrorIs(decode(func(b []byte) []byte {
+		b[12] ^= 0xFF
+		return b
+	}), ErrHeaderCrcMismatch)
+	a.ErrorIs(decode(func(b []byte) []byte {
+		b[len(b)-1] ^= 0xFF
+		return b
+	}), ErrFileCrcMismatch)
+	a.ErrorIs(decode(func(b []byte) []byte {
+		// data message with a local message type which wasn't defined
+		b[32] = 0x01
+		return b
+	}), ErrUndefinedLocalMsgType)
+	a.Error(decode(func(b []byte) []byte {
+		// unknown architecture
+		b[16] = 0x02
+		return b
+	}))
+	a.Error(decode(func(b []byte) []byte {
+		return b[:len(b)-5]
+	}), "decoding of truncated file")
+}
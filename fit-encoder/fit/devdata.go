@@ -0,0 +1,239 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+const (
+	maxStringFieldLength = 254
+	applicationIdSize    = 16
+)
+
+// DeveloperDataId describes the application which owns developer
+// fields with the same developer data index. Zero values of the
+// optional fields aren't encoded in the developer_data_id message.
+type DeveloperDataId struct {
+	DevDataIndex types.FitUint8
+	// Optional, the application id, e.g. the UUID of a Connect IQ app
+	ApplicationId uuid.UUID
+	// Optional, the manufacturer of the application
+	ManufacturerId types.FitUint16
+	// Optional, the version of the application
+	ApplicationVersion types.FitUint32
+}
+
+// NativeField is the regular field which a developer field provides the value for
+type NativeField struct {
+	MesgNum  types.FitUint16
+	FieldNum types.FitUint8
+}
+
+// FieldDescription describes a single developer field, allowing decoders to
+// display its name and units. Zero values of the optional fields aren't
+// encoded in the field_description message.
+type FieldDescription struct {
+	DevDataIndex types.FitUint8
+	DefNum       types.FitUint8
+	BaseType     types.FitBaseType
+	// Optional, the name of the field
+	Name string
+	// Optional, the number of elements if the field is an array
+	Array types.FitUint8
+	// Optional, the scale and offset which should be applied to the
+	// value the same way as for regular fields: value / scale - offset
+	Scale  types.FitUint8
+	Offset types.FitSint8
+	// Optional, the units of the value after applying the scale and offset
+	Units string
+	// Optional, the regular field whose value is provided by this field
+	Native *NativeField
+}
+
+// DeveloperDataIdStub is kept for compatibility, the developer
+// data id used to be represented only by its developer data index.
+//
+// Deprecated: use DeveloperDataId.
+type DeveloperDataIdStub = DeveloperDataId
+
+// FieldDescriptionStub is kept for compatibility, the field description used
+// to be represented only by its developer data index, def number and base type.
+//
+// Deprecated: use FieldDescription.
+type FieldDescriptionStub = FieldDescription
+
+// dataMessageBuilder builds a definition message along
+// with the values of the fields added to it
+type dataMessageBuilder struct {
+	def    *DefinitionMessage
+	values []interface{}
+}
+
+func (b *dataMessageBuilder) add(defNum types.FitUint8, baseType types.FitBaseType, size types.FitUint8, value interface{}) {
+	b.def.FieldDefs = append(b.def.FieldDefs, &FieldDefinition{
+		DefNum:   defNum,
+		Size:     size,
+		BaseType: baseType,
+	})
+	b.values = append(b.values, value)
+}
+
+func (b *dataMessageBuilder) addString(defNum types.FitUint8, value string) {
+	b.add(defNum, types.FIT_TYPE_STRING, types.FitUint8(len(value)+1), value)
+}
+
+// DataMessage returns the definition of the developer_data_id
+// message with all of the set fields along with their values
+func (devId *DeveloperDataId) DataMessage() (*DefinitionMessage, []interface{}) {
+	b := &dataMessageBuilder{def: &DefinitionMessage{GlobalMsgNum: FIT_MESG_NUM_DEV_DATA_ID}}
+	if devId.ApplicationId != uuid.Nil {
+		applicationId := make([]types.FitByte, applicationIdSize)
+		for i, v := range devId.ApplicationId {
+			applicationId[i] = types.FitByte(v)
+		}
+		b.add(FIT_FIELD_NUM_DEV_DATA_ID_APPLICATION_ID, types.FIT_TYPE_BYTE, applicationIdSize, applicationId)
+	}
+	if devId.ManufacturerId != 0 {
+		b.add(FIT_FIELD_NUM_DEV_DATA_ID_MANUFACTURER_ID, types.FIT_TYPE_UINT16,
+			types.FIT_TYPE_UINT16_SIZE, devId.ManufacturerId)
+	}
+	b.add(FIT_FIELD_NUM_DEV_DATA_ID_DEV_DATA_INDEX, types.FIT_TYPE_UINT8,
+		types.FIT_TYPE_UINT8_SIZE, devId.DevDataIndex)
+	if devId.ApplicationVersion != 0 {
+		b.add(FIT_FIELD_NUM_DEV_DATA_ID_APPLICATION_VERSION, types.FIT_TYPE_UINT32,
+			types.FIT_TYPE_UINT32_SIZE, devId.ApplicationVersion)
+	}
+	return b.def, b.values
+}
+
+// DataMessage returns the definition of the field_description
+// message with all of the set fields along with their values
+func (fieldDesc *FieldDescription) DataMessage() (*DefinitionMessage, []interface{}) {
+	b := &dataMessageBuilder{def: &DefinitionMessage{GlobalMsgNum: FIT_MESG_NUM_FIELD_DESC}}
+	b.add(FIT_FIELD_NUM_FIELD_DESC_DEV_DATA_INDEX, types.FIT_TYPE_UINT8,
+		types.FIT_TYPE_UINT8_SIZE, fieldDesc.DevDataIndex)
+	b.add(FIT_FIELD_NUM_FIELD_DESC_FIELD_DEF_NUM, types.FIT_TYPE_UINT8,
+		types.FIT_TYPE_UINT8_SIZE, fieldDesc.DefNum)
+	b.add(FIT_FIELD_NUM_FIELD_DESC_BASE_TYPE, types.FIT_TYPE_UINT8,
+		types.FIT_TYPE_UINT8_SIZE, types.FitUint8(fieldDesc.BaseType))
+	if fieldDesc.Name != "" {
+		b.addString(FIT_FIELD_NUM_FIELD_DESC_FIELD_NAME, fieldDesc.Name)
+	}
+	if fieldDesc.Array != 0 {
+		b.add(FIT_FIELD_NUM_FIELD_DESC_ARRAY, types.FIT_TYPE_UINT8,
+			types.FIT_TYPE_UINT8_SIZE, fieldDesc.Array)
+	}
+	if fieldDesc.Scale != 0 {
+		b.add(FIT_FIELD_NUM_FIELD_DESC_SCALE, types.FIT_TYPE_UINT8,
+			types.FIT_TYPE_UINT8_SIZE, fieldDesc.Scale)
+	}
+	if fieldDesc.Offset != 0 {
+		b.add(FIT_FIELD_NUM_FIELD_DESC_OFFSET, types.FIT_TYPE_SINT8,
+			types.FIT_TYPE_SINT8_SIZE, fieldDesc.Offset)
+	}
+	if fieldDesc.Units != "" {
+		b.addString(FIT_FIELD_NUM_FIELD_DESC_UNITS, fieldDesc.Units)
+	}
+	if fieldDesc.Native != nil {
+		b.add(FIT_FIELD_NUM_FIELD_DESC_NATIVE_MESG_NUM, types.FIT_TYPE_UINT16,
+			types.FIT_TYPE_UINT16_SIZE, fieldDesc.Native.MesgNum)
+		b.add(FIT_FIELD_NUM_FIELD_DESC_NATIVE_FIELD_NUM, types.FIT_TYPE_UINT8,
+			types.FIT_TYPE_UINT8_SIZE, fieldDesc.Native.FieldNum)
+	}
+	return b.def, b.values
+}
+
+// WriteDeveloperData writes the developer_data_id message followed by the
+// field_description messages of all of the fields. The string fields of the
+// descriptions are padded to the same size, so that descriptions with the
+// same set of fields share a single definition message.
+func WriteDeveloperData(wr MessageWriter, devId *DeveloperDataId, fields ...*FieldDescription) error {
+	for _, fieldDesc := range fields {
+		if fieldDesc.DevDataIndex != devId.DevDataIndex {
+			return errors.New("field description developer data index mismatch")
+		}
+		if len(fieldDesc.Name) > maxStringFieldLength || len(fieldDesc.Units) > maxStringFieldLength {
+			return errors.New("unable to encode field description strings with length > 254")
+		}
+	}
+
+	def, values := devId.DataMessage()
+	if err := wr.AddData(def, values...); err != nil {
+		return err
+	}
+
+	defs := make([]*DefinitionMessage, len(fields))
+	fieldValues := make([][]interface{}, len(fields))
+	// maximum string field sizes of descriptions with the same set of fields
+	type stringFieldKey struct {
+		layout string
+		defNum types.FitUint8
+	}
+	stringSizes := make(map[stringFieldKey]types.FitUint8)
+	for i, fieldDesc := range fields {
+		defs[i], fieldValues[i] = fieldDesc.DataMessage()
+		layout := fieldLayout(defs[i])
+		for _, fieldDef := range defs[i].FieldDefs {
+			key := stringFieldKey{layout, fieldDef.DefNum}
+			if fieldDef.BaseType == types.FIT_TYPE_STRING && fieldDef.Size > stringSizes[key] {
+				stringSizes[key] = fieldDef.Size
+			}
+		}
+	}
+
+	for i := range fields {
+		layout := fieldLayout(defs[i])
+		for _, fieldDef := range defs[i].FieldDefs {
+			if fieldDef.BaseType == types.FIT_TYPE_STRING {
+				fieldDef.Size = stringSizes[stringFieldKey{layout, fieldDef.DefNum}]
+			}
+		}
+		if err := wr.AddData(defs[i], fieldValues[i]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldLayout returns a string identifying the set of fields in the definition
+func fieldLayout(def *DefinitionMessage) string {
+	var sb strings.Builder
+	for _, fieldDef := range def.FieldDefs {
+		sb.WriteByte(byte(fieldDef.DefNum))
+	}
+	return sb.String()
+}
+
+// NewDevFieldDefinition creates the definition of a developer field described by
+// fieldDesc. If size is 0, it is computed from the base type and array size of
+// the field, which is impossible for strings, since their size isn't fixed.
+func NewDevFieldDefinition(devId *DeveloperDataId, fieldDesc *FieldDescription, size types.FitUint8) (*DevFieldDefinition, error) {
+	if size == 0 {
+		if fieldDesc.BaseType == types.FIT_TYPE_STRING {
+			return nil, errors.New("size of string developer fields must be specified")
+		}
+		baseSize, ok := types.FitTypeSize[fieldDesc.BaseType]
+		if !ok {
+			return nil, types.ErrUnknownFitType
+		}
+		size = baseSize
+		if fieldDesc.Array != 0 {
+			size *= fieldDesc.Array
+		}
+	}
+
+	devFieldDef := &DevFieldDefinition{
+		Field: fieldDesc,
+		Size:  size,
+		DevId: devId,
+	}
+	if err := devFieldDef.validate(); err != nil {
+		return nil, err
+	}
+	return devFieldDef, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeveloperData(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	devId := &DeveloperDataId{
+		DevDataIndex:       1,
+		ApplicationId:      uuid.MustParse("a1b2c3d4-e5f6-4788-99aa-bbccddeeff00"),
+		ManufacturerId:     255,
+		ApplicationVersion: 42,
+	}
+	power := &FieldDescription{
+		DevDataIndex: 1,
+		DefNum:       0,
+		BaseType:     types.FIT_TYPE_UINT16,
+		Name:         "power",
+		Units:        "watts",
+		Native:       &NativeField{MesgNum: FIT_MESG_NUM_RECORD, FieldNum: 7},
+	}
+	temperature := &FieldDescription{
+		DevDataIndex: 1,
+		DefNum:       1,
+		BaseType:     types.FIT_TYPE_SINT16,
+		Name:         "core_temperature",
+		Units:        "C",
+		Scale:        10,
+		Offset:       -5,
+	}
+	skinTemperature := &FieldDescription{
+		DevDataIndex: 1,
+		DefNum:       3,
+		BaseType:     types.FIT_TYPE_SINT16,
+		Name:         "skin_temp",
+		Units:        "C",
+		Scale:        10,
+		Offset:       -5,
+	}
+	gears := &FieldDescription{
+		DevDataIndex: 1,
+		DefNum:       2,
+		BaseType:     types.FIT_TYPE_UINT8,
+		Name:         "gears",
+		Array:        2,
+	}
+
+	fitFile := new(FitFile)
+	r.NoError(WriteDeveloperData(fitFile, devId, power, temperature, gears, skinTemperature))
+	// the temperatures only differ in the lengths of the strings and must share a definition
+	a.Equal([]uint8{0, 1, 2, 3}, localTypesOf(fitFile))
+
+	recordDef := singleFieldDefinition(FIT_MESG_NUM_RECORD, 6)
+	for _, fieldDesc := range []*FieldDescription{power, temperature, gears} {
+		devFieldDef, err := NewDevFieldDefinition(devId, fieldDesc, 0)
+		r.NoError(err)
+		recordDef.DevFieldDefs = append(recordDef.DevFieldDefs, devFieldDef)
+	}
+	a.Equal(types.FitUint8(2), recordDef.DevFieldDefs[2].Size)
+	r.NoError(fitFile.AddData(recordDef, 1000, 250, -52, []types.FitUint8{2, 11}))
+
+	buffer := new(bytes.Buffer)
+	r.NoError(fitFile.Encode(buffer, encoding.LittleEndian))
+	decoded, err := Decode(buffer)
+	r.NoError(err)
+
+	dataMessages := decoded.DataMessages()
+	r.Len(dataMessages, 6)
+	a.Equal(types.FitString("core_temperature"), dataMessages[2].Value(FIT_FIELD_NUM_FIELD_DESC_FIELD_NAME))
+	a.Equal(types.FitString("skin_temp"), dataMessages[4].Value(FIT_FIELD_NUM_FIELD_DESC_FIELD_NAME))
+	devFields := dataMessages[5].DevFields()
+	r.Len(devFields, 3)
+	a.Equal(devId, devFields[0].Def.DevId)
+	a.Equal(power, devFields[0].Def.Field)
+	a.Equal(temperature, devFields[1].Def.Field)
+	a.Equal(gears, devFields[2].Def.Field)
+	a.Equal(types.FitSint16(-52), devFields[1].Value)
+	a.Equal([]types.FitUint8{2, 11}, devFields[2].Value)
+
+	// fields whose size isn't a multiple of the base type size are byte arrays
+	byteArrayDef, err := NewDevFieldDefinition(devId, power, 3)
+	r.NoError(err)
+	a.Equal(3, byteArrayDef.fieldDefinition().ArrayLength())
+
+	// Error tests
+	a.Error(WriteDeveloperData(new(FitFile), &DeveloperDataId{DevDataIndex: 2}, power))
+
+	_, err = NewDevFieldDefinition(devId, &FieldDescription{DevDataIndex: 1, BaseType: types.FIT_TYPE_STRING}, 0)
+	a.Error(err)
+
+	_, err = NewDevFieldDefinition(&DeveloperDataId{DevDataIndex: 2}, power, 0)
+	a.Error(err)
+
+}
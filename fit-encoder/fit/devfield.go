@@ -11,27 +11,13 @@ import (
 	"github.com/renbou/jogmock/fit-encoder/internal/maybeio"
 )
 
-// DeveloperDataIdStub is a struct representing only the "required" part
-// of a DeveloperDataId message - the developer data index
-type DeveloperDataIdStub struct {
-	DevDataIndex types.FitUint8
-}
-
-// FieldDescriptionStub is a struct representing only the "required" part
-// of a FieldDescription message - the developer data index and field def number
-type FieldDescriptionStub struct {
-	DevDataIndex types.FitUint8
-	DefNum       types.FitUint8
-	BaseType     types.FitBaseType
-}
-
 // DevFieldDefinition struct encapsulates the developer field definition
 // logic in a way that makes usages explicitly specify the field description
 // and developer data id
 type DevFieldDefinition struct {
-	Field *FieldDescriptionStub
+	Field *FieldDescription
 	Size  types.FitUint8
-	DevId *DeveloperDataIdStub
+	DevId *DeveloperDataId
 }
 
 func (devFieldDef *DevFieldDefinition) validate() error {
@@ -39,11 +25,17 @@ func (devFieldDef *DevFieldDefinition) validate() error {
 		return errors.New("developer field definition developer data index mismatch")
 	}
 
-	return (&FieldDefinition{
+	return devFieldDef.fieldDefinition().validate()
+}
+
+// fieldDefinition returns the regular field definition
+// describing the layout of the developer field's value
+func (devFieldDef *DevFieldDefinition) fieldDefinition() *FieldDefinition {
+	return &FieldDefinition{
 		DefNum:   devFieldDef.Field.DefNum,
 		Size:     devFieldDef.Size,
 		BaseType: devFieldDef.Field.BaseType,
-	}).validate()
+	}
 }
 
 func (devFieldDef *DevFieldDefinition) Encode(wr io.Writer, endianness encoding.Endianness) error {
@@ -66,23 +58,5 @@ func (devField *DevField) Encode(wr io.Writer, endianness encoding.Endianness) e
 	if err := devField.Def.validate(); err != nil {
 		return err
 	}
-	if err := devField.Def.Field.BaseType.ValidateValue(devField.Value); err != nil {
-		return err
-	}
-
-	switch devField.Value.(type) {
-	case encoding.EndianEncoder:
-		return devField.Value.(encoding.EndianEncoder).Encode(wr, endianness)
-	case types.FitString:
-		encodableStr := &types.FitEncodableString{
-			FitString: devField.Value.(types.FitString),
-			Length:    devField.Def.Size,
-		}
-		if err := encodableStr.Validate(); err != nil {
-			return err
-		}
-		return encodableStr.Encode(wr, endianness)
-	default:
-		return types.ErrUnknownFitType
-	}
+	return encodeFieldValue(wr, endianness, devField.Def.fieldDefinition(), devField.Value)
 }
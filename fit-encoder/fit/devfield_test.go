@@ -59,7 +59,7 @@ func TestFitDevFieldDefinitionEncoding(t *testing.T) {
 		DevId: &DeveloperDataIdStub{
 			DevDataIndex: 3,
 		},
-	}, encoding.BigEndian, false, nil)
+	}, encoding.BigEndian, true, []byte{5, 3, 3})
 
 	encodeAndValidate(a, &DevFieldDefinition{
 		Field: &FieldDescriptionStub{
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 
 	"github.com/renbou/jogmock/fit-encoder/encoding"
 	"github.com/renbou/jogmock/fit-encoder/fit/types"
@@ -26,22 +27,48 @@ func (fieldDef *FieldDefinition) validate() error {
 		return types.ErrUnknownFitType
 	}
 
-	if fieldDef.BaseType == types.FIT_TYPE_STRING {
-		if fieldDef.Size < 1 {
-			return errors.New("field definition size with base string type must be at least 1")
-		}
-	} else {
-		// we already check that the type is known at the beginning,
-		// and the only type without a "known" size should be a string
-		properSize := types.FitTypeSize[fieldDef.BaseType]
-		if properSize != fieldDef.Size {
-			return fmt.Errorf("field definition with base type %v has unexpected size %v (instead of %v)",
-				fieldDef.BaseType, fieldDef.Size, properSize)
-		}
+	// Array fields contain multiple values, so their size is a multiple of the base size,
+	// while fields of any other size are byte arrays as per the spec, so only strings,
+	// which must fit at least the null terminator, can have an invalid size
+	if fieldDef.BaseType == types.FIT_TYPE_STRING && fieldDef.Size < 1 {
+		return errors.New("field definition size with base string type must be at least 1")
 	}
 	return nil
 }
 
+// isByteArray returns whether the value of the field is stored as an array of bytes,
+// which is the case when the size isn't a multiple of the base type size
+func (fieldDef *FieldDefinition) isByteArray() bool {
+	properSize, ok := types.FitTypeSize[fieldDef.BaseType]
+	if !ok || fieldDef.BaseType == types.FIT_TYPE_STRING || properSize == 0 {
+		return false
+	}
+	return fieldDef.Size == 0 || fieldDef.Size%properSize != 0
+}
+
+// valueType returns the base type of the values stored in the field,
+// which is FIT_TYPE_BYTE for fields stored as byte arrays
+func (fieldDef *FieldDefinition) valueType() types.FitBaseType {
+	if fieldDef.isByteArray() {
+		return types.FIT_TYPE_BYTE
+	}
+	return fieldDef.BaseType
+}
+
+// ArrayLength returns the number of values stored in the field, which is more than 1
+// only for array fields. Strings are always a single value, and byte arrays contain
+// one value per byte of the field.
+func (fieldDef *FieldDefinition) ArrayLength() int {
+	properSize, ok := types.FitTypeSize[fieldDef.BaseType]
+	if !ok || fieldDef.BaseType == types.FIT_TYPE_STRING || properSize == 0 {
+		return 1
+	}
+	if fieldDef.isByteArray() {
+		return int(fieldDef.Size)
+	}
+	return int(fieldDef.Size / properSize)
+}
+
 func (fieldDef *FieldDefinition) Encode(wr io.Writer, endianness encoding.Endianness) error {
 	if err := fieldDef.validate(); err != nil {
 		return err
@@ -53,26 +80,62 @@ func (fieldDef *FieldDefinition) Encode(wr io.Writer, endianness encoding.Endian
 	return mwr.Error()
 }
 
+// Decode decodes the field definition without validating it, since
+// the base type or size might only be invalid for the current implementation
+func (fieldDef *FieldDefinition) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	if err := fieldDef.DefNum.Decode(rd, endianness); err != nil {
+		return err
+	}
+	if err := fieldDef.Size.Decode(rd, endianness); err != nil {
+		return err
+	}
+	return fieldDef.BaseType.Decode(rd, endianness)
+}
+
 type Field struct {
 	Def   *FieldDefinition
 	Value interface{}
 }
 
 func (field *Field) Encode(wr io.Writer, endianness encoding.Endianness) error {
-	if err := field.Def.validate(); err != nil {
+	return encodeFieldValue(wr, endianness, field.Def, field.Value)
+}
+
+// encodeFieldValue encodes the value of a field or developer field, which is either
+// a single value of the field's base type or a slice of such values for array fields
+func encodeFieldValue(wr io.Writer, endianness encoding.Endianness, fieldDef *FieldDefinition, value interface{}) error {
+	if err := fieldDef.validate(); err != nil {
 		return err
 	}
-	if err := field.Def.BaseType.ValidateValue(field.Value); err != nil {
+
+	if arrayValue := reflect.ValueOf(value); arrayValue.Kind() == reflect.Slice {
+		if err := fieldDef.valueType().ValidateArrayValue(value); err != nil {
+			return err
+		}
+		if arrayValue.Len() != fieldDef.ArrayLength() {
+			return fmt.Errorf("%w: got %d values instead of %d", ErrArrayLengthMismatch, arrayValue.Len(), fieldDef.ArrayLength())
+		}
+		mwr := maybeio.NewWriter(wr)
+		for i := 0; i < arrayValue.Len(); i++ {
+			arrayValue.Index(i).Interface().(encoding.EndianEncoder).Encode(mwr, endianness)
+		}
+		return mwr.Error()
+	}
+
+	if err := fieldDef.valueType().ValidateValue(value); err != nil {
 		return err
 	}
+	if fieldDef.ArrayLength() != 1 {
+		return fmt.Errorf("%w: got a single value instead of %d", ErrArrayLengthMismatch, fieldDef.ArrayLength())
+	}
 
-	switch field.Value.(type) {
+	switch value.(type) {
 	case encoding.EndianEncoder:
-		return field.Value.(encoding.EndianEncoder).Encode(wr, endianness)
+		return value.(encoding.EndianEncoder).Encode(wr, endianness)
 	case types.FitString:
 		encodableStr := &types.FitEncodableString{
-			FitString: field.Value.(types.FitString),
-			Length:    field.Def.Size,
+			FitString: value.(types.FitString),
+			Length:    fieldDef.Size,
 		}
 		if err := encodableStr.Validate(); err != nil {
 			return err
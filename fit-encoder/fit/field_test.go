@@ -148,6 +148,136 @@ func TestFitFieldEncoding(t *testing.T) {
 	}, encoding.LittleEndian, false, nil)
 }
 
+func TestFitFieldExtendedTypesEncoding(t *testing.T) {
+	a := assert.New(t)
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   73,
+			Size:     types.FIT_TYPE_FLOAT32_SIZE,
+			BaseType: types.FIT_TYPE_FLOAT32,
+		},
+		Value: types.FitFloat32(3.5),
+	}, encoding.BigEndian, true, []byte{0x40, 0x60, 0x00, 0x00})
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   3,
+			Size:     types.FIT_TYPE_UINT32Z_SIZE,
+			BaseType: types.FIT_TYPE_UINT32Z,
+		},
+		Value: types.FitUint32z(0x12345678),
+	}, encoding.LittleEndian, true, []byte{0x78, 0x56, 0x34, 0x12})
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   30,
+			Size:     types.FIT_TYPE_BYTE_SIZE,
+			BaseType: types.FIT_TYPE_BYTE,
+		},
+		Value: types.FitByte(0xFF),
+	}, encoding.LittleEndian, true, []byte{0xFF})
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   3,
+			Size:     types.FIT_TYPE_FLOAT32_SIZE,
+			BaseType: types.FIT_TYPE_FLOAT64,
+		},
+		Value: types.FitFloat64(1),
+	}, encoding.LittleEndian, false, nil)
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   3,
+			Size:     types.FIT_TYPE_UINT16Z_SIZE,
+			BaseType: types.FIT_TYPE_UINT16Z,
+		},
+		Value: types.FitUint16(1),
+	}, encoding.LittleEndian, false, nil)
+}
+
+func TestFitFieldArrayEncoding(t *testing.T) {
+	a := assert.New(t)
+
+	encodeAndValidate(a, &FieldDefinition{
+		DefNum:   5,
+		Size:     3 * types.FIT_TYPE_UINT16_SIZE,
+		BaseType: types.FIT_TYPE_UINT16,
+	}, encoding.LittleEndian, true, []byte{5, 6, 0x84})
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   5,
+			Size:     3 * types.FIT_TYPE_UINT16_SIZE,
+			BaseType: types.FIT_TYPE_UINT16,
+		},
+		Value: []types.FitUint16{0x1234, 0x0001, 0xFFFF},
+	}, encoding.BigEndian, true, []byte{0x12, 0x34, 0x00, 0x01, 0xFF, 0xFF})
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   2,
+			Size:     4,
+			BaseType: types.FIT_TYPE_BYTE,
+		},
+		Value: []types.FitByte{0xDE, 0xAD, 0xBE, 0xEF},
+	}, encoding.LittleEndian, true, []byte{0xDE, 0xAD, 0xBE, 0xEF})
+
+	// fields whose size isn't a multiple of the base type size are byte arrays
+	encodeAndValidate(a, &FieldDefinition{
+		DefNum:   5,
+		Size:     5,
+		BaseType: types.FIT_TYPE_UINT16,
+	}, encoding.LittleEndian, true, []byte{5, 5, 0x84})
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   5,
+			Size:     5,
+			BaseType: types.FIT_TYPE_UINT16,
+		},
+		Value: []types.FitByte{1, 2, 3, 4, 5},
+	}, encoding.LittleEndian, true, []byte{1, 2, 3, 4, 5})
+
+	// Error tests
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   5,
+			Size:     5,
+			BaseType: types.FIT_TYPE_UINT16,
+		},
+		Value: []types.FitUint16{1, 2},
+	}, encoding.LittleEndian, false, nil)
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   5,
+			Size:     3 * types.FIT_TYPE_UINT16_SIZE,
+			BaseType: types.FIT_TYPE_UINT16,
+		},
+		Value: []types.FitUint16{1, 2},
+	}, encoding.LittleEndian, false, nil)
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   5,
+			Size:     3 * types.FIT_TYPE_UINT16_SIZE,
+			BaseType: types.FIT_TYPE_UINT16,
+		},
+		Value: types.FitUint16(1),
+	}, encoding.LittleEndian, false, nil)
+
+	encodeAndValidate(a, &Field{
+		Def: &FieldDefinition{
+			DefNum:   5,
+			Size:     2 * types.FIT_TYPE_UINT16_SIZE,
+			BaseType: types.FIT_TYPE_UINT16,
+		},
+		Value: []types.FitSint16{1, 2},
+	}, encoding.LittleEndian, false, nil)
+}
+
 func TestFitFieldErrors(t *testing.T) {
 	a := assert.New(t)
 
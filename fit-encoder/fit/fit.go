@@ -3,69 +3,209 @@
 package fit
 
 import (
-	"bytes"
 	"encoding/binary"
+	"errors"
+	"hash"
 	"io"
+	"math"
 
 	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
 	"github.com/renbou/jogmock/fit-encoder/internal/hash/crc16"
 )
 
+// MessageWriter is implemented by everything definition and data messages
+// can be sequentially added to, such as a FitFile or a StreamEncoder
+type MessageWriter interface {
+	AddMessage(message encoding.EndianEncoder) error
+	// AddData adds a data message for def, automatically assigning it
+	// a local message type and adding the definition message only if needed
+	AddData(def *DefinitionMessage, values ...interface{}) error
+	// AddCompressedData is the same as AddData, but
+	// the data message uses a compressed timestamp header
+	AddCompressedData(def *DefinitionMessage, timestamp types.FitUint32, values ...interface{}) error
+}
+
 // FitFile stores sequential definition and data messages
 // and can be used to later encode all of them with a valid
 // fit header and footer with crc
 type FitFile struct {
-	messages []encoding.EndianEncoder
+	// Header configures the header written when encoding the file,
+	// decoded files have it set to the header they were decoded from
+	Header        HeaderOptions
+	messages      []encoding.EndianEncoder
+	localMsgTypes localMsgTypes
 }
 
-func (f *FitFile) AddMessage(message encoding.EndianEncoder) error {
-	if _, ok := message.(*localDefinitionMessage); ok {
-		f.messages = append(f.messages, message)
-	} else if _, ok := message.(*localDataMessage); ok {
-		f.messages = append(f.messages, message)
-	} else {
+// validateMessage checks that message is one of the encodable message types
+// and updates the local message types assigned by the previous messages
+func validateMessage(message encoding.EndianEncoder, lt *localMsgTypes) error {
+	switch msg := message.(type) {
+	case *localDefinitionMessage:
+		lt.define(msg)
+	case *localDataMessage:
+		lt.use(msg)
+	default:
 		return ErrInvalidMessage
 	}
 	return nil
 }
 
-func (f *FitFile) Encode(wr io.Writer, endianness encoding.Endianness) error {
-	// first encode all of the messages
-	buffer := new(bytes.Buffer)
-	bufferEncoder := encoding.NewEncoder(buffer, endianness)
+func (f *FitFile) AddMessage(message encoding.EndianEncoder) error {
+	if err := validateMessage(message, &f.localMsgTypes); err != nil {
+		return err
+	}
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+func (f *FitFile) AddData(def *DefinitionMessage, values ...interface{}) error {
+	return f.localMsgTypes.addData(f.AddMessage, def, false, 0, values)
+}
+
+func (f *FitFile) AddCompressedData(def *DefinitionMessage, timestamp types.FitUint32, values ...interface{}) error {
+	return f.localMsgTypes.addData(f.AddMessage, def, true, timestamp, values)
+}
+
+// DataMessages returns all of the data messages
+// stored in the file in the order they were added
+func (f *FitFile) DataMessages() []*localDataMessage {
+	var dataMessages []*localDataMessage
 	for _, message := range f.messages {
-		bufferEncoder.Encode(message)
+		if dataMessage, ok := message.(*localDataMessage); ok {
+			dataMessages = append(dataMessages, dataMessage)
+		}
+	}
+	return dataMessages
+}
+
+const (
+	DefaultProtocolVersion uint8  = 0x20
+	DefaultProfileVersion  uint16 = 0x0854
+)
+
+// HeaderOptions configures the header of an encoded fit file.
+// The zero value describes the default 14-byte header.
+type HeaderOptions struct {
+	// ProtocolVersion defaults to DefaultProtocolVersion if unset
+	ProtocolVersion uint8
+	// ProfileVersion defaults to DefaultProfileVersion if unset
+	ProfileVersion uint16
+	// Legacy selects the 12-byte header without the header crc
+	Legacy bool
+}
+
+// encodeFileHeader constructs the fit file header, which is either the
+// 14-byte header with a header crc or the legacy 12-byte header
+func encodeFileHeader(dataSize uint32, options HeaderOptions) []byte {
+	protocolVersion := options.ProtocolVersion
+	if protocolVersion == 0 {
+		protocolVersion = DefaultProtocolVersion
+	}
+	profileVersion := options.ProfileVersion
+	if profileVersion == 0 {
+		profileVersion = DefaultProfileVersion
 	}
 
-	dataSize := buffer.Len()
-	dataSizeBytes := make([]byte, 4)
-	binary.LittleEndian.PutUint32(dataSizeBytes, uint32(dataSize))
-	dataCrc := crc16.New()
-	dataCrc.Write(buffer.Bytes())
+	size := byte(fitHeaderSize)
+	if options.Legacy {
+		size = fitLegacyHeaderSize
+	}
+	header := make([]byte, 8, size)
+	header[0] = size
+	header[1] = protocolVersion
+	binary.LittleEndian.PutUint16(header[2:4], profileVersion)
+	binary.LittleEndian.PutUint32(header[4:8], dataSize)
+	header = append(header, []byte(fitDataType)...)
+	if options.Legacy {
+		return header
+	}
 
-	// write header
-	header := []byte{0x0E, 0x20, 0x54, 0x08}
-	header = append(header, dataSizeBytes...)
-	header = append(header, []byte(".FIT")...)
 	headerCrc := crc16.New()
 	headerCrc.Write(header)
-	header = append(header, headerCrc.Sum(nil)...)
-	_, err := wr.Write(header)
-	if err != nil {
+	return append(header, headerCrc.Sum(nil)...)
+}
+
+// dataWriter writes the data records of a fit file
+// while computing their total size and crc
+type dataWriter struct {
+	wr   io.Writer
+	crc  hash.Hash
+	size int64
+}
+
+func newDataWriter(wr io.Writer) *dataWriter {
+	return &dataWriter{wr: wr, crc: crc16.New()}
+}
+
+func (dwr *dataWriter) Write(b []byte) (n int, err error) {
+	n, err = dwr.wr.Write(b)
+	dwr.crc.Write(b[:n])
+	dwr.size += int64(n)
+	return
+}
+
+// dataSize returns the size of the written data records
+// or an error if it doesn't fit into the fit header
+func (dwr *dataWriter) dataSize() (uint32, error) {
+	if dwr.size > math.MaxUint32 {
+		return 0, errors.New("fit file data size exceeds 4GiB")
+	}
+	return uint32(dwr.size), nil
+}
+
+// encodeMessages encodes all of the messages, expanding compressed timestamps where needed
+func (f *FitFile) encodeMessages(wr io.Writer, endianness encoding.Endianness) error {
+	encoder := encoding.NewEncoder(wr, endianness)
+	timestamps := new(timestampTracker)
+	for _, message := range f.messages {
+		for _, encodable := range timestamps.messagesToEncode(message) {
+			if err := encoder.Encode(encodable); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Encode encodes the fit file in two passes: the first pass only
+// calculates the data size, while the second actually writes the
+// messages, so the encoded data is never buffered in memory
+func (f *FitFile) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	// first pass, calculate the data size
+	sizingWriter := newDataWriter(io.Discard)
+	if err := f.encodeMessages(sizingWriter, endianness); err != nil {
 		return err
 	}
 
-	// write actual messages
-	_, err = wr.Write(buffer.Bytes())
+	dataSize, err := sizingWriter.dataSize()
 	if err != nil {
 		return err
 	}
 
-	// write data crc
-	_, err = wr.Write(dataCrc.Sum(nil))
-	if err != nil {
+	// second pass, write the header and actual messages. The file crc
+	// is computed over the header as well, which only matters for the
+	// legacy header, since a header with a crc resets the crc to 0
+	crcWriter := newDataWriter(wr)
+	if _, err := crcWriter.Write(encodeFileHeader(dataSize, f.Header)); err != nil {
+		return err
+	}
+	if err := f.encodeMessages(crcWriter, endianness); err != nil {
 		return err
 	}
 
+	// write file crc
+	_, err = wr.Write(crcWriter.crc.Sum(nil))
+	return err
+}
+
+// EncodeChained encodes the fit files one after another into a single
+// chained fit stream, each file having its own header and crc
+func EncodeChained(wr io.Writer, endianness encoding.Endianness, files ...*FitFile) error {
+	for _, f := range files {
+		if err := f.Encode(wr, endianness); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,122 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+const numLocalMsgTypes = 16
+
+// key returns a string uniquely identifying the layout of the definition,
+// so that identical definitions can share the same local message type
+func (defMsg *DefinitionMessage) key() string {
+	var sb strings.Builder
+	num := make([]byte, 2)
+	binary.LittleEndian.PutUint16(num, uint16(defMsg.GlobalMsgNum))
+	sb.Write(num)
+	sb.WriteByte(byte(len(defMsg.FieldDefs)))
+	for _, fieldDef := range defMsg.FieldDefs {
+		sb.Write([]byte{byte(fieldDef.DefNum), byte(fieldDef.Size), byte(fieldDef.BaseType)})
+	}
+	for _, devFieldDef := range defMsg.DevFieldDefs {
+		sb.Write([]byte{
+			byte(devFieldDef.Field.DevDataIndex), byte(devFieldDef.Field.DefNum),
+			byte(devFieldDef.Size), byte(devFieldDef.Field.BaseType),
+		})
+	}
+	return sb.String()
+}
+
+// localMsgTypeSlot is a single local message type along with the
+// definition currently assigned to it in the encoded file
+type localMsgTypeSlot struct {
+	key      string
+	def      *DefinitionMessage
+	lastUsed uint64
+}
+
+// localMsgTypes keeps track of the definitions assigned to each of the
+// local message types, allocating them in least-recently-used order
+type localMsgTypes struct {
+	slots [numLocalMsgTypes]localMsgTypeSlot
+	clock uint64
+}
+
+func (lt *localMsgTypes) touch(localMsgType uint8) {
+	lt.clock++
+	lt.slots[localMsgType].lastUsed = lt.clock
+}
+
+// define marks localMsgType as containing the definition
+// of a definition message which was added manually
+func (lt *localMsgTypes) define(localDefMsg *localDefinitionMessage) {
+	lt.slots[localDefMsg.localMsgType] = localMsgTypeSlot{
+		key: localDefMsg.def.key(),
+		def: localDefMsg.def,
+	}
+	lt.touch(localDefMsg.localMsgType)
+}
+
+// use marks the local message type of a manually added data message as recently used
+func (lt *localMsgTypes) use(localDataMsg *localDataMessage) {
+	if lt.slots[localDataMsg.localMsgType].def != nil {
+		lt.touch(localDataMsg.localMsgType)
+	}
+}
+
+// find returns the local message type to use for def and whether it already
+// contains an identical definition. If there is no such local message type,
+// an unused or the least-recently-used one is returned. Data messages with
+// compressed timestamps can only use the first few local message types.
+func (lt *localMsgTypes) find(def *DefinitionMessage, compressed bool) (uint8, bool) {
+	limit := uint8(numLocalMsgTypes)
+	if compressed {
+		limit = maxCompressedLocalMsgType + 1
+	}
+
+	key := def.key()
+	victim := uint8(0)
+	for localMsgType := uint8(0); localMsgType < limit; localMsgType++ {
+		slot := &lt.slots[localMsgType]
+		if slot.def != nil && slot.key == key {
+			return localMsgType, true
+		}
+		if slot.lastUsed < lt.slots[victim].lastUsed {
+			victim = localMsgType
+		}
+	}
+	return victim, false
+}
+
+// addData constructs the data message for def and passes it to add, preceded
+// by the definition message if def isn't assigned to any local message type yet.
+// add is expected to update the local message types via define and use.
+func (lt *localMsgTypes) addData(add func(encoding.EndianEncoder) error,
+	def *DefinitionMessage, compressed bool, timestamp types.FitUint32, values []interface{},
+) error {
+	localMsgType, defined := lt.find(def, compressed)
+	localDefMsg := def.ConstructLocalMessage(localMsgType)
+
+	var localDataMsg *localDataMessage
+	var err error
+	if compressed {
+		localDataMsg, err = localDefMsg.ConstructCompressedData(timestamp, values...)
+	} else {
+		localDataMsg, err = localDefMsg.ConstructData(values...)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !defined {
+		if err := add(localDefMsg); err != nil {
+			return err
+		}
+	}
+	return add(localDataMsg)
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// singleFieldDefinition creates a definition of a message with a single uint16 field
+func singleFieldDefinition(globalMsgNum types.FitUint16, defNum types.FitUint8) *DefinitionMessage {
+	return &DefinitionMessage{
+		GlobalMsgNum: globalMsgNum,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   defNum,
+				Size:     types.FIT_TYPE_UINT16_SIZE,
+				BaseType: types.FIT_TYPE_UINT16,
+			},
+		},
+	}
+}
+
+// localTypesOf returns the local message types of all of the file's definition messages
+func localTypesOf(f *FitFile) []uint8 {
+	var localTypes []uint8
+	for _, message := range f.messages {
+		if localDefMsg, ok := message.(*localDefinitionMessage); ok {
+			localTypes = append(localTypes, localDefMsg.localMsgType)
+		}
+	}
+	return localTypes
+}
+
+func TestLocalMsgTypeReuse(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	fitFile := new(FitFile)
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_RECORD, 5), 1))
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_EVENT, 2), 2))
+	// identical definitions are reused even if they are different instances
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_RECORD, 5), 3))
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_RECORD, 6), 4))
+
+	a.Equal([]uint8{0, 1, 2}, localTypesOf(fitFile))
+	dataMessages := fitFile.DataMessages()
+	r.Len(dataMessages, 4)
+	a.Equal([]uint8{0, 1, 0, 2}, []uint8{
+		dataMessages[0].LocalMsgType(), dataMessages[1].LocalMsgType(),
+		dataMessages[2].LocalMsgType(), dataMessages[3].LocalMsgType(),
+	})
+
+	// Error tests
+	a.ErrorIs(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_LAP, 1)), ErrFieldNumMismatch)
+	a.Len(fitFile.messages, 7, "nothing must be added on error")
+}
+
+func TestLocalMsgTypeEviction(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	fitFile := new(FitFile)
+	for i := 0; i < numLocalMsgTypes; i++ {
+		r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_RECORD, types.FitUint8(i)), i))
+	}
+	// use the first definition again, so that the second one is least recently used
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_RECORD, 0), 0))
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_EVENT, 0), 0))
+	// the evicted definition must be emitted again
+	r.NoError(fitFile.AddData(singleFieldDefinition(FIT_MESG_NUM_RECORD, 1), 1))
+
+	localTypes := localTypesOf(fitFile)
+	r.Len(localTypes, numLocalMsgTypes+2)
+	a.Equal(uint8(1), localTypes[numLocalMsgTypes])
+	a.Equal(uint8(2), localTypes[numLocalMsgTypes+1])
+
+	// compressed timestamp messages only use the first local message types
+	r.NoError(fitFile.AddCompressedData(singleFieldDefinition(FIT_MESG_NUM_RECORD, 7), 1000, 7))
+	localTypes = localTypesOf(fitFile)
+	r.Len(localTypes, numLocalMsgTypes+3)
+	a.LessOrEqual(localTypes[numLocalMsgTypes+2], maxCompressedLocalMsgType)
+
+	// the file must still decode to the same values
+	buffer := new(bytes.Buffer)
+	r.NoError(fitFile.Encode(buffer, encoding.LittleEndian))
+	decoded, err := Decode(buffer)
+	r.NoError(err)
+	expected := fitFile.DataMessages()
+	actual := decoded.DataMessages()
+	r.Len(actual, len(expected))
+	for i := range expected {
+		field := expected[i].Fields()[0]
+		a.Equal(expected[i].Definition().GlobalMsgNum, actual[i].Definition().GlobalMsgNum)
+		a.Equal(field.Value, actual[i].Value(field.Def.DefNum))
+	}
+}
+
+func TestLocalMsgTypeManualDefinitions(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	recordMsgDef := singleFieldDefinition(FIT_MESG_NUM_RECORD, 5)
+	eventMsgDef := singleFieldDefinition(FIT_MESG_NUM_EVENT, 2)
+
+	fitFile := new(FitFile)
+	r.NoError(fitFile.AddData(recordMsgDef, 1))
+	// manually overwriting the local message type must be noticed
+	r.NoError(fitFile.AddMessage(eventMsgDef.ConstructLocalMessage(0)))
+	r.NoError(fitFile.AddData(eventMsgDef, 2))
+	r.NoError(fitFile.AddData(recordMsgDef, 3))
+	a.Equal([]uint8{0, 0, 1}, localTypesOf(fitFile))
+
+	// the stream encoder must make the same decisions
+	buffer := new(bytes.Buffer)
+	r.NoError(fitFile.Encode(buffer, encoding.BigEndian))
+
+	streamBuffer := new(bytes.Buffer)
+	streamEncoder, err := NewStreamEncoder(streamBuffer, encoding.BigEndian)
+	r.NoError(err)
+	r.NoError(streamEncoder.AddData(recordMsgDef, 1))
+	r.NoError(streamEncoder.AddMessage(eventMsgDef.ConstructLocalMessage(0)))
+	r.NoError(streamEncoder.AddData(eventMsgDef, 2))
+	r.NoError(streamEncoder.AddData(recordMsgDef, 3))
+	r.NoError(streamEncoder.Close())
+	a.Equal(buffer.Bytes(), streamBuffer.Bytes())
+}
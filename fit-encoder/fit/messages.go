@@ -44,6 +44,18 @@ func encodeMessageHeader(wr io.Writer, msgType uint8, msgSpecific bool, localMsg
 	return err
 }
 
+// encodeCompressedTimestampHeader encodes the header of a data message with
+// a compressed timestamp, which can only be used with local message types 0-3
+func encodeCompressedTimestampHeader(wr io.Writer, localMsgType uint8, timeOffset uint8) error {
+	if localMsgType > maxCompressedLocalMsgType {
+		return ErrInvalidCompressedLocalMsgType
+	}
+	messageHeader := uint8(1<<7) | localMsgType<<5 | (timeOffset & compressedTimeOffsetMask)
+
+	_, err := wr.Write([]byte{messageHeader})
+	return err
+}
+
 type messageHeader struct {
 	localMsgType uint8
 }
@@ -91,14 +103,18 @@ func (localDefMsg *localDefinitionMessage) Encode(wr io.Writer, endianness encod
 
 	// Encode all field defs
 	for _, fieldDef := range localDefMsg.def.FieldDefs {
-		fieldDef.Encode(mwr, endianness)
+		if err := fieldDef.Encode(mwr, endianness); err != nil {
+			return err
+		}
 	}
 
 	// Encode dev fields if we need to
 	if len(localDefMsg.def.DevFieldDefs) > 0 {
 		types.FitUint8(len(localDefMsg.def.DevFieldDefs)).Encode(mwr, endianness)
 		for _, devFieldDef := range localDefMsg.def.DevFieldDefs {
-			devFieldDef.Encode(mwr, endianness)
+			if err := devFieldDef.Encode(mwr, endianness); err != nil {
+				return err
+			}
 		}
 	}
 	return mwr.Error()
@@ -155,7 +171,78 @@ func convertValue(value interface{}, fitBaseType types.FitBaseType) (interface{}
 	}
 }
 
+// convertFieldValue converts the value of a field to its fit base type, converting
+// each of the values separately if the value is a slice for an array field
+func convertFieldValue(value interface{}, fieldDef *FieldDefinition) (interface{}, error) {
+	arrayValue := reflect.ValueOf(value)
+	if arrayValue.Kind() != reflect.Slice || fieldDef.BaseType == types.FIT_TYPE_STRING {
+		if fieldDef.ArrayLength() != 1 {
+			return nil, fmt.Errorf("%w: got a single value instead of %d", ErrArrayLengthMismatch, fieldDef.ArrayLength())
+		}
+		return convertValue(value, fieldDef.valueType())
+	}
+
+	if arrayValue.Len() != fieldDef.ArrayLength() {
+		return nil, fmt.Errorf("%w: got %d values instead of %d", ErrArrayLengthMismatch, arrayValue.Len(), fieldDef.ArrayLength())
+	}
+	fitType, ok := types.FitTypeMap[fieldDef.valueType()]
+	if !ok {
+		return nil, fmt.Errorf("unable to convert value to field with unknown base type %v", fieldDef.BaseType)
+	}
+
+	convertedValue := reflect.MakeSlice(reflect.SliceOf(fitType), arrayValue.Len(), arrayValue.Len())
+	for i := 0; i < arrayValue.Len(); i++ {
+		converted, err := convertValue(arrayValue.Index(i).Interface(), fieldDef.valueType())
+		if err != nil {
+			return nil, err
+		}
+		convertedValue.Index(i).Set(reflect.ValueOf(converted))
+	}
+	return convertedValue.Interface(), nil
+}
+
+// ConstructData constructs a data message with the values of all fields
+// and developer fields in the order of their definitions. Values are converted
+// to the respective fit base types, and array fields accept slices of values.
 func (localDefMsg *localDefinitionMessage) ConstructData(values ...interface{}) (*localDataMessage, error) {
+	convertedValues, err := localDefMsg.convertValues(values)
+	if err != nil {
+		return nil, err
+	}
+	return localDefMsg.constructDataImpl(convertedValues)
+}
+
+// ConstructCompressedData constructs a data message which will be encoded
+// with a compressed timestamp header instead of a timestamp field. The definition
+// must not contain the timestamp field and must use one of the local message types 0-3.
+// If the timestamp can't be compressed relative to the previous timestamp in the file,
+// the encoder automatically emits the message with a full timestamp field instead.
+func (localDefMsg *localDefinitionMessage) ConstructCompressedData(timestamp types.FitUint32, values ...interface{}) (*localDataMessage, error) {
+	if localDefMsg.localMsgType > maxCompressedLocalMsgType {
+		return nil, ErrInvalidCompressedLocalMsgType
+	}
+	for _, fieldDef := range localDefMsg.def.FieldDefs {
+		if fieldDef.DefNum == FIT_FIELD_NUM_TIMESTAMP {
+			return nil, ErrTimestampFieldDefined
+		}
+	}
+
+	convertedValues, err := localDefMsg.convertValues(values)
+	if err != nil {
+		return nil, err
+	}
+	localDataMsg, err := localDefMsg.constructDataImpl(convertedValues)
+	if err != nil {
+		return nil, err
+	}
+	localDataMsg.compressedTimestamp = true
+	localDataMsg.timestamp = timestamp
+	return localDataMsg, nil
+}
+
+// convertValues converts the values of all fields
+// and developer fields to their respective fit base types
+func (localDefMsg *localDefinitionMessage) convertValues(values []interface{}) ([]interface{}, error) {
 	if len(values) != len(localDefMsg.def.FieldDefs)+len(localDefMsg.def.DevFieldDefs) {
 		return nil, ErrFieldNumMismatch
 	}
@@ -163,7 +250,7 @@ func (localDefMsg *localDefinitionMessage) ConstructData(values ...interface{})
 	index := 0
 	convertedValues := make([]interface{}, len(values))
 	for _, fieldDef := range localDefMsg.def.FieldDefs {
-		converted, err := convertValue(values[index], fieldDef.BaseType)
+		converted, err := convertFieldValue(values[index], fieldDef)
 		if err != nil {
 			return nil, err
 		}
@@ -171,14 +258,14 @@ func (localDefMsg *localDefinitionMessage) ConstructData(values ...interface{})
 		index++
 	}
 	for _, devFieldDef := range localDefMsg.def.DevFieldDefs {
-		converted, err := convertValue(values[index], devFieldDef.Field.BaseType)
+		converted, err := convertFieldValue(values[index], devFieldDef.fieldDefinition())
 		if err != nil {
 			return nil, err
 		}
 		convertedValues[index] = converted
 		index++
 	}
-	return localDefMsg.constructDataImpl(convertedValues)
+	return convertedValues, nil
 }
 
 type localDataMessage struct {
@@ -186,6 +273,84 @@ type localDataMessage struct {
 	def       *DefinitionMessage
 	fields    []*Field
 	devFields []*DevField
+	// messages with a compressed timestamp header store
+	// their timestamp outside of the actual fields
+	compressedTimestamp bool
+	timestamp           types.FitUint32
+}
+
+// LocalMsgType returns the local message type of this data message
+func (localDataMsg *localDataMessage) LocalMsgType() uint8 {
+	return localDataMsg.localMsgType
+}
+
+// Definition returns the definition message describing this data message
+func (localDataMsg *localDataMessage) Definition() *DefinitionMessage {
+	return localDataMsg.def
+}
+
+// Fields returns the fields of this data message in their definition order
+func (localDataMsg *localDataMessage) Fields() []*Field {
+	return localDataMsg.fields
+}
+
+// DevFields returns the developer fields of this data message in their definition order
+func (localDataMsg *localDataMessage) DevFields() []*DevField {
+	return localDataMsg.devFields
+}
+
+// Value returns the value of the field with the given definition
+// number, or nil if the message doesn't contain such a field
+func (localDataMsg *localDataMessage) Value(defNum types.FitUint8) interface{} {
+	for _, field := range localDataMsg.fields {
+		if field.Def.DefNum == defNum {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// Timestamp returns the timestamp of the message, either from the
+// compressed timestamp header or from the timestamp field, if present
+func (localDataMsg *localDataMessage) Timestamp() (types.FitUint32, bool) {
+	if localDataMsg.compressedTimestamp {
+		return localDataMsg.timestamp, true
+	}
+	timestamp, ok := localDataMsg.Value(FIT_FIELD_NUM_TIMESTAMP).(types.FitUint32)
+	return timestamp, ok
+}
+
+// HasCompressedTimestamp returns true if the message
+// is encoded with a compressed timestamp header
+func (localDataMsg *localDataMessage) HasCompressedTimestamp() bool {
+	return localDataMsg.compressedTimestamp
+}
+
+// withFullTimestamp returns the definition and data messages which can be used
+// to encode a message with a compressed timestamp using a normal header instead
+func (localDataMsg *localDataMessage) withFullTimestamp() (*localDefinitionMessage, *localDataMessage) {
+	timestampFieldDef := &FieldDefinition{
+		DefNum:   FIT_FIELD_NUM_TIMESTAMP,
+		Size:     types.FIT_TYPE_UINT32_SIZE,
+		BaseType: types.FIT_TYPE_UINT32,
+	}
+
+	def := &DefinitionMessage{
+		GlobalMsgNum: localDataMsg.def.GlobalMsgNum,
+		FieldDefs:    append([]*FieldDefinition{timestampFieldDef}, localDataMsg.def.FieldDefs...),
+		DevFieldDefs: localDataMsg.def.DevFieldDefs,
+	}
+	localDefMsg := def.ConstructLocalMessage(localDataMsg.localMsgType)
+
+	return localDefMsg, &localDataMessage{
+		messageHeader: localDataMsg.messageHeader,
+		def:           def,
+		fields: append([]*Field{{
+			Def:   timestampFieldDef,
+			Value: localDataMsg.timestamp,
+		}}, localDataMsg.fields...),
+		devFields: localDataMsg.devFields,
+	}
 }
 
 func (localDataMsg *localDataMessage) Encode(wr io.Writer, endianness encoding.Endianness) error {
@@ -196,14 +361,24 @@ func (localDataMsg *localDataMessage) Encode(wr io.Writer, endianness encoding.E
 	mwr := maybeio.NewWriter(wr)
 
 	// Encode the single-byte message header
-	encodeMessageHeader(mwr, dataMsgType, false, localDataMsg.localMsgType)
+	if localDataMsg.compressedTimestamp {
+		if err := encodeCompressedTimestampHeader(mwr, localDataMsg.localMsgType, uint8(localDataMsg.timestamp)); err != nil {
+			return err
+		}
+	} else {
+		encodeMessageHeader(mwr, dataMsgType, false, localDataMsg.localMsgType)
+	}
 
 	// Encode fields
 	for _, field := range localDataMsg.fields {
-		field.Encode(mwr, endianness)
+		if err := field.Encode(mwr, endianness); err != nil {
+			return err
+		}
 	}
 	for _, devField := range localDataMsg.devFields {
-		devField.Encode(mwr, endianness)
+		if err := devField.Encode(mwr, endianness); err != nil {
+			return err
+		}
 	}
 
 	return mwr.Error()
@@ -184,6 +184,57 @@ func TestMessageEncoding(t *testing.T) {
 	)
 	r.Error(err, "construction of invalid local device info (invalid def field types)")
 
+	floatMsgDef := &DefinitionMessage{
+		GlobalMsgNum: 20,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   73,
+				Size:     types.FIT_TYPE_FLOAT32_SIZE,
+				BaseType: types.FIT_TYPE_FLOAT32,
+			},
+			{
+				DefNum:   0,
+				Size:     types.FIT_TYPE_UINT16Z_SIZE,
+				BaseType: types.FIT_TYPE_UINT16Z,
+			},
+		},
+	}
+	localFloatMsg, err := floatMsgDef.ConstructLocalMessage(3).ConstructData(3.5, 2)
+	r.NoError(err, "construction of message with float and z-type fields")
+	encodeAndValidate(a, localFloatMsg, encoding.LittleEndian, true, []byte{
+		0x03, 0x00, 0x00, 0x60, 0x40, 0x02, 0x00,
+	})
+
+	arrayMsgDef := &DefinitionMessage{
+		GlobalMsgNum: 20,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   8,
+				Size:     3 * types.FIT_TYPE_UINT8_SIZE,
+				BaseType: types.FIT_TYPE_UINT8,
+			},
+			{
+				DefNum:   1,
+				Size:     2 * types.FIT_TYPE_SINT16_SIZE,
+				BaseType: types.FIT_TYPE_SINT16,
+			},
+		},
+	}
+	localArrayMsgDef := arrayMsgDef.ConstructLocalMessage(2)
+	localArrayMsg, err := localArrayMsgDef.ConstructData([]int{1, 2, 3}, []types.FitSint16{-1, 256})
+	r.NoError(err, "construction of message with array fields")
+	a.Equal([]types.FitUint8{1, 2, 3}, localArrayMsg.Value(8))
+	encodeAndValidate(a, localArrayMsg, encoding.LittleEndian, true, []byte{
+		0x02, 0x01, 0x02, 0x03, 0xFF, 0xFF, 0x00, 0x01,
+	})
+
+	_, err = localArrayMsgDef.ConstructData([]int{1, 2}, []int{1, 2})
+	a.ErrorIs(err, ErrArrayLengthMismatch)
+	_, err = localArrayMsgDef.ConstructData(1, []int{1, 2})
+	a.ErrorIs(err, ErrArrayLengthMismatch)
+	_, err = localArrayMsgDef.ConstructData([]int{1, 2, 3}, []string{"a", "b"})
+	a.Error(err, "construction of array message with invalid value types")
+
 	fakeMsgDef := &DefinitionMessage{
 		GlobalMsgNum: 123,
 		FieldDefs: []*FieldDefinition{
@@ -0,0 +1,451 @@
+// Copyright 2021 Artem Mikheev
+
+// profilegen generates the fit/profile package from CSV exports
+// of the Types and Messages sheets of the FIT SDK Profile.xlsx
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// baseTypes maps the base type names used in the profile to their fit types
+var baseTypes = map[string]struct {
+	baseType string
+	goType   string
+}{
+	"enum":    {"types.FIT_TYPE_ENUM", "types.FitEnum"},
+	"sint8":   {"types.FIT_TYPE_SINT8", "types.FitSint8"},
+	"uint8":   {"types.FIT_TYPE_UINT8", "types.FitUint8"},
+	"sint16":  {"types.FIT_TYPE_SINT16", "types.FitSint16"},
+	"uint16":  {"types.FIT_TYPE_UINT16", "types.FitUint16"},
+	"sint32":  {"types.FIT_TYPE_SINT32", "types.FitSint32"},
+	"uint32":  {"types.FIT_TYPE_UINT32", "types.FitUint32"},
+	"string":  {"types.FIT_TYPE_STRING", "string"},
+	"float32": {"types.FIT_TYPE_FLOAT32", "types.FitFloat32"},
+	"float64": {"types.FIT_TYPE_FLOAT64", "types.FitFloat64"},
+	"uint8z":  {"types.FIT_TYPE_UINT8Z", "types.FitUint8z"},
+	"uint16z": {"types.FIT_TYPE_UINT16Z", "types.FitUint16z"},
+	"uint32z": {"types.FIT_TYPE_UINT32Z", "types.FitUint32z"},
+	"byte":    {"types.FIT_TYPE_BYTE", "types.FitByte"},
+	"sint64":  {"types.FIT_TYPE_SINT64", "types.FitSint64"},
+	"uint64":  {"types.FIT_TYPE_UINT64", "types.FitUint64"},
+	"uint64z": {"types.FIT_TYPE_UINT64Z", "types.FitUint64z"},
+}
+
+// typeOverrides are profile types which already have a go
+// representation, so no constants are generated for them
+var typeOverrides = map[string]string{
+	"fit_base_type": "types.FitBaseType",
+}
+
+type profileValue struct {
+	Const   string
+	Value   string
+	Comment string
+}
+
+type profileType struct {
+	Name     string
+	BaseType string
+	GoType   string
+	Comment  string
+	Values   []*profileValue
+}
+
+type profileField struct {
+	Var      string
+	GoName   string
+	GoType   string
+	Num      string
+	Name     string
+	Type     string
+	BaseType string
+	Array    bool
+	Scale    string
+	Offset   string
+	Units    string
+	// GoUnits are the units of the value stored in the message struct
+	GoUnits string
+	Comment string
+}
+
+type profileMessage struct {
+	GoName  string
+	Num     string
+	Name    string
+	Comment string
+	Fields  []*profileField
+}
+
+// camelCase converts snake_case profile names to exported go identifiers
+func camelCase(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+// constName constructs the name of the constant for a profile type value
+func constName(typeName, valueName string) string {
+	return "FIT_" + strings.ToUpper(typeName+"_"+valueName)
+}
+
+// readSheet reads a csv export of a profile sheet, returning
+// the rows as maps from the column names to the values
+func readSheet(path string) ([]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rd := csv.NewReader(file)
+	rd.FieldsPerRecord = -1
+	header, err := rd.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := rd.Read()
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseTypes(path string) ([]*profileType, map[string]*profileType, error) {
+	rows, err := readSheet(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var typeList []*profileType
+	typeMap := make(map[string]*profileType)
+	var current *profileType
+	for _, row := range rows {
+		if name := row["Type Name"]; name != "" {
+			base, ok := baseTypes[row["Base Type"]]
+			if !ok {
+				return nil, nil, fmt.Errorf("type %s has unknown base type %q", name, row["Base Type"])
+			}
+			current = &profileType{
+				Name:     name,
+				BaseType: row["Base Type"],
+				GoType:   base.goType,
+				Comment:  row["Comment"],
+			}
+			typeList = append(typeList, current)
+			typeMap[name] = current
+			continue
+		}
+
+		if row["Value Name"] == "" {
+			continue
+		}
+		if current == nil {
+			return nil, nil, fmt.Errorf("value %s defined before any type", row["Value Name"])
+		}
+		if _, err := strconv.ParseInt(row["Value"], 0, 64); err != nil {
+			return nil, nil, fmt.Errorf("value %s of type %s: %w", row["Value Name"], current.Name, err)
+		}
+		current.Values = append(current.Values, &profileValue{
+			Const:   constName(current.Name, row["Value Name"]),
+			Value:   row["Value"],
+			Comment: row["Comment"],
+		})
+	}
+	return typeList, typeMap, nil
+}
+
+func parseMessages(path string, typeMap map[string]*profileType) ([]*profileMessage, error) {
+	rows, err := readSheet(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mesgNums := make(map[string]string)
+	if mesgNumType, ok := typeMap["mesg_num"]; ok {
+		for _, value := range mesgNumType.Values {
+			mesgNums[value.Const] = value.Value
+		}
+	}
+
+	var messages []*profileMessage
+	var current *profileMessage
+	for _, row := range rows {
+		if name := row["Message Name"]; name != "" {
+			num := constName("mesg_num", name)
+			if _, ok := mesgNums[num]; !ok {
+				return nil, fmt.Errorf("message %s is missing from the mesg_num type", name)
+			}
+			current = &profileMessage{
+				GoName:  camelCase(name),
+				Num:     num,
+				Name:    name,
+				Comment: row["Comment"],
+			}
+			messages = append(messages, current)
+			continue
+		}
+
+		// subfields and dynamic fields have no field number of their own
+		if row["Field Def #"] == "" || row["Field Name"] == "" {
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("field %s defined before any message", row["Field Name"])
+		}
+		field, err := parseField(current, row, typeMap)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of message %s: %w", row["Field Name"], current.Name, err)
+		}
+		current.Fields = append(current.Fields, field)
+	}
+	return messages, nil
+}
+
+func parseField(message *profileMessage, row map[string]string, typeMap map[string]*profileType) (*profileField, error) {
+	if _, err := strconv.ParseUint(row["Field Def #"], 10, 8); err != nil {
+		return nil, err
+	}
+
+	field := &profileField{
+		Var:     message.GoName + camelCase(row["Field Name"]),
+		GoName:  camelCase(row["Field Name"]),
+		Num:     row["Field Def #"],
+		Name:    row["Field Name"],
+		Type:    row["Field Type"],
+		Array:   row["Array"] != "",
+		Scale:   "1",
+		Offset:  "0",
+		Units:   row["Units"],
+		Comment: row["Comment"],
+	}
+
+	baseTypeName := field.Type
+	if profileType, ok := typeMap[field.Type]; ok {
+		baseTypeName = profileType.BaseType
+	}
+	base, ok := baseTypes[baseTypeName]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", field.Type)
+	}
+	field.BaseType = base.baseType
+	field.GoType = base.goType
+	if override, ok := typeOverrides[field.Type]; ok {
+		field.GoType = override
+	}
+
+	// components have a scale for each of the components,
+	// only single scales actually apply to the field itself
+	if scale := row["Scale"]; scale != "" && !strings.Contains(scale, ",") {
+		if _, err := strconv.ParseFloat(scale, 64); err != nil {
+			return nil, err
+		}
+		field.Scale = scale
+	}
+	if offset := row["Offset"]; offset != "" && !strings.Contains(offset, ",") {
+		if _, err := strconv.ParseFloat(offset, 64); err != nil {
+			return nil, err
+		}
+		field.Offset = offset
+	}
+
+	field.GoUnits = field.Units
+	switch {
+	case field.Type == "date_time":
+		field.GoType = "time.Time"
+		field.GoUnits = ""
+	case field.Units == "semicircles":
+		field.GoType = "float64"
+		field.GoUnits = "degrees"
+	case field.Scale != "1" || field.Offset != "0":
+		field.GoType = "float64"
+	}
+	// string arrays are encoded as a single string
+	if field.GoType == "string" {
+		field.Array = false
+	}
+	if field.Array {
+		field.GoType = "[]" + field.GoType
+	}
+	return field, nil
+}
+
+// checkIdentifiers makes sure that none of the generated identifiers collide
+func checkIdentifiers(typeList []*profileType, messages []*profileMessage) error {
+	seen := make(map[string]bool)
+	add := func(ident string) error {
+		if seen[ident] {
+			return fmt.Errorf("duplicate identifier %s", ident)
+		}
+		seen[ident] = true
+		return nil
+	}
+
+	for _, profileType := range typeList {
+		for _, value := range profileType.Values {
+			if err := add(value.Const); err != nil {
+				return err
+			}
+		}
+	}
+	for _, message := range messages {
+		if err := add(message.GoName); err != nil {
+			return err
+		}
+		if err := add(message.GoName + "Info"); err != nil {
+			return err
+		}
+		for _, field := range message.Fields {
+			if err := add(field.Var); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var profileTemplate = template.Must(template.New("profile").Parse(`// Code generated by profilegen from {{.TypesPath}} and {{.MessagesPath}}; DO NOT EDIT.
+
+package profile
+
+import (
+	"time"
+
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+{{range .Types}}{{if .Values}}
+// {{.Name}}{{if .Comment}}: {{.Comment}}{{end}}
+const (
+{{- $goType := .GoType}}
+{{- range .Values}}
+	{{.Const}} {{$goType}} = {{.Value}}{{if .Comment}} // {{.Comment}}{{end}}
+{{- end}}
+)
+{{end}}{{end}}
+{{- range .Messages}}
+// {{.GoName}}Info describes the {{.Name}} message{{if .Comment}}. {{.Comment}}{{end}}
+var {{.GoName}}Info = &MessageInfo{
+	Num:  {{.Num}},
+	Name: "{{.Name}}",
+	Fields: []*FieldInfo{
+{{- range .Fields}}
+		{{.Var}},
+{{- end}}
+	},
+}
+
+// fields of the {{.Name}} message
+var (
+{{- $mesg := .}}
+{{- range .Fields}}
+	{{.Var}} = &FieldInfo{
+		MesgNum:  {{$mesg.Num}},
+		Num:      {{.Num}},
+		Name:     "{{.Name}}",
+		Type:     "{{.Type}}",
+		BaseType: {{.BaseType}},
+		Array:    {{.Array}},
+		Scale:    {{.Scale}},
+		Offset:   {{.Offset}},
+		Units:    "{{.Units}}",
+	}
+{{- end}}
+)
+
+// {{.GoName}} is the {{.Name}} message{{if .Comment}}. {{.Comment}}{{end}}
+type {{.GoName}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{.GoType}}{{if .GoUnits}} // {{.GoUnits}}{{end}}
+{{- end}}
+}
+
+func ({{.GoName}}) Info() *MessageInfo {
+	return {{.GoName}}Info
+}
+
+func (m {{.GoName}}) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+{{- range .Fields}}
+	case {{.Num}}:
+		return m.{{.GoName}}
+{{- end}}
+	}
+	return nil
+}
+{{end}}
+var messages = map[types.FitUint16]*MessageInfo{
+{{- range .Messages}}
+	{{.Num}}: {{.GoName}}Info,
+{{- end}}
+}
+`))
+
+func main() {
+	typesPath := flag.String("types", "spec/Types.csv", "csv export of the Types sheet")
+	messagesPath := flag.String("messages", "spec/Messages.csv", "csv export of the Messages sheet")
+	outPath := flag.String("out", "profile_gen.go", "generated go file")
+	flag.Parse()
+
+	typeList, typeMap, err := parseTypes(*typesPath)
+	if err != nil {
+		log.Fatalf("parsing types: %v", err)
+	}
+	for name := range typeOverrides {
+		if profileType, ok := typeMap[name]; ok {
+			profileType.Values = nil
+		}
+	}
+
+	messages, err := parseMessages(*messagesPath, typeMap)
+	if err != nil {
+		log.Fatalf("parsing messages: %v", err)
+	}
+	if err := checkIdentifiers(typeList, messages); err != nil {
+		log.Fatal(err)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := profileTemplate.Execute(buffer, map[string]interface{}{
+		"TypesPath":    *typesPath,
+		"MessagesPath": *messagesPath,
+		"Types":        typeList,
+		"Messages":     messages,
+	}); err != nil {
+		log.Fatalf("executing template: %v", err)
+	}
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %v", err)
+	}
+	if err := os.WriteFile(*outPath, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 Artem Mikheev
+
+// Package profile contains the messages and fields of the FIT global profile,
+// generated from CSV exports of the Types and Messages sheets of the FIT SDK
+// Profile.xlsx. Only the messages placed into spec/Messages.csv are generated,
+// so new messages can be supported by exporting their rows and regenerating.
+//
+// Message structs store field values in their actual units: scale and offset
+// are applied when the values are encoded, positions are stored in degrees
+// instead of semicircles and date_time fields are stored as time.Time.
+package profile
+
+//go:generate go run ./internal/profilegen -types spec/Types.csv -messages spec/Messages.csv -out profile_gen.go
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+var (
+	ErrUnknownField    = errors.New("field is not a part of the message profile")
+	ErrValueOutOfRange = errors.New("field value is out of range of the field base type")
+	ErrInvalidSize     = errors.New("string or array field value has invalid size")
+)
+
+// FitEpoch is the reference point of all fit timestamps
+var FitEpoch = time.Date(1989, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// semicirclesPerDegree is used to convert positions to and from semicircles
+const semicirclesPerDegree = float64(int64(1)<<31) / 180.0
+
+// EncodeTime converts t to a fit date_time value
+func EncodeTime(t time.Time) types.FitUint32 {
+	return types.FitUint32(t.Unix() - FitEpoch.Unix())
+}
+
+// DecodeTime converts a fit date_time value to UTC time
+func DecodeTime(timestamp types.FitUint32) time.Time {
+	return FitEpoch.Add(time.Duration(timestamp) * time.Second)
+}
+
+// FieldInfo describes a single field of a message in the global profile
+type FieldInfo struct {
+	MesgNum types.FitUint16
+	Num     types.FitUint8
+	Name    string
+	// Type is the name of the profile type, which is either a
+	// base type name or one of the types from the Types sheet
+	Type     string
+	BaseType types.FitBaseType
+	Array    bool
+	Scale    float64
+	Offset   float64
+	Units    string
+}
+
+// MessageInfo describes a message of the global profile
+type MessageInfo struct {
+	Num    types.FitUint16
+	Name   string
+	Fields []*FieldInfo
+}
+
+// Field returns the field of the message with the given field number, or nil
+func (info *MessageInfo) Field(num types.FitUint8) *FieldInfo {
+	for _, field := range info.Fields {
+		if field.Num == num {
+			return field
+		}
+	}
+	return nil
+}
+
+// Lookup returns the profile of the message with the given
+// global message number, or nil if it isn't part of the profile
+func Lookup(mesgNum types.FitUint16) *MessageInfo {
+	return messages[mesgNum]
+}
+
+// Message is implemented by all of the generated message structs
+type Message interface {
+	Info() *MessageInfo
+	// value returns the value of the field as stored in the message struct
+	value(fieldNum types.FitUint8) interface{}
+}
+
+// RawValue converts a value stored in a message struct to the value which
+// should be encoded, applying the scale and offset of the field
+func (field *FieldInfo) RawValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return EncodeTime(v), nil
+	case float64:
+		if field.Units == "semicircles" {
+			v *= semicirclesPerDegree
+		} else {
+			v = (v + field.Offset) * field.Scale
+		}
+		return field.convertNumber(v)
+	default:
+		return value, nil
+	}
+}
+
+// Value converts a raw decoded value to the value stored
+// in message structs, which is the inverse of RawValue
+func (field *FieldInfo) Value(raw interface{}) (interface{}, error) {
+	if field.Type == "date_time" {
+		timestamp, ok := raw.(types.FitUint32)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", types.ErrFitBaseTypeMismatch, field.Name)
+		}
+		return DecodeTime(timestamp), nil
+	}
+
+	if field.Units != "semicircles" && field.Scale == 1 && field.Offset == 0 {
+		return raw, nil
+	}
+
+	rawValue := reflect.ValueOf(raw)
+	if !rawValue.CanConvert(reflect.TypeOf(float64(0))) {
+		return nil, fmt.Errorf("%w: %s", types.ErrFitBaseTypeMismatch, field.Name)
+	}
+	v := rawValue.Convert(reflect.TypeOf(float64(0))).Float()
+	if field.Units == "semicircles" {
+		return v / semicirclesPerDegree, nil
+	}
+	return v/field.Scale - field.Offset, nil
+}
+
+// convertNumber rounds v and converts it to the fit type of the field
+func (field *FieldInfo) convertNumber(v float64) (interface{}, error) {
+	fitType, ok := types.FitTypeMap[field.BaseType]
+	if !ok {
+		return nil, types.ErrUnknownFitType
+	}
+
+	value := reflect.New(fitType).Elem()
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(v)
+		return value.Interface(), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rounded := math.Round(v)
+		if rounded < math.MinInt64 || rounded > math.MaxInt64 || value.OverflowInt(int64(rounded)) {
+			return nil, fmt.Errorf("%w: %s = %v", ErrValueOutOfRange, field.Name, v)
+		}
+		value.SetInt(int64(rounded))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		rounded := math.Round(v)
+		if rounded < 0 || rounded > math.MaxUint64 || value.OverflowUint(uint64(rounded)) {
+			return nil, fmt.Errorf("%w: %s = %v", ErrValueOutOfRange, field.Name, v)
+		}
+		value.SetUint(uint64(rounded))
+	default:
+		return nil, fmt.Errorf("%w: %s can't hold a number", types.ErrFitBaseTypeMismatch, field.Name)
+	}
+	return value.Interface(), nil
+}
+
+// size returns the size of the field definition needed to store value
+func (field *FieldInfo) size(value interface{}) (types.FitUint8, error) {
+	if field.BaseType == types.FIT_TYPE_STRING {
+		str, _ := value.(string)
+		// strings are always terminated with a null byte
+		if len(str)+1 > math.MaxUint8 {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidSize, field.Name)
+		}
+		return types.FitUint8(len(str) + 1), nil
+	}
+
+	baseSize, ok := types.FitTypeSize[field.BaseType]
+	if !ok {
+		return 0, types.ErrUnknownFitType
+	}
+	if !field.Array {
+		return baseSize, nil
+	}
+
+	length := reflect.ValueOf(value).Len()
+	if length == 0 || length*int(baseSize) > math.MaxUint8 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSize, field.Name)
+	}
+	return types.FitUint8(length * int(baseSize)), nil
+}
+
+// Definition constructs a definition message containing the given fields of msg in
+// the same order. The sizes of string and array fields are taken from the values in msg,
+// so messages with longer strings or arrays need their own definition.
+func Definition(msg Message, fields ...*FieldInfo) (*fit.DefinitionMessage, error) {
+	info := msg.Info()
+	def := &fit.DefinitionMessage{
+		GlobalMsgNum: info.Num,
+	}
+	for _, field := range fields {
+		if field.MesgNum != info.Num {
+			return nil, fmt.Errorf("%w: %s is not a field of %s", ErrUnknownField, field.Name, info.Name)
+		}
+		size, err := field.size(msg.value(field.Num))
+		if err != nil {
+			return nil, err
+		}
+		def.FieldDefs = append(def.FieldDefs, &fit.FieldDefinition{
+			DefNum:   field.Num,
+			Size:     size,
+			BaseType: field.BaseType,
+		})
+	}
+	return def, nil
+}
+
+// Values returns the encoded values of all regular fields defined by def, taken from msg,
+// which can be passed to ConstructData after appending the values of the developer fields
+func Values(msg Message, def *fit.DefinitionMessage) ([]interface{}, error) {
+	info := msg.Info()
+	if def.GlobalMsgNum != info.Num {
+		return nil, fmt.Errorf("%w: definition of message %d used for %s", ErrUnknownField, def.GlobalMsgNum, info.Name)
+	}
+
+	values := make([]interface{}, 0, len(def.FieldDefs))
+	for _, fieldDef := range def.FieldDefs {
+		field := info.Field(fieldDef.DefNum)
+		if field == nil {
+			return nil, fmt.Errorf("%w: field %d of %s", ErrUnknownField, fieldDef.DefNum, info.Name)
+		}
+		value, err := field.RawValue(msg.value(field.Num))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
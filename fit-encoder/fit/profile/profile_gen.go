@@ -0,0 +1,2899 @@
+// Code generated by profilegen from spec/Types.csv and spec/Messages.csv; DO NOT EDIT.
+
+package profile
+
+import (
+	"time"
+
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+// file
+const (
+	FIT_FILE_DEVICE           types.FitEnum = 1  // Read only, single file. Must be in root directory.
+	FIT_FILE_SETTINGS         types.FitEnum = 2  // Read/write, single file. Directory=Settings
+	FIT_FILE_SPORT            types.FitEnum = 3  // Read/write, multiple files, file number = sport type. Directory=Sports
+	FIT_FILE_ACTIVITY         types.FitEnum = 4  // Read/erase, multiple files. Directory=Activities
+	FIT_FILE_WORKOUT          types.FitEnum = 5  // Read/write/erase, multiple files. Directory=Workouts
+	FIT_FILE_COURSE           types.FitEnum = 6  // Read/write/erase, multiple files. Directory=Courses
+	FIT_FILE_SCHEDULES        types.FitEnum = 7  // Read/write, single file. Directory=Schedules
+	FIT_FILE_WEIGHT           types.FitEnum = 9  // Read only, single file. Circular buffer. All message definitions at start of file. Directory=Weight
+	FIT_FILE_TOTALS           types.FitEnum = 10 // Read only, single file. Directory=Totals
+	FIT_FILE_GOALS            types.FitEnum = 11 // Read/write, single file. Directory=Goals
+	FIT_FILE_BLOOD_PRESSURE   types.FitEnum = 14 // Read only. Directory=Blood Pressure
+	FIT_FILE_MONITORING_A     types.FitEnum = 15 // Read only. Directory=Monitoring. File number=sub type.
+	FIT_FILE_ACTIVITY_SUMMARY types.FitEnum = 20 // Read/erase, multiple files. Directory=Activities
+	FIT_FILE_MONITORING_DAILY types.FitEnum = 28
+	FIT_FILE_MONITORING_B     types.FitEnum = 32 // Read only. Directory=Monitoring. File number=identifier
+	FIT_FILE_SEGMENT          types.FitEnum = 34 // Read/write/erase. Multiple Files.  Directory=Segments
+	FIT_FILE_SEGMENT_LIST     types.FitEnum = 35 // Read/write/erase. Single File.  Directory=Segments
+)
+
+// mesg_num
+const (
+	FIT_MESG_NUM_FILE_ID            types.FitUint16 = 0
+	FIT_MESG_NUM_CAPABILITIES       types.FitUint16 = 1
+	FIT_MESG_NUM_DEVICE_SETTINGS    types.FitUint16 = 2
+	FIT_MESG_NUM_USER_PROFILE       types.FitUint16 = 3
+	FIT_MESG_NUM_HRM_PROFILE        types.FitUint16 = 4
+	FIT_MESG_NUM_SDM_PROFILE        types.FitUint16 = 5
+	FIT_MESG_NUM_BIKE_PROFILE       types.FitUint16 = 6
+	FIT_MESG_NUM_ZONES_TARGET       types.FitUint16 = 7
+	FIT_MESG_NUM_HR_ZONE            types.FitUint16 = 8
+	FIT_MESG_NUM_POWER_ZONE         types.FitUint16 = 9
+	FIT_MESG_NUM_MET_ZONE           types.FitUint16 = 10
+	FIT_MESG_NUM_SPORT              types.FitUint16 = 12
+	FIT_MESG_NUM_GOAL               types.FitUint16 = 15
+	FIT_MESG_NUM_SESSION            types.FitUint16 = 18
+	FIT_MESG_NUM_LAP                types.FitUint16 = 19
+	FIT_MESG_NUM_RECORD             types.FitUint16 = 20
+	FIT_MESG_NUM_EVENT              types.FitUint16 = 21
+	FIT_MESG_NUM_DEVICE_INFO        types.FitUint16 = 23
+	FIT_MESG_NUM_WORKOUT            types.FitUint16 = 26
+	FIT_MESG_NUM_WORKOUT_STEP       types.FitUint16 = 27
+	FIT_MESG_NUM_SCHEDULE           types.FitUint16 = 28
+	FIT_MESG_NUM_WEIGHT_SCALE       types.FitUint16 = 30
+	FIT_MESG_NUM_COURSE             types.FitUint16 = 31
+	FIT_MESG_NUM_COURSE_POINT       types.FitUint16 = 32
+	FIT_MESG_NUM_TOTALS             types.FitUint16 = 33
+	FIT_MESG_NUM_ACTIVITY           types.FitUint16 = 34
+	FIT_MESG_NUM_SOFTWARE           types.FitUint16 = 35
+	FIT_MESG_NUM_FILE_CAPABILITIES  types.FitUint16 = 37
+	FIT_MESG_NUM_MESG_CAPABILITIES  types.FitUint16 = 38
+	FIT_MESG_NUM_FIELD_CAPABILITIES types.FitUint16 = 39
+	FIT_MESG_NUM_FILE_CREATOR       types.FitUint16 = 49
+	FIT_MESG_NUM_BLOOD_PRESSURE     types.FitUint16 = 51
+	FIT_MESG_NUM_SPEED_ZONE         types.FitUint16 = 53
+	FIT_MESG_NUM_MONITORING         types.FitUint16 = 55
+	FIT_MESG_NUM_TRAINING_FILE      types.FitUint16 = 72
+	FIT_MESG_NUM_HRV                types.FitUint16 = 78
+	FIT_MESG_NUM_LENGTH             types.FitUint16 = 101
+	FIT_MESG_NUM_FIELD_DESCRIPTION  types.FitUint16 = 206
+	FIT_MESG_NUM_DEVELOPER_DATA_ID  types.FitUint16 = 207
+)
+
+// manufacturer
+const (
+	FIT_MANUFACTURER_GARMIN         types.FitUint16 = 1
+	FIT_MANUFACTURER_ZEPHYR         types.FitUint16 = 3
+	FIT_MANUFACTURER_DAYTON         types.FitUint16 = 4
+	FIT_MANUFACTURER_IDT            types.FitUint16 = 5
+	FIT_MANUFACTURER_SRM            types.FitUint16 = 6
+	FIT_MANUFACTURER_QUARQ          types.FitUint16 = 7
+	FIT_MANUFACTURER_IBIKE          types.FitUint16 = 8
+	FIT_MANUFACTURER_SARIS          types.FitUint16 = 9
+	FIT_MANUFACTURER_SPARK_HK       types.FitUint16 = 10
+	FIT_MANUFACTURER_TANITA         types.FitUint16 = 11
+	FIT_MANUFACTURER_ECHOWELL       types.FitUint16 = 12
+	FIT_MANUFACTURER_DYNASTREAM_OEM types.FitUint16 = 13
+	FIT_MANUFACTURER_NAUTILUS       types.FitUint16 = 14
+	FIT_MANUFACTURER_DYNASTREAM     types.FitUint16 = 15
+	FIT_MANUFACTURER_TIMEX          types.FitUint16 = 16
+	FIT_MANUFACTURER_DEVELOPMENT    types.FitUint16 = 255
+	FIT_MANUFACTURER_STRAVA         types.FitUint16 = 265
+)
+
+// message_index
+const (
+	FIT_MESSAGE_INDEX_SELECTED types.FitUint16 = 0x8000 // message is selected if set
+	FIT_MESSAGE_INDEX_RESERVED types.FitUint16 = 0x7000 // reserved (default 0)
+	FIT_MESSAGE_INDEX_MASK     types.FitUint16 = 0x0FFF // index
+)
+
+// device_index
+const (
+	FIT_DEVICE_INDEX_CREATOR types.FitUint8 = 0 // Creator of the file is always device index 0.
+)
+
+// battery_status
+const (
+	FIT_BATTERY_STATUS_NEW      types.FitUint8 = 1
+	FIT_BATTERY_STATUS_GOOD     types.FitUint8 = 2
+	FIT_BATTERY_STATUS_OK       types.FitUint8 = 3
+	FIT_BATTERY_STATUS_LOW      types.FitUint8 = 4
+	FIT_BATTERY_STATUS_CRITICAL types.FitUint8 = 5
+	FIT_BATTERY_STATUS_CHARGING types.FitUint8 = 6
+	FIT_BATTERY_STATUS_UNKNOWN  types.FitUint8 = 7
+)
+
+// event
+const (
+	FIT_EVENT_TIMER                   types.FitEnum = 0  // Group 0.  Start / stop_all
+	FIT_EVENT_WORKOUT                 types.FitEnum = 3  // start / stop
+	FIT_EVENT_WORKOUT_STEP            types.FitEnum = 4  // Start at beginning of workout.  Stop at end of each step.
+	FIT_EVENT_POWER_DOWN              types.FitEnum = 5  // stop_all group 0
+	FIT_EVENT_POWER_UP                types.FitEnum = 6  // stop_all group 0
+	FIT_EVENT_OFF_COURSE              types.FitEnum = 7  // start / stop group 0
+	FIT_EVENT_SESSION                 types.FitEnum = 8  // Stop at end of each session.
+	FIT_EVENT_LAP                     types.FitEnum = 9  // Stop at end of each lap.
+	FIT_EVENT_COURSE_POINT            types.FitEnum = 10 // marker
+	FIT_EVENT_BATTERY                 types.FitEnum = 11 // marker
+	FIT_EVENT_VIRTUAL_PARTNER_PACE    types.FitEnum = 12 // Group 1. Start at beginning of activity if VP enabled, when VP pace is changed during activity or VP enabled mid activity.  stop_disable when VP disabled.
+	FIT_EVENT_HR_HIGH_ALERT           types.FitEnum = 13 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_HR_LOW_ALERT            types.FitEnum = 14 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_SPEED_HIGH_ALERT        types.FitEnum = 15 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_SPEED_LOW_ALERT         types.FitEnum = 16 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_CAD_HIGH_ALERT          types.FitEnum = 17 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_CAD_LOW_ALERT           types.FitEnum = 18 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_POWER_HIGH_ALERT        types.FitEnum = 19 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_POWER_LOW_ALERT         types.FitEnum = 20 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_RECOVERY_HR             types.FitEnum = 21 // marker
+	FIT_EVENT_BATTERY_LOW             types.FitEnum = 22 // marker
+	FIT_EVENT_TIME_DURATION_ALERT     types.FitEnum = 23 // Group 1.  Start if enabled mid activity (not required at start of activity). Stop when duration is reached.  stop_disable if disabled.
+	FIT_EVENT_DISTANCE_DURATION_ALERT types.FitEnum = 24 // Group 1.  Start if enabled mid activity (not required at start of activity). Stop when duration is reached.  stop_disable if disabled.
+	FIT_EVENT_CALORIE_DURATION_ALERT  types.FitEnum = 25 // Group 1.  Start if enabled mid activity (not required at start of activity). Stop when duration is reached.  stop_disable if disabled.
+	FIT_EVENT_ACTIVITY                types.FitEnum = 26 // Group 1..  Stop at end of activity.
+	FIT_EVENT_FITNESS_EQUIPMENT       types.FitEnum = 27 // marker
+	FIT_EVENT_LENGTH                  types.FitEnum = 28 // Stop at end of each length.
+	FIT_EVENT_USER_MARKER             types.FitEnum = 32 // marker
+	FIT_EVENT_SPORT_POINT             types.FitEnum = 33 // marker
+	FIT_EVENT_CALIBRATION             types.FitEnum = 36 // start/stop/marker
+	FIT_EVENT_FRONT_GEAR_CHANGE       types.FitEnum = 42 // marker
+	FIT_EVENT_REAR_GEAR_CHANGE        types.FitEnum = 43 // marker
+	FIT_EVENT_RIDER_POSITION_CHANGE   types.FitEnum = 44 // marker
+	FIT_EVENT_ELEV_HIGH_ALERT         types.FitEnum = 45 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_ELEV_LOW_ALERT          types.FitEnum = 46 // Group 0.  Start / stop when in alert condition.
+	FIT_EVENT_COMM_TIMEOUT            types.FitEnum = 47 // marker
+)
+
+// event_type
+const (
+	FIT_EVENT_TYPE_START                   types.FitEnum = 0
+	FIT_EVENT_TYPE_STOP                    types.FitEnum = 1
+	FIT_EVENT_TYPE_CONSECUTIVE_DEPRECIATED types.FitEnum = 2
+	FIT_EVENT_TYPE_MARKER                  types.FitEnum = 3
+	FIT_EVENT_TYPE_STOP_ALL                types.FitEnum = 4
+	FIT_EVENT_TYPE_BEGIN_DEPRECIATED       types.FitEnum = 5
+	FIT_EVENT_TYPE_END_DEPRECIATED         types.FitEnum = 6
+	FIT_EVENT_TYPE_END_ALL_DEPRECIATED     types.FitEnum = 7
+	FIT_EVENT_TYPE_STOP_DISABLE            types.FitEnum = 8
+	FIT_EVENT_TYPE_STOP_DISABLE_ALL        types.FitEnum = 9
+)
+
+// timer_trigger: timer event data
+const (
+	FIT_TIMER_TRIGGER_MANUAL            types.FitEnum = 0
+	FIT_TIMER_TRIGGER_AUTO              types.FitEnum = 1
+	FIT_TIMER_TRIGGER_FITNESS_EQUIPMENT types.FitEnum = 2
+)
+
+// activity
+const (
+	FIT_ACTIVITY_MANUAL           types.FitEnum = 0
+	FIT_ACTIVITY_AUTO_MULTI_SPORT types.FitEnum = 1
+)
+
+// sport
+const (
+	FIT_SPORT_GENERIC              types.FitEnum = 0
+	FIT_SPORT_RUNNING              types.FitEnum = 1
+	FIT_SPORT_CYCLING              types.FitEnum = 2
+	FIT_SPORT_TRANSITION           types.FitEnum = 3 // Mulitsport transition
+	FIT_SPORT_FITNESS_EQUIPMENT    types.FitEnum = 4
+	FIT_SPORT_SWIMMING             types.FitEnum = 5
+	FIT_SPORT_BASKETBALL           types.FitEnum = 6
+	FIT_SPORT_SOCCER               types.FitEnum = 7
+	FIT_SPORT_TENNIS               types.FitEnum = 8
+	FIT_SPORT_AMERICAN_FOOTBALL    types.FitEnum = 9
+	FIT_SPORT_TRAINING             types.FitEnum = 10
+	FIT_SPORT_WALKING              types.FitEnum = 11
+	FIT_SPORT_CROSS_COUNTRY_SKIING types.FitEnum = 12
+	FIT_SPORT_ALPINE_SKIING        types.FitEnum = 13
+	FIT_SPORT_SNOWBOARDING         types.FitEnum = 14
+	FIT_SPORT_ROWING               types.FitEnum = 15
+	FIT_SPORT_MOUNTAINEERING       types.FitEnum = 16
+	FIT_SPORT_HIKING               types.FitEnum = 17
+	FIT_SPORT_MULTISPORT           types.FitEnum = 18
+	FIT_SPORT_PADDLING             types.FitEnum = 19
+	FIT_SPORT_ALL                  types.FitEnum = 254 // All is for goals only to include all sports.
+)
+
+// sub_sport
+const (
+	FIT_SUB_SPORT_GENERIC        types.FitEnum = 0
+	FIT_SUB_SPORT_TREADMILL      types.FitEnum = 1  // Run/Fitness Equipment
+	FIT_SUB_SPORT_STREET         types.FitEnum = 2  // Run
+	FIT_SUB_SPORT_TRAIL          types.FitEnum = 3  // Run
+	FIT_SUB_SPORT_TRACK          types.FitEnum = 4  // Run
+	FIT_SUB_SPORT_SPIN           types.FitEnum = 5  // Cycling
+	FIT_SUB_SPORT_INDOOR_CYCLING types.FitEnum = 6  // Cycling/Fitness Equipment
+	FIT_SUB_SPORT_ROAD           types.FitEnum = 7  // Cycling
+	FIT_SUB_SPORT_MOUNTAIN       types.FitEnum = 8  // Cycling
+	FIT_SUB_SPORT_DOWNHILL       types.FitEnum = 9  // Cycling
+	FIT_SUB_SPORT_RECUMBENT      types.FitEnum = 10 // Cycling
+	FIT_SUB_SPORT_CYCLOCROSS     types.FitEnum = 11 // Cycling
+	FIT_SUB_SPORT_HAND_CYCLING   types.FitEnum = 12 // Cycling
+	FIT_SUB_SPORT_TRACK_CYCLING  types.FitEnum = 13 // Cycling
+	FIT_SUB_SPORT_INDOOR_ROWING  types.FitEnum = 14 // Fitness Equipment
+	FIT_SUB_SPORT_ELLIPTICAL     types.FitEnum = 15 // Fitness Equipment
+	FIT_SUB_SPORT_STAIR_CLIMBING types.FitEnum = 16 // Fitness Equipment
+	FIT_SUB_SPORT_LAP_SWIMMING   types.FitEnum = 17 // Swimming
+	FIT_SUB_SPORT_OPEN_WATER     types.FitEnum = 18 // Swimming
+	FIT_SUB_SPORT_ALL            types.FitEnum = 254
+)
+
+// session_trigger
+const (
+	FIT_SESSION_TRIGGER_ACTIVITY_END      types.FitEnum = 0
+	FIT_SESSION_TRIGGER_MANUAL            types.FitEnum = 1 // User changed sport.
+	FIT_SESSION_TRIGGER_AUTO_MULTI_SPORT  types.FitEnum = 2 // Auto multi-sport feature is enabled and user pressed lap button to advance session.
+	FIT_SESSION_TRIGGER_FITNESS_EQUIPMENT types.FitEnum = 3 // Auto sport change caused by user linking to fitness equipment.
+)
+
+// lap_trigger
+const (
+	FIT_LAP_TRIGGER_MANUAL            types.FitEnum = 0
+	FIT_LAP_TRIGGER_TIME              types.FitEnum = 1
+	FIT_LAP_TRIGGER_DISTANCE          types.FitEnum = 2
+	FIT_LAP_TRIGGER_POSITION_START    types.FitEnum = 3
+	FIT_LAP_TRIGGER_POSITION_LAP      types.FitEnum = 4
+	FIT_LAP_TRIGGER_POSITION_WAYPOINT types.FitEnum = 5
+	FIT_LAP_TRIGGER_POSITION_MARKED   types.FitEnum = 6
+	FIT_LAP_TRIGGER_SESSION_END       types.FitEnum = 7
+	FIT_LAP_TRIGGER_FITNESS_EQUIPMENT types.FitEnum = 8
+)
+
+// intensity
+const (
+	FIT_INTENSITY_ACTIVE   types.FitEnum = 0
+	FIT_INTENSITY_REST     types.FitEnum = 1
+	FIT_INTENSITY_WARMUP   types.FitEnum = 2
+	FIT_INTENSITY_COOLDOWN types.FitEnum = 3
+	FIT_INTENSITY_RECOVERY types.FitEnum = 4
+	FIT_INTENSITY_INTERVAL types.FitEnum = 5
+	FIT_INTENSITY_OTHER    types.FitEnum = 6
+)
+
+// wkt_step_duration
+const (
+	FIT_WKT_STEP_DURATION_TIME                                      types.FitEnum = 0
+	FIT_WKT_STEP_DURATION_DISTANCE                                  types.FitEnum = 1
+	FIT_WKT_STEP_DURATION_HR_LESS_THAN                              types.FitEnum = 2
+	FIT_WKT_STEP_DURATION_HR_GREATER_THAN                           types.FitEnum = 3
+	FIT_WKT_STEP_DURATION_CALORIES                                  types.FitEnum = 4
+	FIT_WKT_STEP_DURATION_OPEN                                      types.FitEnum = 5
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_STEPS_CMPLT                  types.FitEnum = 6
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_TIME                         types.FitEnum = 7
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_DISTANCE                     types.FitEnum = 8
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_CALORIES                     types.FitEnum = 9
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_HR_LESS_THAN                 types.FitEnum = 10
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_HR_GREATER_THAN              types.FitEnum = 11
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_POWER_LESS_THAN              types.FitEnum = 12
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_POWER_GREATER_THAN           types.FitEnum = 13
+	FIT_WKT_STEP_DURATION_POWER_LESS_THAN                           types.FitEnum = 14
+	FIT_WKT_STEP_DURATION_POWER_GREATER_THAN                        types.FitEnum = 15
+	FIT_WKT_STEP_DURATION_TRAINING_PEAKS_TSS                        types.FitEnum = 16
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_POWER_LAST_LAP_LESS_THAN     types.FitEnum = 17
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_MAX_POWER_LAST_LAP_LESS_THAN types.FitEnum = 18
+	FIT_WKT_STEP_DURATION_POWER_3S_LESS_THAN                        types.FitEnum = 19
+	FIT_WKT_STEP_DURATION_POWER_10S_LESS_THAN                       types.FitEnum = 20
+	FIT_WKT_STEP_DURATION_POWER_30S_LESS_THAN                       types.FitEnum = 21
+	FIT_WKT_STEP_DURATION_POWER_3S_GREATER_THAN                     types.FitEnum = 22
+	FIT_WKT_STEP_DURATION_POWER_10S_GREATER_THAN                    types.FitEnum = 23
+	FIT_WKT_STEP_DURATION_POWER_30S_GREATER_THAN                    types.FitEnum = 24
+	FIT_WKT_STEP_DURATION_POWER_LAP_LESS_THAN                       types.FitEnum = 25
+	FIT_WKT_STEP_DURATION_POWER_LAP_GREATER_THAN                    types.FitEnum = 26
+	FIT_WKT_STEP_DURATION_REPEAT_UNTIL_TRAINING_PEAKS_TSS           types.FitEnum = 27
+	FIT_WKT_STEP_DURATION_REPETITION_TIME                           types.FitEnum = 28
+	FIT_WKT_STEP_DURATION_REPS                                      types.FitEnum = 29
+)
+
+// wkt_step_target
+const (
+	FIT_WKT_STEP_TARGET_SPEED          types.FitEnum = 0
+	FIT_WKT_STEP_TARGET_HEART_RATE     types.FitEnum = 1
+	FIT_WKT_STEP_TARGET_OPEN           types.FitEnum = 2
+	FIT_WKT_STEP_TARGET_CADENCE        types.FitEnum = 3
+	FIT_WKT_STEP_TARGET_POWER          types.FitEnum = 4
+	FIT_WKT_STEP_TARGET_GRADE          types.FitEnum = 5
+	FIT_WKT_STEP_TARGET_RESISTANCE     types.FitEnum = 6
+	FIT_WKT_STEP_TARGET_POWER_3S       types.FitEnum = 7
+	FIT_WKT_STEP_TARGET_POWER_10S      types.FitEnum = 8
+	FIT_WKT_STEP_TARGET_POWER_30S      types.FitEnum = 9
+	FIT_WKT_STEP_TARGET_POWER_LAP      types.FitEnum = 10
+	FIT_WKT_STEP_TARGET_SWIM_STROKE    types.FitEnum = 11
+	FIT_WKT_STEP_TARGET_SPEED_LAP      types.FitEnum = 12
+	FIT_WKT_STEP_TARGET_HEART_RATE_LAP types.FitEnum = 13
+)
+
+// workout_capabilities
+const (
+	FIT_WORKOUT_CAPABILITIES_INTERVAL          types.FitUint32z = 0x00000001
+	FIT_WORKOUT_CAPABILITIES_CUSTOM            types.FitUint32z = 0x00000002
+	FIT_WORKOUT_CAPABILITIES_FITNESS_EQUIPMENT types.FitUint32z = 0x00000004
+	FIT_WORKOUT_CAPABILITIES_FIRSTBEAT         types.FitUint32z = 0x00000008
+	FIT_WORKOUT_CAPABILITIES_NEW_LEAF          types.FitUint32z = 0x00000010
+	FIT_WORKOUT_CAPABILITIES_TCX               types.FitUint32z = 0x00000020 // For backwards compatibility.  Watch should add missing id fields then clear flag.
+	FIT_WORKOUT_CAPABILITIES_SPEED             types.FitUint32z = 0x00000080 // Speed source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_HEART_RATE        types.FitUint32z = 0x00000100 // Heart rate source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_DISTANCE          types.FitUint32z = 0x00000200 // Distance source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_CADENCE           types.FitUint32z = 0x00000400 // Cadence source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_POWER             types.FitUint32z = 0x00000800 // Power source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_GRADE             types.FitUint32z = 0x00001000 // Grade source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_RESISTANCE        types.FitUint32z = 0x00002000 // Resistance source required for workout step.
+	FIT_WORKOUT_CAPABILITIES_PROTECTED         types.FitUint32z = 0x00004000
+)
+
+// source_type
+const (
+	FIT_SOURCE_TYPE_ANT                  types.FitEnum = 0 // External device connected with ANT
+	FIT_SOURCE_TYPE_ANTPLUS              types.FitEnum = 1 // External device connected with ANT+
+	FIT_SOURCE_TYPE_BLUETOOTH            types.FitEnum = 2 // External device connected with BT
+	FIT_SOURCE_TYPE_BLUETOOTH_LOW_ENERGY types.FitEnum = 3 // External device connected with BLE
+	FIT_SOURCE_TYPE_WIFI                 types.FitEnum = 4 // External device connected with Wifi
+	FIT_SOURCE_TYPE_LOCAL                types.FitEnum = 5 // Onboard device
+)
+
+// fit_base_unit
+const (
+	FIT_FIT_BASE_UNIT_OTHER    types.FitUint16 = 0
+	FIT_FIT_BASE_UNIT_KILOGRAM types.FitUint16 = 1
+	FIT_FIT_BASE_UNIT_POUND    types.FitUint16 = 2
+)
+
+// FileIdInfo describes the file_id message. Must be first message in file.
+var FileIdInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_FILE_ID,
+	Name: "file_id",
+	Fields: []*FieldInfo{
+		FileIdType,
+		FileIdManufacturer,
+		FileIdProduct,
+		FileIdSerialNumber,
+		FileIdTimeCreated,
+		FileIdNumber,
+		FileIdProductName,
+	},
+}
+
+// fields of the file_id message
+var (
+	FileIdType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      0,
+		Name:     "type",
+		Type:     "file",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileIdManufacturer = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      1,
+		Name:     "manufacturer",
+		Type:     "manufacturer",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileIdProduct = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      2,
+		Name:     "product",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileIdSerialNumber = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      3,
+		Name:     "serial_number",
+		Type:     "uint32z",
+		BaseType: types.FIT_TYPE_UINT32Z,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileIdTimeCreated = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      4,
+		Name:     "time_created",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileIdNumber = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      5,
+		Name:     "number",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileIdProductName = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_ID,
+		Num:      8,
+		Name:     "product_name",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// FileId is the file_id message. Must be first message in file.
+type FileId struct {
+	Type         types.FitEnum
+	Manufacturer types.FitUint16
+	Product      types.FitUint16
+	SerialNumber types.FitUint32z
+	TimeCreated  time.Time
+	Number       types.FitUint16
+	ProductName  string
+}
+
+func (FileId) Info() *MessageInfo {
+	return FileIdInfo
+}
+
+func (m FileId) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 0:
+		return m.Type
+	case 1:
+		return m.Manufacturer
+	case 2:
+		return m.Product
+	case 3:
+		return m.SerialNumber
+	case 4:
+		return m.TimeCreated
+	case 5:
+		return m.Number
+	case 8:
+		return m.ProductName
+	}
+	return nil
+}
+
+// FileCreatorInfo describes the file_creator message
+var FileCreatorInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_FILE_CREATOR,
+	Name: "file_creator",
+	Fields: []*FieldInfo{
+		FileCreatorSoftwareVersion,
+		FileCreatorHardwareVersion,
+	},
+}
+
+// fields of the file_creator message
+var (
+	FileCreatorSoftwareVersion = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_CREATOR,
+		Num:      0,
+		Name:     "software_version",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FileCreatorHardwareVersion = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FILE_CREATOR,
+		Num:      1,
+		Name:     "hardware_version",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// FileCreator is the file_creator message
+type FileCreator struct {
+	SoftwareVersion types.FitUint16
+	HardwareVersion types.FitUint8
+}
+
+func (FileCreator) Info() *MessageInfo {
+	return FileCreatorInfo
+}
+
+func (m FileCreator) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 0:
+		return m.SoftwareVersion
+	case 1:
+		return m.HardwareVersion
+	}
+	return nil
+}
+
+// SessionInfo describes the session message
+var SessionInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_SESSION,
+	Name: "session",
+	Fields: []*FieldInfo{
+		SessionMessageIndex,
+		SessionTimestamp,
+		SessionEvent,
+		SessionEventType,
+		SessionStartTime,
+		SessionStartPositionLat,
+		SessionStartPositionLong,
+		SessionSport,
+		SessionSubSport,
+		SessionTotalElapsedTime,
+		SessionTotalTimerTime,
+		SessionTotalDistance,
+		SessionTotalCycles,
+		SessionTotalCalories,
+		SessionAvgSpeed,
+		SessionMaxSpeed,
+		SessionAvgHeartRate,
+		SessionMaxHeartRate,
+		SessionAvgCadence,
+		SessionMaxCadence,
+		SessionAvgPower,
+		SessionMaxPower,
+		SessionTotalAscent,
+		SessionTotalDescent,
+		SessionFirstLapIndex,
+		SessionNumLaps,
+		SessionTrigger,
+		SessionEnhancedAvgSpeed,
+		SessionEnhancedMaxSpeed,
+	},
+}
+
+// fields of the session message
+var (
+	SessionMessageIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      254,
+		Name:     "message_index",
+		Type:     "message_index",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      253,
+		Name:     "timestamp",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "s",
+	}
+	SessionEvent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      0,
+		Name:     "event",
+		Type:     "event",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionEventType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      1,
+		Name:     "event_type",
+		Type:     "event_type",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionStartTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      2,
+		Name:     "start_time",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionStartPositionLat = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      3,
+		Name:     "start_position_lat",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	SessionStartPositionLong = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      4,
+		Name:     "start_position_long",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	SessionSport = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      5,
+		Name:     "sport",
+		Type:     "sport",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionSubSport = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      6,
+		Name:     "sub_sport",
+		Type:     "sub_sport",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionTotalElapsedTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      7,
+		Name:     "total_elapsed_time",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "s",
+	}
+	SessionTotalTimerTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      8,
+		Name:     "total_timer_time",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "s",
+	}
+	SessionTotalDistance = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      9,
+		Name:     "total_distance",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    100,
+		Offset:   0,
+		Units:    "m",
+	}
+	SessionTotalCycles = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      10,
+		Name:     "total_cycles",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "cycles",
+	}
+	SessionTotalCalories = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      11,
+		Name:     "total_calories",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "kcal",
+	}
+	SessionAvgSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      14,
+		Name:     "avg_speed",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	SessionMaxSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      15,
+		Name:     "max_speed",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	SessionAvgHeartRate = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      16,
+		Name:     "avg_heart_rate",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "bpm",
+	}
+	SessionMaxHeartRate = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      17,
+		Name:     "max_heart_rate",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "bpm",
+	}
+	SessionAvgCadence = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      18,
+		Name:     "avg_cadence",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "rpm",
+	}
+	SessionMaxCadence = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      19,
+		Name:     "max_cadence",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "rpm",
+	}
+	SessionAvgPower = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      20,
+		Name:     "avg_power",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "watts",
+	}
+	SessionMaxPower = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      21,
+		Name:     "max_power",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "watts",
+	}
+	SessionTotalAscent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      22,
+		Name:     "total_ascent",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "m",
+	}
+	SessionTotalDescent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      23,
+		Name:     "total_descent",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "m",
+	}
+	SessionFirstLapIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      25,
+		Name:     "first_lap_index",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionNumLaps = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      26,
+		Name:     "num_laps",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionTrigger = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      28,
+		Name:     "trigger",
+		Type:     "session_trigger",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	SessionEnhancedAvgSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      124,
+		Name:     "enhanced_avg_speed",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	SessionEnhancedMaxSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_SESSION,
+		Num:      125,
+		Name:     "enhanced_max_speed",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+)
+
+// Session is the session message
+type Session struct {
+	MessageIndex      types.FitUint16
+	Timestamp         time.Time
+	Event             types.FitEnum
+	EventType         types.FitEnum
+	StartTime         time.Time
+	StartPositionLat  float64 // degrees
+	StartPositionLong float64 // degrees
+	Sport             types.FitEnum
+	SubSport          types.FitEnum
+	TotalElapsedTime  float64         // s
+	TotalTimerTime    float64         // s
+	TotalDistance     float64         // m
+	TotalCycles       types.FitUint32 // cycles
+	TotalCalories     types.FitUint16 // kcal
+	AvgSpeed          float64         // m/s
+	MaxSpeed          float64         // m/s
+	AvgHeartRate      types.FitUint8  // bpm
+	MaxHeartRate      types.FitUint8  // bpm
+	AvgCadence        types.FitUint8  // rpm
+	MaxCadence        types.FitUint8  // rpm
+	AvgPower          types.FitUint16 // watts
+	MaxPower          types.FitUint16 // watts
+	TotalAscent       types.FitUint16 // m
+	TotalDescent      types.FitUint16 // m
+	FirstLapIndex     types.FitUint16
+	NumLaps           types.FitUint16
+	Trigger           types.FitEnum
+	EnhancedAvgSpeed  float64 // m/s
+	EnhancedMaxSpeed  float64 // m/s
+}
+
+func (Session) Info() *MessageInfo {
+	return SessionInfo
+}
+
+func (m Session) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 254:
+		return m.MessageIndex
+	case 253:
+		return m.Timestamp
+	case 0:
+		return m.Event
+	case 1:
+		return m.EventType
+	case 2:
+		return m.StartTime
+	case 3:
+		return m.StartPositionLat
+	case 4:
+		return m.StartPositionLong
+	case 5:
+		return m.Sport
+	case 6:
+		return m.SubSport
+	case 7:
+		return m.TotalElapsedTime
+	case 8:
+		return m.TotalTimerTime
+	case 9:
+		return m.TotalDistance
+	case 10:
+		return m.TotalCycles
+	case 11:
+		return m.TotalCalories
+	case 14:
+		return m.AvgSpeed
+	case 15:
+		return m.MaxSpeed
+	case 16:
+		return m.AvgHeartRate
+	case 17:
+		return m.MaxHeartRate
+	case 18:
+		return m.AvgCadence
+	case 19:
+		return m.MaxCadence
+	case 20:
+		return m.AvgPower
+	case 21:
+		return m.MaxPower
+	case 22:
+		return m.TotalAscent
+	case 23:
+		return m.TotalDescent
+	case 25:
+		return m.FirstLapIndex
+	case 26:
+		return m.NumLaps
+	case 28:
+		return m.Trigger
+	case 124:
+		return m.EnhancedAvgSpeed
+	case 125:
+		return m.EnhancedMaxSpeed
+	}
+	return nil
+}
+
+// LapInfo describes the lap message
+var LapInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_LAP,
+	Name: "lap",
+	Fields: []*FieldInfo{
+		LapMessageIndex,
+		LapTimestamp,
+		LapEvent,
+		LapEventType,
+		LapStartTime,
+		LapStartPositionLat,
+		LapStartPositionLong,
+		LapEndPositionLat,
+		LapEndPositionLong,
+		LapTotalElapsedTime,
+		LapTotalTimerTime,
+		LapTotalDistance,
+		LapTotalCycles,
+		LapTotalCalories,
+		LapAvgSpeed,
+		LapMaxSpeed,
+		LapAvgHeartRate,
+		LapMaxHeartRate,
+		LapAvgCadence,
+		LapMaxCadence,
+		LapAvgPower,
+		LapMaxPower,
+		LapTotalAscent,
+		LapTotalDescent,
+		LapIntensity,
+		LapLapTrigger,
+		LapSport,
+		LapSubSport,
+		LapEnhancedAvgSpeed,
+		LapEnhancedMaxSpeed,
+	},
+}
+
+// fields of the lap message
+var (
+	LapMessageIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      254,
+		Name:     "message_index",
+		Type:     "message_index",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      253,
+		Name:     "timestamp",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "s",
+	}
+	LapEvent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      0,
+		Name:     "event",
+		Type:     "event",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapEventType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      1,
+		Name:     "event_type",
+		Type:     "event_type",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapStartTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      2,
+		Name:     "start_time",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapStartPositionLat = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      3,
+		Name:     "start_position_lat",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	LapStartPositionLong = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      4,
+		Name:     "start_position_long",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	LapEndPositionLat = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      5,
+		Name:     "end_position_lat",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	LapEndPositionLong = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      6,
+		Name:     "end_position_long",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	LapTotalElapsedTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      7,
+		Name:     "total_elapsed_time",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "s",
+	}
+	LapTotalTimerTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      8,
+		Name:     "total_timer_time",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "s",
+	}
+	LapTotalDistance = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      9,
+		Name:     "total_distance",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    100,
+		Offset:   0,
+		Units:    "m",
+	}
+	LapTotalCycles = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      10,
+		Name:     "total_cycles",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "cycles",
+	}
+	LapTotalCalories = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      11,
+		Name:     "total_calories",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "kcal",
+	}
+	LapAvgSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      13,
+		Name:     "avg_speed",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	LapMaxSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      14,
+		Name:     "max_speed",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	LapAvgHeartRate = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      15,
+		Name:     "avg_heart_rate",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "bpm",
+	}
+	LapMaxHeartRate = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      16,
+		Name:     "max_heart_rate",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "bpm",
+	}
+	LapAvgCadence = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      17,
+		Name:     "avg_cadence",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "rpm",
+	}
+	LapMaxCadence = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      18,
+		Name:     "max_cadence",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "rpm",
+	}
+	LapAvgPower = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      19,
+		Name:     "avg_power",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "watts",
+	}
+	LapMaxPower = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      20,
+		Name:     "max_power",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "watts",
+	}
+	LapTotalAscent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      21,
+		Name:     "total_ascent",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "m",
+	}
+	LapTotalDescent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      22,
+		Name:     "total_descent",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "m",
+	}
+	LapIntensity = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      23,
+		Name:     "intensity",
+		Type:     "intensity",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapLapTrigger = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      24,
+		Name:     "lap_trigger",
+		Type:     "lap_trigger",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapSport = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      25,
+		Name:     "sport",
+		Type:     "sport",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapSubSport = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      39,
+		Name:     "sub_sport",
+		Type:     "sub_sport",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	LapEnhancedAvgSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      110,
+		Name:     "enhanced_avg_speed",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	LapEnhancedMaxSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_LAP,
+		Num:      111,
+		Name:     "enhanced_max_speed",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+)
+
+// Lap is the lap message
+type Lap struct {
+	MessageIndex      types.FitUint16
+	Timestamp         time.Time
+	Event             types.FitEnum
+	EventType         types.FitEnum
+	StartTime         time.Time
+	StartPositionLat  float64         // degrees
+	StartPositionLong float64         // degrees
+	EndPositionLat    float64         // degrees
+	EndPositionLong   float64         // degrees
+	TotalElapsedTime  float64         // s
+	TotalTimerTime    float64         // s
+	TotalDistance     float64         // m
+	TotalCycles       types.FitUint32 // cycles
+	TotalCalories     types.FitUint16 // kcal
+	AvgSpeed          float64         // m/s
+	MaxSpeed          float64         // m/s
+	AvgHeartRate      types.FitUint8  // bpm
+	MaxHeartRate      types.FitUint8  // bpm
+	AvgCadence        types.FitUint8  // rpm
+	MaxCadence        types.FitUint8  // rpm
+	AvgPower          types.FitUint16 // watts
+	MaxPower          types.FitUint16 // watts
+	TotalAscent       types.FitUint16 // m
+	TotalDescent      types.FitUint16 // m
+	Intensity         types.FitEnum
+	LapTrigger        types.FitEnum
+	Sport             types.FitEnum
+	SubSport          types.FitEnum
+	EnhancedAvgSpeed  float64 // m/s
+	EnhancedMaxSpeed  float64 // m/s
+}
+
+func (Lap) Info() *MessageInfo {
+	return LapInfo
+}
+
+func (m Lap) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 254:
+		return m.MessageIndex
+	case 253:
+		return m.Timestamp
+	case 0:
+		return m.Event
+	case 1:
+		return m.EventType
+	case 2:
+		return m.StartTime
+	case 3:
+		return m.StartPositionLat
+	case 4:
+		return m.StartPositionLong
+	case 5:
+		return m.EndPositionLat
+	case 6:
+		return m.EndPositionLong
+	case 7:
+		return m.TotalElapsedTime
+	case 8:
+		return m.TotalTimerTime
+	case 9:
+		return m.TotalDistance
+	case 10:
+		return m.TotalCycles
+	case 11:
+		return m.TotalCalories
+	case 13:
+		return m.AvgSpeed
+	case 14:
+		return m.MaxSpeed
+	case 15:
+		return m.AvgHeartRate
+	case 16:
+		return m.MaxHeartRate
+	case 17:
+		return m.AvgCadence
+	case 18:
+		return m.MaxCadence
+	case 19:
+		return m.AvgPower
+	case 20:
+		return m.MaxPower
+	case 21:
+		return m.TotalAscent
+	case 22:
+		return m.TotalDescent
+	case 23:
+		return m.Intensity
+	case 24:
+		return m.LapTrigger
+	case 25:
+		return m.Sport
+	case 39:
+		return m.SubSport
+	case 110:
+		return m.EnhancedAvgSpeed
+	case 111:
+		return m.EnhancedMaxSpeed
+	}
+	return nil
+}
+
+// RecordInfo describes the record message
+var RecordInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_RECORD,
+	Name: "record",
+	Fields: []*FieldInfo{
+		RecordTimestamp,
+		RecordPositionLat,
+		RecordPositionLong,
+		RecordAltitude,
+		RecordHeartRate,
+		RecordCadence,
+		RecordDistance,
+		RecordSpeed,
+		RecordPower,
+		RecordGrade,
+		RecordTemperature,
+		RecordAccumulatedPower,
+		RecordGpsAccuracy,
+		RecordVerticalSpeed,
+		RecordCalories,
+		RecordFractionalCadence,
+		RecordEnhancedSpeed,
+		RecordEnhancedAltitude,
+	},
+}
+
+// fields of the record message
+var (
+	RecordTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      253,
+		Name:     "timestamp",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "s",
+	}
+	RecordPositionLat = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      0,
+		Name:     "position_lat",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	RecordPositionLong = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      1,
+		Name:     "position_long",
+		Type:     "sint32",
+		BaseType: types.FIT_TYPE_SINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "semicircles",
+	}
+	RecordAltitude = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      2,
+		Name:     "altitude",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    5,
+		Offset:   500,
+		Units:    "m",
+	}
+	RecordHeartRate = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      3,
+		Name:     "heart_rate",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "bpm",
+	}
+	RecordCadence = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      4,
+		Name:     "cadence",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "rpm",
+	}
+	RecordDistance = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      5,
+		Name:     "distance",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    100,
+		Offset:   0,
+		Units:    "m",
+	}
+	RecordSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      6,
+		Name:     "speed",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	RecordPower = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      7,
+		Name:     "power",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "watts",
+	}
+	RecordGrade = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      9,
+		Name:     "grade",
+		Type:     "sint16",
+		BaseType: types.FIT_TYPE_SINT16,
+		Array:    false,
+		Scale:    100,
+		Offset:   0,
+		Units:    "%",
+	}
+	RecordTemperature = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      13,
+		Name:     "temperature",
+		Type:     "sint8",
+		BaseType: types.FIT_TYPE_SINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "C",
+	}
+	RecordAccumulatedPower = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      29,
+		Name:     "accumulated_power",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "watts",
+	}
+	RecordGpsAccuracy = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      31,
+		Name:     "gps_accuracy",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "m",
+	}
+	RecordVerticalSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      32,
+		Name:     "vertical_speed",
+		Type:     "sint16",
+		BaseType: types.FIT_TYPE_SINT16,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	RecordCalories = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      33,
+		Name:     "calories",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "kcal",
+	}
+	RecordFractionalCadence = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      53,
+		Name:     "fractional_cadence",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    128,
+		Offset:   0,
+		Units:    "rpm",
+	}
+	RecordEnhancedSpeed = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      73,
+		Name:     "enhanced_speed",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "m/s",
+	}
+	RecordEnhancedAltitude = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_RECORD,
+		Num:      78,
+		Name:     "enhanced_altitude",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    5,
+		Offset:   500,
+		Units:    "m",
+	}
+)
+
+// Record is the record message
+type Record struct {
+	Timestamp         time.Time
+	PositionLat       float64         // degrees
+	PositionLong      float64         // degrees
+	Altitude          float64         // m
+	HeartRate         types.FitUint8  // bpm
+	Cadence           types.FitUint8  // rpm
+	Distance          float64         // m
+	Speed             float64         // m/s
+	Power             types.FitUint16 // watts
+	Grade             float64         // %
+	Temperature       types.FitSint8  // C
+	AccumulatedPower  types.FitUint32 // watts
+	GpsAccuracy       types.FitUint8  // m
+	VerticalSpeed     float64         // m/s
+	Calories          types.FitUint16 // kcal
+	FractionalCadence float64         // rpm
+	EnhancedSpeed     float64         // m/s
+	EnhancedAltitude  float64         // m
+}
+
+func (Record) Info() *MessageInfo {
+	return RecordInfo
+}
+
+func (m Record) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 253:
+		return m.Timestamp
+	case 0:
+		return m.PositionLat
+	case 1:
+		return m.PositionLong
+	case 2:
+		return m.Altitude
+	case 3:
+		return m.HeartRate
+	case 4:
+		return m.Cadence
+	case 5:
+		return m.Distance
+	case 6:
+		return m.Speed
+	case 7:
+		return m.Power
+	case 9:
+		return m.Grade
+	case 13:
+		return m.Temperature
+	case 29:
+		return m.AccumulatedPower
+	case 31:
+		return m.GpsAccuracy
+	case 32:
+		return m.VerticalSpeed
+	case 33:
+		return m.Calories
+	case 53:
+		return m.FractionalCadence
+	case 73:
+		return m.EnhancedSpeed
+	case 78:
+		return m.EnhancedAltitude
+	}
+	return nil
+}
+
+// EventInfo describes the event message
+var EventInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_EVENT,
+	Name: "event",
+	Fields: []*FieldInfo{
+		EventTimestamp,
+		EventEvent,
+		EventEventType,
+		EventData16,
+		EventData,
+		EventEventGroup,
+	},
+}
+
+// fields of the event message
+var (
+	EventTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_EVENT,
+		Num:      253,
+		Name:     "timestamp",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "s",
+	}
+	EventEvent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_EVENT,
+		Num:      0,
+		Name:     "event",
+		Type:     "event",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	EventEventType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_EVENT,
+		Num:      1,
+		Name:     "event_type",
+		Type:     "event_type",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	EventData16 = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_EVENT,
+		Num:      2,
+		Name:     "data16",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	EventData = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_EVENT,
+		Num:      3,
+		Name:     "data",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	EventEventGroup = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_EVENT,
+		Num:      4,
+		Name:     "event_group",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// Event is the event message
+type Event struct {
+	Timestamp  time.Time
+	Event      types.FitEnum
+	EventType  types.FitEnum
+	Data16     types.FitUint16
+	Data       types.FitUint32
+	EventGroup types.FitUint8
+}
+
+func (Event) Info() *MessageInfo {
+	return EventInfo
+}
+
+func (m Event) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 253:
+		return m.Timestamp
+	case 0:
+		return m.Event
+	case 1:
+		return m.EventType
+	case 2:
+		return m.Data16
+	case 3:
+		return m.Data
+	case 4:
+		return m.EventGroup
+	}
+	return nil
+}
+
+// DeviceInfoInfo describes the device_info message
+var DeviceInfoInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_DEVICE_INFO,
+	Name: "device_info",
+	Fields: []*FieldInfo{
+		DeviceInfoTimestamp,
+		DeviceInfoDeviceIndex,
+		DeviceInfoDeviceType,
+		DeviceInfoManufacturer,
+		DeviceInfoSerialNumber,
+		DeviceInfoProduct,
+		DeviceInfoSoftwareVersion,
+		DeviceInfoHardwareVersion,
+		DeviceInfoCumOperatingTime,
+		DeviceInfoBatteryVoltage,
+		DeviceInfoBatteryStatus,
+		DeviceInfoDescriptor,
+		DeviceInfoSourceType,
+		DeviceInfoProductName,
+	},
+}
+
+// fields of the device_info message
+var (
+	DeviceInfoTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      253,
+		Name:     "timestamp",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "s",
+	}
+	DeviceInfoDeviceIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      0,
+		Name:     "device_index",
+		Type:     "device_index",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoDeviceType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      1,
+		Name:     "device_type",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoManufacturer = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      2,
+		Name:     "manufacturer",
+		Type:     "manufacturer",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoSerialNumber = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      3,
+		Name:     "serial_number",
+		Type:     "uint32z",
+		BaseType: types.FIT_TYPE_UINT32Z,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoProduct = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      4,
+		Name:     "product",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoSoftwareVersion = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      5,
+		Name:     "software_version",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    100,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoHardwareVersion = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      6,
+		Name:     "hardware_version",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoCumOperatingTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      7,
+		Name:     "cum_operating_time",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "s",
+	}
+	DeviceInfoBatteryVoltage = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      10,
+		Name:     "battery_voltage",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    256,
+		Offset:   0,
+		Units:    "V",
+	}
+	DeviceInfoBatteryStatus = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      11,
+		Name:     "battery_status",
+		Type:     "battery_status",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoDescriptor = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      19,
+		Name:     "descriptor",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoSourceType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      25,
+		Name:     "source_type",
+		Type:     "source_type",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeviceInfoProductName = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVICE_INFO,
+		Num:      27,
+		Name:     "product_name",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// DeviceInfo is the device_info message
+type DeviceInfo struct {
+	Timestamp        time.Time
+	DeviceIndex      types.FitUint8
+	DeviceType       types.FitUint8
+	Manufacturer     types.FitUint16
+	SerialNumber     types.FitUint32z
+	Product          types.FitUint16
+	SoftwareVersion  float64
+	HardwareVersion  types.FitUint8
+	CumOperatingTime types.FitUint32 // s
+	BatteryVoltage   float64         // V
+	BatteryStatus    types.FitUint8
+	Descriptor       string
+	SourceType       types.FitEnum
+	ProductName      string
+}
+
+func (DeviceInfo) Info() *MessageInfo {
+	return DeviceInfoInfo
+}
+
+func (m DeviceInfo) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 253:
+		return m.Timestamp
+	case 0:
+		return m.DeviceIndex
+	case 1:
+		return m.DeviceType
+	case 2:
+		return m.Manufacturer
+	case 3:
+		return m.SerialNumber
+	case 4:
+		return m.Product
+	case 5:
+		return m.SoftwareVersion
+	case 6:
+		return m.HardwareVersion
+	case 7:
+		return m.CumOperatingTime
+	case 10:
+		return m.BatteryVoltage
+	case 11:
+		return m.BatteryStatus
+	case 19:
+		return m.Descriptor
+	case 25:
+		return m.SourceType
+	case 27:
+		return m.ProductName
+	}
+	return nil
+}
+
+// ActivityInfo describes the activity message
+var ActivityInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_ACTIVITY,
+	Name: "activity",
+	Fields: []*FieldInfo{
+		ActivityTimestamp,
+		ActivityTotalTimerTime,
+		ActivityNumSessions,
+		ActivityType,
+		ActivityEvent,
+		ActivityEventType,
+		ActivityLocalTimestamp,
+		ActivityEventGroup,
+	},
+}
+
+// fields of the activity message
+var (
+	ActivityTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      253,
+		Name:     "timestamp",
+		Type:     "date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	ActivityTotalTimerTime = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      0,
+		Name:     "total_timer_time",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1000,
+		Offset:   0,
+		Units:    "s",
+	}
+	ActivityNumSessions = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      1,
+		Name:     "num_sessions",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	ActivityType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      2,
+		Name:     "type",
+		Type:     "activity",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	ActivityEvent = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      3,
+		Name:     "event",
+		Type:     "event",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	ActivityEventType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      4,
+		Name:     "event_type",
+		Type:     "event_type",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	ActivityLocalTimestamp = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      5,
+		Name:     "local_timestamp",
+		Type:     "local_date_time",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	ActivityEventGroup = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_ACTIVITY,
+		Num:      6,
+		Name:     "event_group",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// Activity is the activity message
+type Activity struct {
+	Timestamp      time.Time
+	TotalTimerTime float64 // s
+	NumSessions    types.FitUint16
+	Type           types.FitEnum
+	Event          types.FitEnum
+	EventType      types.FitEnum
+	LocalTimestamp types.FitUint32
+	EventGroup     types.FitUint8
+}
+
+func (Activity) Info() *MessageInfo {
+	return ActivityInfo
+}
+
+func (m Activity) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 253:
+		return m.Timestamp
+	case 0:
+		return m.TotalTimerTime
+	case 1:
+		return m.NumSessions
+	case 2:
+		return m.Type
+	case 3:
+		return m.Event
+	case 4:
+		return m.EventType
+	case 5:
+		return m.LocalTimestamp
+	case 6:
+		return m.EventGroup
+	}
+	return nil
+}
+
+// WorkoutInfo describes the workout message
+var WorkoutInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_WORKOUT,
+	Name: "workout",
+	Fields: []*FieldInfo{
+		WorkoutSport,
+		WorkoutCapabilities,
+		WorkoutNumValidSteps,
+		WorkoutWktName,
+		WorkoutSubSport,
+	},
+}
+
+// fields of the workout message
+var (
+	WorkoutSport = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT,
+		Num:      4,
+		Name:     "sport",
+		Type:     "sport",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutCapabilities = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT,
+		Num:      5,
+		Name:     "capabilities",
+		Type:     "workout_capabilities",
+		BaseType: types.FIT_TYPE_UINT32Z,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutNumValidSteps = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT,
+		Num:      6,
+		Name:     "num_valid_steps",
+		Type:     "uint16",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutWktName = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT,
+		Num:      8,
+		Name:     "wkt_name",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutSubSport = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT,
+		Num:      11,
+		Name:     "sub_sport",
+		Type:     "sub_sport",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// Workout is the workout message
+type Workout struct {
+	Sport         types.FitEnum
+	Capabilities  types.FitUint32z
+	NumValidSteps types.FitUint16
+	WktName       string
+	SubSport      types.FitEnum
+}
+
+func (Workout) Info() *MessageInfo {
+	return WorkoutInfo
+}
+
+func (m Workout) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 4:
+		return m.Sport
+	case 5:
+		return m.Capabilities
+	case 6:
+		return m.NumValidSteps
+	case 8:
+		return m.WktName
+	case 11:
+		return m.SubSport
+	}
+	return nil
+}
+
+// WorkoutStepInfo describes the workout_step message
+var WorkoutStepInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_WORKOUT_STEP,
+	Name: "workout_step",
+	Fields: []*FieldInfo{
+		WorkoutStepMessageIndex,
+		WorkoutStepWktStepName,
+		WorkoutStepDurationType,
+		WorkoutStepDurationValue,
+		WorkoutStepTargetType,
+		WorkoutStepTargetValue,
+		WorkoutStepCustomTargetValueLow,
+		WorkoutStepCustomTargetValueHigh,
+		WorkoutStepIntensity,
+		WorkoutStepNotes,
+	},
+}
+
+// fields of the workout_step message
+var (
+	WorkoutStepMessageIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      254,
+		Name:     "message_index",
+		Type:     "message_index",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepWktStepName = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      0,
+		Name:     "wkt_step_name",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepDurationType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      1,
+		Name:     "duration_type",
+		Type:     "wkt_step_duration",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepDurationValue = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      2,
+		Name:     "duration_value",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepTargetType = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      3,
+		Name:     "target_type",
+		Type:     "wkt_step_target",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepTargetValue = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      4,
+		Name:     "target_value",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepCustomTargetValueLow = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      5,
+		Name:     "custom_target_value_low",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepCustomTargetValueHigh = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      6,
+		Name:     "custom_target_value_high",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepIntensity = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      7,
+		Name:     "intensity",
+		Type:     "intensity",
+		BaseType: types.FIT_TYPE_ENUM,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	WorkoutStepNotes = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_WORKOUT_STEP,
+		Num:      8,
+		Name:     "notes",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// WorkoutStep is the workout_step message
+type WorkoutStep struct {
+	MessageIndex          types.FitUint16
+	WktStepName           string
+	DurationType          types.FitEnum
+	DurationValue         types.FitUint32
+	TargetType            types.FitEnum
+	TargetValue           types.FitUint32
+	CustomTargetValueLow  types.FitUint32
+	CustomTargetValueHigh types.FitUint32
+	Intensity             types.FitEnum
+	Notes                 string
+}
+
+func (WorkoutStep) Info() *MessageInfo {
+	return WorkoutStepInfo
+}
+
+func (m WorkoutStep) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 254:
+		return m.MessageIndex
+	case 0:
+		return m.WktStepName
+	case 1:
+		return m.DurationType
+	case 2:
+		return m.DurationValue
+	case 3:
+		return m.TargetType
+	case 4:
+		return m.TargetValue
+	case 5:
+		return m.CustomTargetValueLow
+	case 6:
+		return m.CustomTargetValueHigh
+	case 7:
+		return m.Intensity
+	case 8:
+		return m.Notes
+	}
+	return nil
+}
+
+// FieldDescriptionInfo describes the field_description message. Must be logged before developer field is used
+var FieldDescriptionInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+	Name: "field_description",
+	Fields: []*FieldInfo{
+		FieldDescriptionDeveloperDataIndex,
+		FieldDescriptionFieldDefinitionNumber,
+		FieldDescriptionFitBaseTypeId,
+		FieldDescriptionFieldName,
+		FieldDescriptionArray,
+		FieldDescriptionComponents,
+		FieldDescriptionScale,
+		FieldDescriptionOffset,
+		FieldDescriptionUnits,
+		FieldDescriptionBits,
+		FieldDescriptionAccumulate,
+		FieldDescriptionFitBaseUnitId,
+		FieldDescriptionNativeMesgNum,
+		FieldDescriptionNativeFieldNum,
+	},
+}
+
+// fields of the field_description message
+var (
+	FieldDescriptionDeveloperDataIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      0,
+		Name:     "developer_data_index",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionFieldDefinitionNumber = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      1,
+		Name:     "field_definition_number",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionFitBaseTypeId = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      2,
+		Name:     "fit_base_type_id",
+		Type:     "fit_base_type",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionFieldName = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      3,
+		Name:     "field_name",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionArray = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      4,
+		Name:     "array",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionComponents = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      5,
+		Name:     "components",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionScale = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      6,
+		Name:     "scale",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionOffset = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      7,
+		Name:     "offset",
+		Type:     "sint8",
+		BaseType: types.FIT_TYPE_SINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionUnits = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      8,
+		Name:     "units",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionBits = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      9,
+		Name:     "bits",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionAccumulate = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      10,
+		Name:     "accumulate",
+		Type:     "string",
+		BaseType: types.FIT_TYPE_STRING,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionFitBaseUnitId = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      13,
+		Name:     "fit_base_unit_id",
+		Type:     "fit_base_unit",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionNativeMesgNum = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      14,
+		Name:     "native_mesg_num",
+		Type:     "mesg_num",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	FieldDescriptionNativeFieldNum = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_FIELD_DESCRIPTION,
+		Num:      15,
+		Name:     "native_field_num",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// FieldDescription is the field_description message. Must be logged before developer field is used
+type FieldDescription struct {
+	DeveloperDataIndex    types.FitUint8
+	FieldDefinitionNumber types.FitUint8
+	FitBaseTypeId         types.FitBaseType
+	FieldName             string
+	Array                 types.FitUint8
+	Components            string
+	Scale                 types.FitUint8
+	Offset                types.FitSint8
+	Units                 string
+	Bits                  string
+	Accumulate            string
+	FitBaseUnitId         types.FitUint16
+	NativeMesgNum         types.FitUint16
+	NativeFieldNum        types.FitUint8
+}
+
+func (FieldDescription) Info() *MessageInfo {
+	return FieldDescriptionInfo
+}
+
+func (m FieldDescription) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 0:
+		return m.DeveloperDataIndex
+	case 1:
+		return m.FieldDefinitionNumber
+	case 2:
+		return m.FitBaseTypeId
+	case 3:
+		return m.FieldName
+	case 4:
+		return m.Array
+	case 5:
+		return m.Components
+	case 6:
+		return m.Scale
+	case 7:
+		return m.Offset
+	case 8:
+		return m.Units
+	case 9:
+		return m.Bits
+	case 10:
+		return m.Accumulate
+	case 13:
+		return m.FitBaseUnitId
+	case 14:
+		return m.NativeMesgNum
+	case 15:
+		return m.NativeFieldNum
+	}
+	return nil
+}
+
+// DeveloperDataIdInfo describes the developer_data_id message. Must be logged before field description
+var DeveloperDataIdInfo = &MessageInfo{
+	Num:  FIT_MESG_NUM_DEVELOPER_DATA_ID,
+	Name: "developer_data_id",
+	Fields: []*FieldInfo{
+		DeveloperDataIdDeveloperId,
+		DeveloperDataIdApplicationId,
+		DeveloperDataIdManufacturerId,
+		DeveloperDataIdDeveloperDataIndex,
+		DeveloperDataIdApplicationVersion,
+	},
+}
+
+// fields of the developer_data_id message
+var (
+	DeveloperDataIdDeveloperId = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVELOPER_DATA_ID,
+		Num:      0,
+		Name:     "developer_id",
+		Type:     "byte",
+		BaseType: types.FIT_TYPE_BYTE,
+		Array:    true,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeveloperDataIdApplicationId = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVELOPER_DATA_ID,
+		Num:      1,
+		Name:     "application_id",
+		Type:     "byte",
+		BaseType: types.FIT_TYPE_BYTE,
+		Array:    true,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeveloperDataIdManufacturerId = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVELOPER_DATA_ID,
+		Num:      2,
+		Name:     "manufacturer_id",
+		Type:     "manufacturer",
+		BaseType: types.FIT_TYPE_UINT16,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeveloperDataIdDeveloperDataIndex = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVELOPER_DATA_ID,
+		Num:      3,
+		Name:     "developer_data_index",
+		Type:     "uint8",
+		BaseType: types.FIT_TYPE_UINT8,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+	DeveloperDataIdApplicationVersion = &FieldInfo{
+		MesgNum:  FIT_MESG_NUM_DEVELOPER_DATA_ID,
+		Num:      4,
+		Name:     "application_version",
+		Type:     "uint32",
+		BaseType: types.FIT_TYPE_UINT32,
+		Array:    false,
+		Scale:    1,
+		Offset:   0,
+		Units:    "",
+	}
+)
+
+// DeveloperDataId is the developer_data_id message. Must be logged before field description
+type DeveloperDataId struct {
+	DeveloperId        []types.FitByte
+	ApplicationId      []types.FitByte
+	ManufacturerId     types.FitUint16
+	DeveloperDataIndex types.FitUint8
+	ApplicationVersion types.FitUint32
+}
+
+func (DeveloperDataId) Info() *MessageInfo {
+	return DeveloperDataIdInfo
+}
+
+func (m DeveloperDataId) value(fieldNum types.FitUint8) interface{} {
+	switch fieldNum {
+	case 0:
+		return m.DeveloperId
+	case 1:
+		return m.ApplicationId
+	case 2:
+		return m.ManufacturerId
+	case 3:
+		return m.DeveloperDataIndex
+	case 4:
+		return m.ApplicationVersion
+	}
+	return nil
+}
+
+var messages = map[types.FitUint16]*MessageInfo{
+	FIT_MESG_NUM_FILE_ID:           FileIdInfo,
+	FIT_MESG_NUM_FILE_CREATOR:      FileCreatorInfo,
+	FIT_MESG_NUM_SESSION:           SessionInfo,
+	FIT_MESG_NUM_LAP:               LapInfo,
+	FIT_MESG_NUM_RECORD:            RecordInfo,
+	FIT_MESG_NUM_EVENT:             EventInfo,
+	FIT_MESG_NUM_DEVICE_INFO:       DeviceInfoInfo,
+	FIT_MESG_NUM_ACTIVITY:          ActivityInfo,
+	FIT_MESG_NUM_WORKOUT:           WorkoutInfo,
+	FIT_MESG_NUM_WORKOUT_STEP:      WorkoutStepInfo,
+	FIT_MESG_NUM_FIELD_DESCRIPTION: FieldDescriptionInfo,
+	FIT_MESG_NUM_DEVELOPER_DATA_ID: DeveloperDataIdInfo,
+}
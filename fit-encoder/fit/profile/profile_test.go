@@ -0,0 +1,165 @@
+// Copyright 2021 Artem Mikheev
+
+package profile
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeConversion(t *testing.T) {
+	a := assert.New(t)
+
+	created := time.Date(2022, time.April, 1, 12, 35, 23, 0, time.UTC)
+	a.Equal(types.FitUint32(1017750923), EncodeTime(created))
+	a.Equal(created, DecodeTime(1017750923))
+}
+
+func TestRecordValues(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	record := Record{
+		PositionLat:      59.92349294945598,
+		PositionLong:     30.30527399852872,
+		Distance:         27.25,
+		Speed:            0.107,
+		GpsAccuracy:      11,
+		EnhancedAltitude: 20.8,
+	}
+	def, err := Definition(record,
+		RecordPositionLat, RecordPositionLong, RecordEnhancedAltitude,
+		RecordSpeed, RecordGpsAccuracy, RecordDistance)
+	r.NoError(err)
+	a.Equal(FIT_MESG_NUM_RECORD, def.GlobalMsgNum)
+	a.Equal(&fit.FieldDefinition{DefNum: 6, Size: 2, BaseType: types.FIT_TYPE_UINT16}, def.FieldDefs[3])
+
+	values, err := Values(record, def)
+	r.NoError(err)
+	// raw values of the same record in research/uploaded-activity-real.fit
+	a.Equal([]interface{}{
+		types.FitSint32(714915118), types.FitSint32(361556002), types.FitUint32(2604),
+		types.FitUint16(107), types.FitUint8(11), types.FitUint32(2725),
+	}, values)
+
+	data, err := def.ConstructLocalMessage(0).ConstructData(values...)
+	r.NoError(err)
+	a.Equal(types.FitUint16(107), data.Value(6))
+
+	// Error tests
+	_, err = Definition(record, LapTotalDistance)
+	a.ErrorIs(err, ErrUnknownField)
+
+	_, err = Values(Lap{}, def)
+	a.ErrorIs(err, ErrUnknownField)
+
+	_, err = Values(Record{Speed: -1}, def)
+	a.ErrorIs(err, ErrValueOutOfRange)
+
+	_, err = Values(Record{Speed: 70}, def)
+	a.ErrorIs(err, ErrValueOutOfRange)
+}
+
+func TestFieldValueConversion(t *testing.T) {
+	a := assert.New(t)
+
+	raw, err := RecordAltitude.RawValue(-100.0)
+	a.NoError(err)
+	a.Equal(types.FitUint16(2000), raw)
+
+	value, err := RecordAltitude.Value(types.FitUint16(2000))
+	a.NoError(err)
+	a.Equal(-100.0, value)
+
+	value, err = RecordPositionLat.Value(types.FitSint32(1 << 30))
+	a.NoError(err)
+	a.Equal(90.0, value)
+
+	value, err = SessionStartTime.Value(types.FitUint32(0))
+	a.NoError(err)
+	a.Equal(FitEpoch, value)
+
+	value, err = SessionNumLaps.Value(types.FitUint16(3))
+	a.NoError(err)
+	a.Equal(types.FitUint16(3), value)
+
+	_, err = SessionStartTime.Value(types.FitUint16(0))
+	a.Error(err)
+}
+
+func TestStringAndArrayFields(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	devDataId := DeveloperDataId{
+		ApplicationId:      []types.FitByte{0xDE, 0xAD, 0xBE, 0xEF},
+		DeveloperDataIndex: 1,
+	}
+	devDataIdDef, err := Definition(devDataId, DeveloperDataIdApplicationId, DeveloperDataIdDeveloperDataIndex)
+	r.NoError(err)
+	a.Equal(types.FitUint8(4), devDataIdDef.FieldDefs[0].Size)
+
+	fieldDesc := FieldDescription{
+		DeveloperDataIndex:    1,
+		FieldDefinitionNumber: 0,
+		FitBaseTypeId:         types.FIT_TYPE_UINT16,
+		FieldName:             "power",
+	}
+	fieldDescDef, err := Definition(fieldDesc,
+		FieldDescriptionDeveloperDataIndex, FieldDescriptionFieldDefinitionNumber,
+		FieldDescriptionFitBaseTypeId, FieldDescriptionFieldName)
+	r.NoError(err)
+	a.Equal(types.FitUint8(len("power")+1), fieldDescDef.FieldDefs[3].Size)
+
+	// encode both messages and make sure the decoder picks them up
+	file := new(fit.FitFile)
+	for _, message := range []struct {
+		msg Message
+		def *fit.DefinitionMessage
+	}{{devDataId, devDataIdDef}, {fieldDesc, fieldDescDef}} {
+		values, err := Values(message.msg, message.def)
+		r.NoError(err)
+		localDef := message.def.ConstructLocalMessage(0)
+		data, err := localDef.ConstructData(values...)
+		r.NoError(err)
+		r.NoError(file.AddMessage(localDef))
+		r.NoError(file.AddMessage(data))
+	}
+
+	buffer := new(bytes.Buffer)
+	r.NoError(file.Encode(buffer, encoding.LittleEndian))
+	decoded, err := fit.Decode(buffer)
+	r.NoError(err)
+	dataMessages := decoded.DataMessages()
+	r.Len(dataMessages, 2)
+	a.Equal([]types.FitByte{0xDE, 0xAD, 0xBE, 0xEF}, dataMessages[0].Value(1))
+	a.Equal(types.FitString("power"), dataMessages[1].Value(3))
+
+	// Error tests
+	_, err = Definition(DeveloperDataId{}, DeveloperDataIdApplicationId)
+	a.ErrorIs(err, ErrInvalidSize)
+}
+
+func TestLookup(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(RecordInfo, Lookup(FIT_MESG_NUM_RECORD))
+	a.Equal(RecordSpeed, Lookup(FIT_MESG_NUM_RECORD).Field(6))
+	a.Nil(Lookup(FIT_MESG_NUM_RECORD).Field(200))
+	a.Nil(Lookup(0xFF00))
+
+	// every generated field must belong to its message
+	for mesgNum, info := range messages {
+		a.Equal(mesgNum, info.Num)
+		for _, field := range info.Fields {
+			a.Equalf(info.Num, field.MesgNum, "%s.%s", info.Name, field.Name)
+		}
+	}
+}
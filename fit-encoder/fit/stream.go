@@ -0,0 +1,153 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"io"
+	"os"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+// StreamEncoder encodes definition and data messages directly into
+// the underlying writer as they are added, incrementally updating the crc,
+// instead of keeping them all in memory like FitFile does. The header
+// is written as a placeholder and patched with the data size on Close.
+type StreamEncoder struct {
+	wr            io.Writer
+	ws            io.WriteSeeker
+	tmp           *os.File
+	headerPos     int64
+	header        HeaderOptions
+	endianness    encoding.Endianness
+	data          *dataWriter
+	timestamps    timestampTracker
+	localMsgTypes localMsgTypes
+	closed        bool
+}
+
+// NewStreamEncoder creates a new encoder writing a single fit file to wr.
+// If wr is an io.WriteSeeker the messages are streamed straight into it,
+// otherwise they are spooled into a temporary file and copied on Close.
+func NewStreamEncoder(wr io.Writer, endianness encoding.Endianness) (*StreamEncoder, error) {
+	return NewStreamEncoderWithHeader(wr, endianness, HeaderOptions{})
+}
+
+// NewStreamEncoderWithHeader is the same as NewStreamEncoder, but the file is encoded
+// with the given header options. Since the file crc of a file with a legacy header
+// depends on the header, such files are always spooled into a temporary file.
+func NewStreamEncoderWithHeader(wr io.Writer, endianness encoding.Endianness, header HeaderOptions) (*StreamEncoder, error) {
+	if !endianness.IsKnown() {
+		return nil, encoding.ErrUnknownEndianness
+	}
+
+	enc := &StreamEncoder{
+		wr:         wr,
+		header:     header,
+		endianness: endianness,
+	}
+
+	// not every io.WriteSeeker is actually seekable (e.g. pipes),
+	// so check that seeking works before relying on it
+	if ws, ok := wr.(io.WriteSeeker); ok && !header.Legacy {
+		if pos, err := ws.Seek(0, io.SeekCurrent); err == nil {
+			enc.ws, enc.headerPos = ws, pos
+		}
+	}
+
+	if enc.ws != nil {
+		// write the header placeholder, it will be overwritten during Close
+		if _, err := wr.Write(encodeFileHeader(0, header)); err != nil {
+			return nil, err
+		}
+		enc.data = newDataWriter(wr)
+	} else {
+		tmp, err := os.CreateTemp("", "fit-stream-*")
+		if err != nil {
+			return nil, err
+		}
+		enc.tmp = tmp
+		enc.data = newDataWriter(tmp)
+	}
+	return enc, nil
+}
+
+// AddMessage encodes the definition or data message into the stream
+func (enc *StreamEncoder) AddMessage(message encoding.EndianEncoder) error {
+	if enc.closed {
+		return ErrEncoderClosed
+	}
+	if err := validateMessage(message, &enc.localMsgTypes); err != nil {
+		return err
+	}
+	for _, encodable := range enc.timestamps.messagesToEncode(message) {
+		if err := encodable.Encode(enc.data, enc.endianness); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (enc *StreamEncoder) AddData(def *DefinitionMessage, values ...interface{}) error {
+	return enc.localMsgTypes.addData(enc.AddMessage, def, false, 0, values)
+}
+
+func (enc *StreamEncoder) AddCompressedData(def *DefinitionMessage, timestamp types.FitUint32, values ...interface{}) error {
+	return enc.localMsgTypes.addData(enc.AddMessage, def, true, timestamp, values)
+}
+
+// Close finishes the fit file by writing the data crc and patching the header.
+// The underlying writer is left positioned at the end of the encoded file.
+func (enc *StreamEncoder) Close() error {
+	if enc.closed {
+		return ErrEncoderClosed
+	}
+	enc.closed = true
+
+	if enc.tmp != nil {
+		defer func() {
+			enc.tmp.Close()
+			os.Remove(enc.tmp.Name())
+		}()
+	}
+
+	dataSize, err := enc.data.dataSize()
+	if err != nil {
+		return err
+	}
+	header := encodeFileHeader(dataSize, enc.header)
+	dataCrc := enc.data.crc.Sum(nil)
+
+	if enc.tmp != nil {
+		// the crc is recomputed while copying, since it also covers the legacy header
+		crcWriter := newDataWriter(enc.wr)
+		if _, err := crcWriter.Write(header); err != nil {
+			return err
+		}
+		if _, err := enc.tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.Copy(crcWriter, enc.tmp); err != nil {
+			return err
+		}
+		_, err := enc.wr.Write(crcWriter.crc.Sum(nil))
+		return err
+	}
+
+	if _, err := enc.wr.Write(dataCrc); err != nil {
+		return err
+	}
+	end, err := enc.ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := enc.ws.Seek(enc.headerPos, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := enc.wr.Write(header); err != nil {
+		return err
+	}
+	_, err = enc.ws.Seek(end, io.SeekStart)
+	return err
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addFileIdMessages(r *require.Assertions, wr MessageWriter) {
+	fileIdMsgDef := &DefinitionMessage{
+		GlobalMsgNum: 0,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   1,
+				Size:     types.FIT_TYPE_UINT16_SIZE,
+				BaseType: types.FIT_TYPE_UINT16,
+			},
+			{
+				DefNum:   2,
+				Size:     types.FIT_TYPE_UINT16_SIZE,
+				BaseType: types.FIT_TYPE_UINT16,
+			},
+			{
+				DefNum:   0,
+				Size:     types.FIT_TYPE_ENUM_SIZE,
+				BaseType: types.FIT_TYPE_ENUM,
+			},
+			{
+				DefNum:   4,
+				Size:     types.FIT_TYPE_UINT32_SIZE,
+				BaseType: types.FIT_TYPE_UINT32,
+			},
+		},
+		DevFieldDefs: nil,
+	}
+
+	localFileIdMsgDef := fileIdMsgDef.ConstructLocalMessage(0)
+	r.NoError(wr.AddMessage(localFileIdMsgDef))
+
+	localFileIdMsg, err := localFileIdMsgDef.ConstructData(265, 102, 4, 1007562558)
+	r.NoError(err, "construction of valid file id")
+	r.NoError(wr.AddMessage(localFileIdMsg))
+}
+
+func TestStreamEncoding(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	fitFileBytes, _ := hex.DecodeString(
+		"0e2054081c0000002e464954b8884000010000040102840202840001000404860001090066043c0e2f3ede5f",
+	)
+
+	// seekable writer, with some data already present before the fit file
+	file, err := os.Create(filepath.Join(t.TempDir(), "stream.fit"))
+	r.NoError(err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("prefix"))
+	r.NoError(err)
+	seekingEncoder, err := NewStreamEncoder(file, encoding.BigEndian)
+	r.NoError(err)
+	addFileIdMessages(r, seekingEncoder)
+	r.NoError(seekingEncoder.Close())
+
+	_, err = file.Write([]byte("suffix"))
+	r.NoError(err)
+	_, err = file.Seek(0, io.SeekStart)
+	r.NoError(err)
+	written, err := io.ReadAll(file)
+	r.NoError(err)
+	a.Equal(append(append([]byte("prefix"), fitFileBytes...), []byte("suffix")...), written)
+
+	// non-seekable writer falls back to a temporary file
+	buffer := new(bytes.Buffer)
+	spoolingEncoder, err := NewStreamEncoder(buffer, encoding.BigEndian)
+	r.NoError(err)
+	addFileIdMessages(r, spoolingEncoder)
+	r.NoError(spoolingEncoder.Close())
+	a.Equal(fitFileBytes, buffer.Bytes())
+
+	// Error tests
+	a.ErrorIs(spoolingEncoder.AddMessage(&localDataMessage{}), ErrEncoderClosed)
+	a.ErrorIs(spoolingEncoder.Close(), ErrEncoderClosed)
+
+	_, err = NewStreamEncoder(buffer, encoding.Endianness(123))
+	a.ErrorIs(err, encoding.ErrUnknownEndianness)
+
+	invalidEncoder, err := NewStreamEncoder(new(bytes.Buffer), encoding.LittleEndian)
+	r.NoError(err)
+	a.ErrorIs(invalidEncoder.AddMessage(types.FitUint8(0)), ErrInvalidMessage)
+	a.NoError(invalidEncoder.Close())
+}
+
+func TestChainedFitFiles(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	first := new(FitFile)
+	addFileIdMessages(r, first)
+	second := &FitFile{
+		Header: HeaderOptions{ProtocolVersion: 0x10, ProfileVersion: 2132, Legacy: true},
+	}
+	addFileIdMessages(r, second)
+
+	buffer := new(bytes.Buffer)
+	r.NoError(EncodeChained(buffer, encoding.LittleEndian, first, second, first))
+
+	files, err := DecodeChained(bytes.NewReader(buffer.Bytes()))
+	r.NoError(err)
+	r.Len(files, 3)
+	a.Equal(HeaderOptions{ProtocolVersion: DefaultProtocolVersion, ProfileVersion: DefaultProfileVersion}, files[0].Header)
+	a.Equal(second.Header, files[1].Header)
+	for _, file := range files {
+		dataMessages := file.DataMessages()
+		r.Len(dataMessages, 1)
+		a.Equal(types.FitUint16(265), dataMessages[0].Value(1))
+	}
+
+	// the stream encoder must encode the legacy header in the same way
+	legacyBuffer := new(bytes.Buffer)
+	r.NoError(second.Encode(legacyBuffer, encoding.LittleEndian))
+	a.Equal(byte(fitLegacyHeaderSize), legacyBuffer.Bytes()[0])
+
+	streamFile, err := os.Create(filepath.Join(t.TempDir(), "legacy.fit"))
+	r.NoError(err)
+	defer streamFile.Close()
+	streamEncoder, err := NewStreamEncoderWithHeader(streamFile, encoding.LittleEndian, second.Header)
+	r.NoError(err)
+	addFileIdMessages(r, streamEncoder)
+	r.NoError(streamEncoder.Close())
+	_, err = streamFile.Seek(0, io.SeekStart)
+	r.NoError(err)
+	written, err := io.ReadAll(streamFile)
+	r.NoError(err)
+	a.Equal(legacyBuffer.Bytes(), written)
+
+	// Error tests
+	_, err = DecodeChained(bytes.NewReader(nil))
+	a.ErrorIs(err, io.EOF)
+
+	_, err = DecodeChained(bytes.NewReader(append(buffer.Bytes(), 0x0E, 0x20)))
+	a.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	corrupted := append([]byte{}, buffer.Bytes()...)
+	corrupted[len(corrupted)-1] ^= 0xFF
+	_, err = DecodeChained(bytes.NewReader(corrupted))
+	a.ErrorIs(err, ErrFileCrcMismatch)
+}
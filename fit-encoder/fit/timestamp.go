@@ -0,0 +1,61 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+// timestampTracker keeps track of the last timestamp in a fit file,
+// which is the reference point for all compressed timestamp headers
+type timestampTracker struct {
+	last    types.FitUint32
+	hasLast bool
+}
+
+// canCompress returns true if timestamp can be represented
+// as a time offset relative to the last timestamp
+func (tt *timestampTracker) canCompress(timestamp types.FitUint32) bool {
+	return tt.hasLast && timestamp >= tt.last &&
+		timestamp-tt.last <= types.FitUint32(compressedTimeOffsetMask)
+}
+
+// expand calculates the full timestamp from a compressed time offset
+func (tt *timestampTracker) expand(timeOffset uint8) types.FitUint32 {
+	mask := types.FitUint32(compressedTimeOffsetMask)
+	return tt.last + ((types.FitUint32(timeOffset) - tt.last) & mask)
+}
+
+func (tt *timestampTracker) update(timestamp types.FitUint32) {
+	tt.last = timestamp
+	tt.hasLast = true
+}
+
+// messagesToEncode returns the messages which should actually be encoded
+// in place of message. Messages with compressed timestamps which can't be
+// compressed anymore are replaced with a definition and data message containing
+// the full timestamp, after which the original definition is restored.
+func (tt *timestampTracker) messagesToEncode(message encoding.EndianEncoder) []encoding.EndianEncoder {
+	localDataMsg, ok := message.(*localDataMessage)
+	if !ok {
+		return []encoding.EndianEncoder{message}
+	}
+
+	timestamp, ok := localDataMsg.Timestamp()
+	if !ok {
+		return []encoding.EndianEncoder{message}
+	}
+
+	canCompress := tt.canCompress(timestamp)
+	tt.update(timestamp)
+	if !localDataMsg.compressedTimestamp || canCompress {
+		return []encoding.EndianEncoder{message}
+	}
+
+	fullDefMsg, fullDataMsg := localDataMsg.withFullTimestamp()
+	return []encoding.EndianEncoder{
+		fullDefMsg, fullDataMsg,
+		localDataMsg.def.ConstructLocalMessage(localDataMsg.localMsgType),
+	}
+}
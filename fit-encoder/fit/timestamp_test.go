@@ -0,0 +1,145 @@
+// Copyright 2021 Artem Mikheev
+
+package fit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedTimestampHeader(t *testing.T) {
+	a := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	a.NoError(encodeCompressedTimestampHeader(buffer, 2, 0x3F))
+	a.Equal([]byte{0xDF}, buffer.Bytes())
+	a.ErrorIs(encodeCompressedTimestampHeader(buffer, 4, 0), ErrInvalidCompressedLocalMsgType)
+}
+
+func TestCompressedTimestampEncoding(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	eventMsgDef := &DefinitionMessage{
+		GlobalMsgNum: FIT_MESG_NUM_EVENT,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   253,
+				Size:     types.FIT_TYPE_UINT32_SIZE,
+				BaseType: types.FIT_TYPE_UINT32,
+			},
+		},
+	}
+	recordMsgDef := &DefinitionMessage{
+		GlobalMsgNum: FIT_MESG_NUM_RECORD,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   5,
+				Size:     types.FIT_TYPE_UINT32_SIZE,
+				BaseType: types.FIT_TYPE_UINT32,
+			},
+		},
+	}
+
+	localEventMsgDef := eventMsgDef.ConstructLocalMessage(0)
+	localRecordMsgDef := recordMsgDef.ConstructLocalMessage(1)
+
+	timestamps := []types.FitUint32{1000, 1001, 1031, 1100, 1101}
+	// only the record after the gap needs a full timestamp
+	expectCompressed := []bool{true, true, true, false, true}
+
+	fitFile := new(FitFile)
+	r.NoError(fitFile.AddMessage(localEventMsgDef))
+	eventMsg, err := localEventMsgDef.ConstructData(1000)
+	r.NoError(err)
+	r.NoError(fitFile.AddMessage(eventMsg))
+	r.NoError(fitFile.AddMessage(localRecordMsgDef))
+	for i, timestamp := range timestamps {
+		recordMsg, err := localRecordMsgDef.ConstructCompressedData(timestamp, i)
+		r.NoError(err)
+		r.NoError(fitFile.AddMessage(recordMsg))
+	}
+
+	buffer := new(bytes.Buffer)
+	r.NoError(fitFile.Encode(buffer, encoding.LittleEndian))
+
+	// the stream encoder must make the same decisions
+	streamBuffer := new(bytes.Buffer)
+	streamEncoder, err := NewStreamEncoder(streamBuffer, encoding.LittleEndian)
+	r.NoError(err)
+	for _, message := range fitFile.messages {
+		r.NoError(streamEncoder.AddMessage(message))
+	}
+	r.NoError(streamEncoder.Close())
+	a.Equal(buffer.Bytes(), streamBuffer.Bytes())
+
+	decoded, err := Decode(bytes.NewReader(buffer.Bytes()))
+	r.NoError(err)
+	dataMessages := decoded.DataMessages()
+	r.Len(dataMessages, len(timestamps)+1)
+	for i, timestamp := range timestamps {
+		recordMsg := dataMessages[i+1]
+		decodedTimestamp, ok := recordMsg.Timestamp()
+		a.True(ok)
+		a.Equal(timestamp, decodedTimestamp)
+		a.Equal(expectCompressed[i], recordMsg.HasCompressedTimestamp())
+		a.Equal(types.FitUint32(i), recordMsg.Value(5))
+	}
+
+	// compressed messages without a preceding timestamp are always expanded
+	fitFile = new(FitFile)
+	r.NoError(fitFile.AddMessage(localRecordMsgDef))
+	recordMsg, err := localRecordMsgDef.ConstructCompressedData(1000, 0)
+	r.NoError(err)
+	r.NoError(fitFile.AddMessage(recordMsg))
+	buffer = new(bytes.Buffer)
+	r.NoError(fitFile.Encode(buffer, encoding.BigEndian))
+	decoded, err = Decode(bytes.NewReader(buffer.Bytes()))
+	r.NoError(err)
+	a.False(decoded.DataMessages()[0].HasCompressedTimestamp())
+
+	// Error tests
+	_, err = localEventMsgDef.ConstructCompressedData(1000, 1000)
+	a.ErrorIs(err, ErrTimestampFieldDefined)
+
+	_, err = recordMsgDef.ConstructLocalMessage(4).ConstructCompressedData(1000, 0)
+	a.ErrorIs(err, ErrInvalidCompressedLocalMsgType)
+
+	_, err = localRecordMsgDef.ConstructCompressedData(1000)
+	a.ErrorIs(err, ErrFieldNumMismatch)
+}
+
+func TestCompressedTimestampDecodingErrors(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	recordMsgDef := &DefinitionMessage{
+		GlobalMsgNum: FIT_MESG_NUM_RECORD,
+		FieldDefs: []*FieldDefinition{
+			{
+				DefNum:   5,
+				Size:     types.FIT_TYPE_UINT32_SIZE,
+				BaseType: types.FIT_TYPE_UINT32,
+			},
+		},
+	}
+	localRecordMsgDef := recordMsgDef.ConstructLocalMessage(1)
+	recordMsg, err := localRecordMsgDef.ConstructCompressedData(1000, 0)
+	r.NoError(err)
+
+	// encode the messages directly, without letting the encoder expand the timestamp
+	data := new(bytes.Buffer)
+	r.NoError(localRecordMsgDef.Encode(data, encoding.LittleEndian))
+	r.NoError(recordMsg.Encode(data, encoding.LittleEndian))
+
+	dec := newDecoder(data)
+	_, err = dec.decodeMessage()
+	r.NoError(err)
+	_, err = dec.decodeMessage()
+	a.ErrorIs(err, ErrMissingTimestamp)
+}
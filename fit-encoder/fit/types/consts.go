@@ -4,6 +4,7 @@ package types
 
 import (
 	"errors"
+	"math"
 	"reflect"
 )
 
@@ -20,6 +21,10 @@ const (
 	FIT_TYPE_FLOAT64_SIZE FitUint8 = 8
 	FIT_TYPE_UINT64_SIZE  FitUint8 = 8
 	FIT_TYPE_SINT64_SIZE  FitUint8 = FIT_TYPE_UINT64_SIZE
+	FIT_TYPE_UINT8Z_SIZE  FitUint8 = FIT_TYPE_UINT8_SIZE
+	FIT_TYPE_UINT16Z_SIZE FitUint8 = FIT_TYPE_UINT16_SIZE
+	FIT_TYPE_UINT32Z_SIZE FitUint8 = FIT_TYPE_UINT32_SIZE
+	FIT_TYPE_UINT64Z_SIZE FitUint8 = FIT_TYPE_UINT64_SIZE
 )
 
 const (
@@ -61,30 +66,71 @@ var FitTypeSize = map[FitBaseType]FitUint8{
 	FIT_TYPE_FLOAT64: FIT_TYPE_FLOAT64_SIZE,
 	FIT_TYPE_SINT64:  FIT_TYPE_SINT64_SIZE,
 	FIT_TYPE_UINT64:  FIT_TYPE_UINT64_SIZE,
+	FIT_TYPE_UINT8Z:  FIT_TYPE_UINT8Z_SIZE,
+	FIT_TYPE_UINT16Z: FIT_TYPE_UINT16Z_SIZE,
+	FIT_TYPE_UINT32Z: FIT_TYPE_UINT32Z_SIZE,
+	FIT_TYPE_UINT64Z: FIT_TYPE_UINT64Z_SIZE,
+	FIT_TYPE_BYTE:    FIT_TYPE_BYTE_SIZE,
 }
 
 var FitTypeMap = map[FitBaseType]reflect.Type{
-	FIT_TYPE_ENUM:   reflect.TypeOf((*FitEnum)(nil)).Elem(),
-	FIT_TYPE_SINT8:  reflect.TypeOf((*FitSint8)(nil)).Elem(),
-	FIT_TYPE_UINT8:  reflect.TypeOf((*FitUint8)(nil)).Elem(),
-	FIT_TYPE_SINT16: reflect.TypeOf((*FitSint16)(nil)).Elem(),
-	FIT_TYPE_UINT16: reflect.TypeOf((*FitUint16)(nil)).Elem(),
-	FIT_TYPE_SINT32: reflect.TypeOf((*FitSint32)(nil)).Elem(),
-	FIT_TYPE_UINT32: reflect.TypeOf((*FitUint32)(nil)).Elem(),
-	FIT_TYPE_SINT64: reflect.TypeOf((*FitSint64)(nil)).Elem(),
-	FIT_TYPE_UINT64: reflect.TypeOf((*FitUint64)(nil)).Elem(),
-	FIT_TYPE_STRING: reflect.TypeOf((*FitString)(nil)).Elem(),
+	FIT_TYPE_ENUM:    reflect.TypeOf((*FitEnum)(nil)).Elem(),
+	FIT_TYPE_SINT8:   reflect.TypeOf((*FitSint8)(nil)).Elem(),
+	FIT_TYPE_UINT8:   reflect.TypeOf((*FitUint8)(nil)).Elem(),
+	FIT_TYPE_SINT16:  reflect.TypeOf((*FitSint16)(nil)).Elem(),
+	FIT_TYPE_UINT16:  reflect.TypeOf((*FitUint16)(nil)).Elem(),
+	FIT_TYPE_SINT32:  reflect.TypeOf((*FitSint32)(nil)).Elem(),
+	FIT_TYPE_UINT32:  reflect.TypeOf((*FitUint32)(nil)).Elem(),
+	FIT_TYPE_SINT64:  reflect.TypeOf((*FitSint64)(nil)).Elem(),
+	FIT_TYPE_UINT64:  reflect.TypeOf((*FitUint64)(nil)).Elem(),
+	FIT_TYPE_STRING:  reflect.TypeOf((*FitString)(nil)).Elem(),
+	FIT_TYPE_FLOAT32: reflect.TypeOf((*FitFloat32)(nil)).Elem(),
+	FIT_TYPE_FLOAT64: reflect.TypeOf((*FitFloat64)(nil)).Elem(),
+	FIT_TYPE_UINT8Z:  reflect.TypeOf((*FitUint8z)(nil)).Elem(),
+	FIT_TYPE_UINT16Z: reflect.TypeOf((*FitUint16z)(nil)).Elem(),
+	FIT_TYPE_UINT32Z: reflect.TypeOf((*FitUint32z)(nil)).Elem(),
+	FIT_TYPE_UINT64Z: reflect.TypeOf((*FitUint64z)(nil)).Elem(),
+	FIT_TYPE_BYTE:    reflect.TypeOf((*FitByte)(nil)).Elem(),
 }
 
 var implementedFitTypes = map[reflect.Type]bool{
-	FitTypeMap[FIT_TYPE_ENUM]:   true,
-	FitTypeMap[FIT_TYPE_SINT8]:  true,
-	FitTypeMap[FIT_TYPE_UINT8]:  true,
-	FitTypeMap[FIT_TYPE_SINT16]: true,
-	FitTypeMap[FIT_TYPE_UINT16]: true,
-	FitTypeMap[FIT_TYPE_SINT32]: true,
-	FitTypeMap[FIT_TYPE_UINT32]: true,
-	FitTypeMap[FIT_TYPE_SINT64]: true,
-	FitTypeMap[FIT_TYPE_UINT64]: true,
-	FitTypeMap[FIT_TYPE_STRING]: true,
+	FitTypeMap[FIT_TYPE_ENUM]:    true,
+	FitTypeMap[FIT_TYPE_SINT8]:   true,
+	FitTypeMap[FIT_TYPE_UINT8]:   true,
+	FitTypeMap[FIT_TYPE_SINT16]:  true,
+	FitTypeMap[FIT_TYPE_UINT16]:  true,
+	FitTypeMap[FIT_TYPE_SINT32]:  true,
+	FitTypeMap[FIT_TYPE_UINT32]:  true,
+	FitTypeMap[FIT_TYPE_SINT64]:  true,
+	FitTypeMap[FIT_TYPE_UINT64]:  true,
+	FitTypeMap[FIT_TYPE_STRING]:  true,
+	FitTypeMap[FIT_TYPE_FLOAT32]: true,
+	FitTypeMap[FIT_TYPE_FLOAT64]: true,
+	FitTypeMap[FIT_TYPE_UINT8Z]:  true,
+	FitTypeMap[FIT_TYPE_UINT16Z]: true,
+	FitTypeMap[FIT_TYPE_UINT32Z]: true,
+	FitTypeMap[FIT_TYPE_UINT64Z]: true,
+	FitTypeMap[FIT_TYPE_BYTE]:    true,
+}
+
+// FitTypeInvalidValue contains the value which is used to mark
+// a field of each base type as invalid (not set), as defined by the fit protocol
+var FitTypeInvalidValue = map[FitBaseType]interface{}{
+	FIT_TYPE_ENUM:    FitEnum(0xFF),
+	FIT_TYPE_SINT8:   FitSint8(0x7F),
+	FIT_TYPE_UINT8:   FitUint8(0xFF),
+	FIT_TYPE_SINT16:  FitSint16(0x7FFF),
+	FIT_TYPE_UINT16:  FitUint16(0xFFFF),
+	FIT_TYPE_SINT32:  FitSint32(0x7FFFFFFF),
+	FIT_TYPE_UINT32:  FitUint32(0xFFFFFFFF),
+	FIT_TYPE_SINT64:  FitSint64(0x7FFFFFFFFFFFFFFF),
+	FIT_TYPE_UINT64:  FitUint64(0xFFFFFFFFFFFFFFFF),
+	FIT_TYPE_STRING:  FitString(""),
+	FIT_TYPE_FLOAT32: FitFloat32(math.Float32frombits(0xFFFFFFFF)),
+	FIT_TYPE_FLOAT64: FitFloat64(math.Float64frombits(0xFFFFFFFFFFFFFFFF)),
+	FIT_TYPE_UINT8Z:  FitUint8z(0),
+	FIT_TYPE_UINT16Z: FitUint16z(0),
+	FIT_TYPE_UINT32Z: FitUint32z(0),
+	FIT_TYPE_UINT64Z: FitUint64z(0),
+	FIT_TYPE_BYTE:    FitByte(0xFF),
 }
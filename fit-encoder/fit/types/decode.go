@@ -0,0 +1,240 @@
+// Copyright 2021 Artem Mikheev
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+)
+
+// readEndian reads exactly size bytes from rd and
+// returns the byte order which should be used to interpret them
+func readEndian(rd io.Reader, size int, endianness encoding.Endianness) ([]byte, binary.ByteOrder, error) {
+	var order binary.ByteOrder
+	switch endianness {
+	case encoding.LittleEndian:
+		order = binary.LittleEndian
+	case encoding.BigEndian:
+		order = binary.BigEndian
+	default:
+		panic(encoding.ErrUnknownEndianness)
+	}
+
+	decoded := make([]byte, size)
+	if _, err := io.ReadFull(rd, decoded); err != nil {
+		return nil, nil, err
+	}
+	return decoded, order, nil
+}
+
+func (value *FitUint8) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	decoded, _, err := readEndian(rd, 1, endianness)
+	if err != nil {
+		return err
+	}
+	*value = FitUint8(decoded[0])
+	return nil
+}
+
+func (value *FitSint8) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint8
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitSint8(unsigned)
+	return nil
+}
+
+func (value *FitEnum) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	return (*FitUint8)(value).Decode(rd, endianness)
+}
+
+func (value *FitUint16) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	decoded, order, err := readEndian(rd, 2, endianness)
+	if err != nil {
+		return err
+	}
+	*value = FitUint16(order.Uint16(decoded))
+	return nil
+}
+
+func (value *FitSint16) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint16
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitSint16(unsigned)
+	return nil
+}
+
+func (value *FitUint32) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	decoded, order, err := readEndian(rd, 4, endianness)
+	if err != nil {
+		return err
+	}
+	*value = FitUint32(order.Uint32(decoded))
+	return nil
+}
+
+func (value *FitSint32) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint32
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitSint32(unsigned)
+	return nil
+}
+
+func (value *FitUint64) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	decoded, order, err := readEndian(rd, 8, endianness)
+	if err != nil {
+		return err
+	}
+	*value = FitUint64(order.Uint64(decoded))
+	return nil
+}
+
+func (value *FitSint64) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint64
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitSint64(unsigned)
+	return nil
+}
+
+func (value *FitUint8z) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint8
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitUint8z(unsigned)
+	return nil
+}
+
+func (value *FitUint16z) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint16
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitUint16z(unsigned)
+	return nil
+}
+
+func (value *FitUint32z) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint32
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitUint32z(unsigned)
+	return nil
+}
+
+func (value *FitUint64z) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint64
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitUint64z(unsigned)
+	return nil
+}
+
+func (value *FitByte) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var unsigned FitUint8
+	if err := unsigned.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitByte(unsigned)
+	return nil
+}
+
+func (value *FitFloat32) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var bits FitUint32
+	if err := bits.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitFloat32(math.Float32frombits(uint32(bits)))
+	return nil
+}
+
+func (value *FitFloat64) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	var bits FitUint64
+	if err := bits.Decode(rd, endianness); err != nil {
+		return err
+	}
+	*value = FitFloat64(math.Float64frombits(uint64(bits)))
+	return nil
+}
+
+// Decode reads exactly str.Length bytes and stores
+// everything before the first null byte as the string value
+func (str *FitEncodableString) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	decoded, _, err := readEndian(rd, int(str.Length), endianness)
+	if err != nil {
+		return err
+	}
+	if end := bytes.IndexByte(decoded, 0); end != -1 {
+		decoded = decoded[:end]
+	}
+	str.FitString = FitString(decoded)
+	return nil
+}
+
+func (value *FitBaseType) Decode(rd io.Reader, endianness encoding.Endianness) error {
+	return (*FitEnum)(value).Decode(rd, endianness)
+}
+
+// DecodeValue decodes a single value of the given base type and size,
+// returning it as the respective fit type (same as the ones accepted by ValidateValue).
+// Values of array fields, whose size is a multiple of the base size, are returned as a slice.
+// Values whose size isn't a multiple of the base size are returned as a byte slice, as per the spec.
+func DecodeValue(rd io.Reader, baseType FitBaseType, size FitUint8, endianness encoding.Endianness) (interface{}, error) {
+	fitType, ok := FitTypeMap[baseType]
+	if !ok {
+		return nil, ErrUnknownFitType
+	}
+
+	if baseType == FIT_TYPE_STRING {
+		str := &FitEncodableString{Length: size}
+		if err := str.Decode(rd, endianness); err != nil {
+			return nil, err
+		}
+		return str.FitString, nil
+	}
+
+	properSize := FitTypeSize[baseType]
+	if size == 0 || size%properSize != 0 {
+		decoded, _, err := readEndian(rd, int(size), endianness)
+		if err != nil {
+			return nil, err
+		}
+		array := make([]FitByte, len(decoded))
+		for i := range decoded {
+			array[i] = FitByte(decoded[i])
+		}
+		return array, nil
+	}
+
+	if size == properSize {
+		value := reflect.New(fitType)
+		if err := value.Interface().(encoding.EndianDecoder).Decode(rd, endianness); err != nil {
+			return nil, err
+		}
+		return value.Elem().Interface(), nil
+	}
+
+	// array fields are decoded as a slice of values
+	length := int(size / properSize)
+	array := reflect.MakeSlice(reflect.SliceOf(fitType), length, length)
+	for i := 0; i < length; i++ {
+		if err := array.Index(i).Addr().Interface().(encoding.EndianDecoder).Decode(rd, endianness); err != nil {
+			return nil, err
+		}
+	}
+	return array.Interface(), nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 Artem Mikheev
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoding(t *testing.T) {
+	a := assert.New(t)
+
+	decode := func(b []byte, baseType FitBaseType, size FitUint8, endianness encoding.Endianness) interface{} {
+		value, err := DecodeValue(bytes.NewReader(b), baseType, size, endianness)
+		if !a.NoErrorf(err, "unexpected error decoding %v as %v", b, baseType) {
+			return nil
+		}
+		return value
+	}
+
+	a.Equal(FitUint8(0x69), decode([]byte{0x69}, FIT_TYPE_UINT8, 1, encoding.LittleEndian))
+	a.Equal(FitSint8(-100), decode([]byte{0x9c}, FIT_TYPE_SINT8, 1, encoding.BigEndian))
+	a.Equal(FitEnum(19), decode([]byte{0x13}, FIT_TYPE_ENUM, 1, encoding.BigEndian))
+	a.Equal(FitUint16(0xA932), decode([]byte{0x32, 0xA9}, FIT_TYPE_UINT16, 2, encoding.LittleEndian))
+	a.Equal(FitSint16(-12398), decode([]byte{0xcf, 0x92}, FIT_TYPE_SINT16, 2, encoding.BigEndian))
+	a.Equal(FitUint32(0xEF73DAB8), decode([]byte{0xB8, 0xDA, 0x73, 0xEF}, FIT_TYPE_UINT32, 4, encoding.LittleEndian))
+	a.Equal(FitSint32(-1283723832), decode([]byte{0xb3, 0x7b, 0xed, 0xc8}, FIT_TYPE_SINT32, 4, encoding.BigEndian))
+	a.Equal(FitUint64(0x18B3EF73EF73DAB8),
+		decode([]byte{0x18, 0xB3, 0xEF, 0x73, 0xEF, 0x73, 0xDA, 0xB8}, FIT_TYPE_UINT64, 8, encoding.BigEndian))
+	a.Equal(FitSint64(-984593745873495847),
+		decode([]byte{0xd9, 0x98, 0x23, 0x69, 0x34, 0x05, 0x56, 0xf2}, FIT_TYPE_SINT64, 8, encoding.LittleEndian))
+	a.Equal(FitString("aboba"), decode([]byte{'a', 'b', 'o', 'b', 'a', 0, 0}, FIT_TYPE_STRING, 7, encoding.LittleEndian))
+	a.Equal(FitString("ab"), decode([]byte{'a', 'b'}, FIT_TYPE_STRING, 2, encoding.LittleEndian))
+
+	a.Equal(FitUint8z(0x12), decode([]byte{0x12}, FIT_TYPE_UINT8Z, 1, encoding.LittleEndian))
+	a.Equal(FitUint16z(0xA932), decode([]byte{0x32, 0xA9}, FIT_TYPE_UINT16Z, 2, encoding.LittleEndian))
+	a.Equal(FitUint32z(0x18B3EF73), decode([]byte{0x18, 0xB3, 0xEF, 0x73}, FIT_TYPE_UINT32Z, 4, encoding.BigEndian))
+	a.Equal(FitUint64z(0xEF73DAB818B3EF73),
+		decode([]byte{0x73, 0xEF, 0xB3, 0x18, 0xB8, 0xDA, 0x73, 0xEF}, FIT_TYPE_UINT64Z, 8, encoding.LittleEndian))
+	a.Equal(FitByte(0xAB), decode([]byte{0xAB}, FIT_TYPE_BYTE, 1, encoding.BigEndian))
+	a.Equal(FitFloat32(3.5), decode([]byte{0x00, 0x00, 0x60, 0x40}, FIT_TYPE_FLOAT32, 4, encoding.LittleEndian))
+	a.Equal(FitFloat64(-1.25), decode([]byte{0xBF, 0xF4, 0, 0, 0, 0, 0, 0}, FIT_TYPE_FLOAT64, 8, encoding.BigEndian))
+	a.True(FIT_TYPE_FLOAT32.IsInvalidValue(
+		decode([]byte{0xFF, 0xFF, 0xFF, 0xFF}, FIT_TYPE_FLOAT32, 4, encoding.LittleEndian)))
+
+	a.Equal([]FitUint16{0xA932, 0x0001},
+		decode([]byte{0x32, 0xA9, 0x01, 0x00}, FIT_TYPE_UINT16, 4, encoding.LittleEndian))
+	a.Equal([]FitByte{0xDE, 0xAD, 0xBE}, decode([]byte{0xDE, 0xAD, 0xBE}, FIT_TYPE_BYTE, 3, encoding.BigEndian))
+	// values with a size which isn't a multiple of the base size are decoded as bytes
+	a.Equal([]FitByte{0x01, 0x02}, decode([]byte{0x01, 0x02}, FIT_TYPE_UINT32, 2, encoding.LittleEndian))
+	a.Equal([]FitByte{0x01, 0x02, 0x03}, decode([]byte{0x01, 0x02, 0x03}, FIT_TYPE_UINT16, 3, encoding.LittleEndian))
+	a.Equal([]FitByte{0x01}, decode([]byte{0x01}, FIT_TYPE_UINT16, 1, encoding.LittleEndian))
+	a.Equal([]FitByte{}, decode(nil, FIT_TYPE_UINT16, 0, encoding.LittleEndian))
+
+	// Error tests
+	_, err := DecodeValue(bytes.NewReader([]byte{0x01}), FIT_TYPE_UINT16, 2, encoding.LittleEndian)
+	a.Error(err, "decoding of truncated value")
+
+	_, err = DecodeValue(bytes.NewReader([]byte{0x01, 0x02}), FIT_TYPE_UINT16, 4, encoding.LittleEndian)
+	a.Error(err, "decoding of truncated array")
+
+	_, err = DecodeValue(bytes.NewReader([]byte{0x01}), FIT_TYPE_INVALID, 1, encoding.LittleEndian)
+	a.ErrorIs(err, ErrUnknownFitType)
+
+	var value FitUint32
+	a.Panics(func() { value.Decode(bytes.NewReader([]byte{0, 0, 0, 0}), invalidEndianness) })
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 
 	"github.com/renbou/jogmock/fit-encoder/encoding"
@@ -29,9 +30,13 @@ type (
 	}
 	FitFloat32  float32
 	FitFloat64  float64
+	FitUint8z   uint8
+	FitUint16z  uint16
+	FitUint32z  uint32
 	FitByte     byte
 	FitSint64   int64
 	FitUint64   uint64
+	FitUint64z  uint64
 	FitBaseType FitEnum
 )
 
@@ -102,6 +107,34 @@ func (value FitSint64) Encode(wr io.Writer, endianness encoding.Endianness) erro
 	return FitUint64(value).Encode(wr, endianness)
 }
 
+func (value FitUint8z) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint8(value).Encode(wr, endianness)
+}
+
+func (value FitUint16z) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint16(value).Encode(wr, endianness)
+}
+
+func (value FitUint32z) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint32(value).Encode(wr, endianness)
+}
+
+func (value FitUint64z) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint64(value).Encode(wr, endianness)
+}
+
+func (value FitByte) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint8(value).Encode(wr, endianness)
+}
+
+func (value FitFloat32) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint32(math.Float32bits(float32(value))).Encode(wr, endianness)
+}
+
+func (value FitFloat64) Encode(wr io.Writer, endianness encoding.Endianness) error {
+	return FitUint64(math.Float64bits(float64(value))).Encode(wr, endianness)
+}
+
 func (str *FitEncodableString) Validate() error {
 	// a fit string must have an additional null byte
 	if int(str.Length) < len(str.FitString)+1 {
@@ -134,6 +167,38 @@ func (baseType FitBaseType) ValidateValue(value interface{}) error {
 	return nil
 }
 
+// ValidateArrayValue checks that value is a slice of the fit type of this base type,
+// which is how the values of array fields are represented
+func (baseType FitBaseType) ValidateArrayValue(value interface{}) error {
+	fitType, ok := FitTypeMap[baseType]
+	if !ok || baseType == FIT_TYPE_STRING || reflect.SliceOf(fitType) != reflect.TypeOf(value) {
+		return ErrFitBaseTypeMismatch
+	}
+	return nil
+}
+
+// InvalidValue returns the value marking a field of this base type as invalid
+func (baseType FitBaseType) InvalidValue() (interface{}, error) {
+	value, ok := FitTypeInvalidValue[baseType]
+	if !ok {
+		return nil, ErrUnknownFitType
+	}
+	return value, nil
+}
+
+// IsInvalidValue returns true if value is the invalid value of this base type.
+// Floats are compared bitwise, since their invalid value is a NaN.
+func (baseType FitBaseType) IsInvalidValue(value interface{}) bool {
+	switch v := value.(type) {
+	case FitFloat32:
+		return baseType == FIT_TYPE_FLOAT32 && math.Float32bits(float32(v)) == 0xFFFFFFFF
+	case FitFloat64:
+		return baseType == FIT_TYPE_FLOAT64 && math.Float64bits(float64(v)) == 0xFFFFFFFFFFFFFFFF
+	}
+	invalid, ok := FitTypeInvalidValue[baseType]
+	return ok && invalid == value
+}
+
 // IsImplemented returns true if t is an implemented fit type, false otherwise
 func IsFitType(t reflect.Type) bool {
 	return implementedFitTypes[t]
@@ -3,6 +3,7 @@ package types
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
 	"testing"
@@ -155,6 +156,40 @@ func TestEncoding(t *testing.T) {
 
 	a.Panics(func() { FitSint64(-123427349).Encode(nil, invalidEndianness) })
 
+	// z-types and byte
+	encode(FitUint8z(0x12), encoding.LittleEndian)
+	assert([]byte{0x12})
+
+	encode(FitUint16z(0xA932), encoding.LittleEndian)
+	assert([]byte{0x32, 0xA9})
+
+	encode(FitUint32z(0x18B3EF73), encoding.BigEndian)
+	assert([]byte{0x18, 0xB3, 0xEF, 0x73})
+
+	encode(FitUint64z(0xEF73DAB818B3EF73), encoding.LittleEndian)
+	assert([]byte{0x73, 0xEF, 0xB3, 0x18, 0xB8, 0xDA, 0x73, 0xEF})
+
+	encode(FitByte(0xAB), encoding.BigEndian)
+	assert([]byte{0xAB})
+
+	// float32
+	encode(FitFloat32(3.5), encoding.LittleEndian)
+	assert([]byte{0x00, 0x00, 0x60, 0x40})
+
+	encode(FitFloat32(-1.25), encoding.BigEndian)
+	assert([]byte{0xBF, 0xA0, 0x00, 0x00})
+
+	a.Panics(func() { FitFloat32(1).Encode(nil, invalidEndianness) })
+
+	// float64
+	encode(FitFloat64(3.5), encoding.LittleEndian)
+	assert([]byte{0, 0, 0, 0, 0, 0, 0x0C, 0x40})
+
+	encode(FitFloat64(-1.25), encoding.BigEndian)
+	assert([]byte{0xBF, 0xF4, 0, 0, 0, 0, 0, 0})
+
+	a.Panics(func() { FitFloat64(1).Encode(nil, invalidEndianness) })
+
 	// string
 	encode(&FitEncodableString{"aboba", 6}, encoding.LittleEndian)
 	assert([]byte{'a', 'b', 'o', 'b', 'a', 0})
@@ -183,4 +218,37 @@ func TestFitTypeValidation(t *testing.T) {
 	// implemented fit types should be checkable
 	a.False(IsFitType(reflect.TypeOf("not a fit string")))
 	a.True(IsFitType(reflect.TypeOf(FitString("is a fit string"))))
+
+	// array values are slices of the fit type
+	a.NoError(FIT_TYPE_UINT16.ValidateArrayValue([]FitUint16{1, 2}))
+	a.ErrorIs(FIT_TYPE_UINT16.ValidateArrayValue([]uint16{1, 2}), ErrFitBaseTypeMismatch)
+	a.ErrorIs(FIT_TYPE_UINT16.ValidateArrayValue(FitUint16(1)), ErrFitBaseTypeMismatch)
+	a.ErrorIs(FIT_TYPE_STRING.ValidateArrayValue([]FitString{"a"}), ErrFitBaseTypeMismatch)
+}
+
+func TestFitTypeInvalidValues(t *testing.T) {
+	a := assert.New(t)
+
+	// every implemented type must have an invalid value of the same type
+	for baseType, fitType := range FitTypeMap {
+		invalid, err := baseType.InvalidValue()
+		if a.NoErrorf(err, "invalid value of %v", baseType) {
+			a.Equalf(fitType, reflect.TypeOf(invalid), "invalid value type of %v", baseType)
+			a.Truef(baseType.IsInvalidValue(invalid), "invalid value of %v", baseType)
+		}
+	}
+
+	a.True(FIT_TYPE_UINT8Z.IsInvalidValue(FitUint8z(0)))
+	a.False(FIT_TYPE_UINT8Z.IsInvalidValue(FitUint8z(0xFF)))
+	a.True(FIT_TYPE_SINT16.IsInvalidValue(FitSint16(0x7FFF)))
+	a.False(FIT_TYPE_SINT16.IsInvalidValue(FitSint16(-1)))
+	a.False(FIT_TYPE_UINT16.IsInvalidValue(FitSint16(0x7FFF)))
+	a.True(FIT_TYPE_FLOAT32.IsInvalidValue(FitFloat32(math.Float32frombits(0xFFFFFFFF))))
+	a.False(FIT_TYPE_FLOAT32.IsInvalidValue(FitFloat32(math.NaN())))
+	a.False(FIT_TYPE_FLOAT64.IsInvalidValue(FitFloat64(0)))
+	a.False(FIT_TYPE_FLOAT64.IsInvalidValue(FitFloat32(math.Float32frombits(0xFFFFFFFF))))
+
+	_, err := FIT_TYPE_INVALID.InvalidValue()
+	a.ErrorIs(err, ErrUnknownFitType)
+	a.False(FIT_TYPE_INVALID.IsInvalidValue(FitUint8(0xFF)))
 }
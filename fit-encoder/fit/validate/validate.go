@@ -0,0 +1,395 @@
+// Copyright 2021 Artem Mikheev
+
+// Package validate checks fit files against the rules of the fit protocol and
+// global profile which aren't enforced while encoding, such as the order of
+// messages and the consistency of the summary messages with the records.
+// Both decoded and in-memory fit files can be validated.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/profile"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+var (
+	ErrFileIdNotFirst      = errors.New("file_id is not the first message")
+	ErrTimestampOrder      = errors.New("timestamp is earlier than the previous one")
+	ErrTotalsMismatch      = errors.New("summary totals don't match the records")
+	ErrTimerEvents         = errors.New("timer start and stop events don't pair up")
+	ErrUndescribedDevField = errors.New("developer field is used before its description")
+	ErrCountMismatch       = errors.New("number of messages doesn't match the summary")
+)
+
+const (
+	// timestamps only have a resolution of a second, so summary
+	// times can differ from the record timestamps by up to a second
+	timeTolerance = 1.0
+	// maximum difference between summary distances and the records in meters
+	distanceTolerance = 1.0
+)
+
+// Violation is a single violation of the rules found in a fit file
+type Violation struct {
+	// Index of the data message violating the rule,
+	// or -1 if the violation concerns the whole file
+	Index int
+	// Err is one of the errors describing the violated rule
+	Err    error
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	if v.Index < 0 {
+		return fmt.Sprintf("%v: %s", v.Err, v.Reason)
+	}
+	return fmt.Sprintf("data message %d: %v: %s", v.Index, v.Err, v.Reason)
+}
+
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Error is returned by Validate and contains all of the violations found in the file
+type Error struct {
+	Violations []*Violation
+}
+
+func (err *Error) Error() string {
+	reasons := make([]string, len(err.Violations))
+	for i, v := range err.Violations {
+		reasons[i] = v.Error()
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Is returns true if any of the violations matches target,
+// so that errors.Is can be used to check for a specific rule
+func (err *Error) Is(target error) bool {
+	for _, v := range err.Violations {
+		if errors.Is(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// dataMessage is the part of the fit data messages used during validation
+type dataMessage interface {
+	Definition() *fit.DefinitionMessage
+	Fields() []*fit.Field
+	DevFields() []*fit.DevField
+	Timestamp() (types.FitUint32, bool)
+}
+
+type devFieldKey struct {
+	devDataIndex types.FitUint8
+	defNum       types.FitUint8
+}
+
+type validator struct {
+	violations []*Violation
+
+	lastTimestamp types.FitUint32
+	hasTimestamp  bool
+
+	firstRecordTime types.FitUint32
+	lastRecordTime  types.FitUint32
+	hasRecords      bool
+	recordDistance  float64
+	hasDistance     bool
+
+	timerRunning   bool
+	timerStart     types.FitUint32
+	timerTime      float64
+	hasTimerEvents bool
+
+	descriptions map[devFieldKey]bool
+	undescribed  map[devFieldKey]bool
+
+	sessions   []dataMessage
+	laps       []dataMessage
+	activities []dataMessage
+}
+
+// Validate checks that the fit file follows the rules of the fit protocol:
+// file_id comes first, timestamps never decrease, session, lap and activity
+// totals are consistent with the records and each other, timer start and stop
+// events pair up, developer fields are described before they are used, and
+// num_sessions and num_laps match the number of session and lap messages.
+// All of the found violations are returned as an *Error.
+func Validate(file *fit.FitFile) error {
+	v := &validator{
+		descriptions: make(map[devFieldKey]bool),
+		undescribed:  make(map[devFieldKey]bool),
+	}
+	for i, msg := range file.DataMessages() {
+		v.checkMessage(i, msg)
+	}
+	v.checkTimer()
+	v.checkTotals()
+	v.checkCounts()
+
+	if len(v.violations) > 0 {
+		return &Error{Violations: v.violations}
+	}
+	return nil
+}
+
+func (v *validator) violate(index int, err error, format string, args ...interface{}) {
+	v.violations = append(v.violations, &Violation{
+		Index:  index,
+		Err:    err,
+		Reason: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) checkMessage(i int, msg dataMessage) {
+	mesgNum := msg.Definition().GlobalMsgNum
+	if i == 0 && mesgNum != profile.FIT_MESG_NUM_FILE_ID {
+		v.violate(i, ErrFileIdNotFirst, "first message has global message number %d", mesgNum)
+	}
+
+	timestamp, hasTimestamp := msg.Timestamp()
+	if hasTimestamp {
+		if v.hasTimestamp && timestamp < v.lastTimestamp {
+			v.violate(i, ErrTimestampOrder, "timestamp %d after %d", timestamp, v.lastTimestamp)
+		}
+		v.lastTimestamp, v.hasTimestamp = timestamp, true
+	}
+
+	for _, devField := range msg.DevFields() {
+		key := devFieldKey{devField.Def.Field.DevDataIndex, devField.Def.Field.DefNum}
+		if !v.descriptions[key] && !v.undescribed[key] {
+			// only report the first use of each developer field
+			v.undescribed[key] = true
+			v.violate(i, ErrUndescribedDevField, "developer data index %d, field %d",
+				key.devDataIndex, key.defNum)
+		}
+	}
+
+	switch mesgNum {
+	case profile.FIT_MESG_NUM_FIELD_DESCRIPTION:
+		devDataIndex, ok1 := value(msg, profile.FieldDescriptionDeveloperDataIndex).(types.FitUint8)
+		defNum, ok2 := value(msg, profile.FieldDescriptionFieldDefinitionNumber).(types.FitUint8)
+		if ok1 && ok2 {
+			v.descriptions[devFieldKey{devDataIndex, defNum}] = true
+		}
+	case profile.FIT_MESG_NUM_RECORD:
+		v.checkRecord(msg, timestamp, hasTimestamp)
+	case profile.FIT_MESG_NUM_EVENT:
+		v.checkEvent(i, msg, timestamp, hasTimestamp)
+	case profile.FIT_MESG_NUM_SESSION:
+		v.sessions = append(v.sessions, msg)
+	case profile.FIT_MESG_NUM_LAP:
+		v.laps = append(v.laps, msg)
+	case profile.FIT_MESG_NUM_ACTIVITY:
+		v.activities = append(v.activities, msg)
+	}
+}
+
+func (v *validator) checkRecord(msg dataMessage, timestamp types.FitUint32, hasTimestamp bool) {
+	if hasTimestamp {
+		if !v.hasRecords {
+			v.firstRecordTime = timestamp
+		}
+		v.lastRecordTime, v.hasRecords = timestamp, true
+	}
+	if distance, ok := floatValue(msg, profile.RecordDistance); ok {
+		v.recordDistance, v.hasDistance = distance, true
+	}
+}
+
+func (v *validator) checkEvent(i int, msg dataMessage, timestamp types.FitUint32, hasTimestamp bool) {
+	if event, _ := value(msg, profile.EventEvent).(types.FitEnum); event != profile.FIT_EVENT_TIMER {
+		return
+	}
+	v.hasTimerEvents = true
+	if !hasTimestamp {
+		v.violate(i, ErrTimerEvents, "timer event without a timestamp")
+		return
+	}
+
+	eventType, _ := value(msg, profile.EventEventType).(types.FitEnum)
+	switch eventType {
+	case profile.FIT_EVENT_TYPE_START:
+		if v.timerRunning {
+			v.violate(i, ErrTimerEvents, "timer started while already running")
+			return
+		}
+		v.timerRunning, v.timerStart = true, timestamp
+	case profile.FIT_EVENT_TYPE_STOP, profile.FIT_EVENT_TYPE_STOP_ALL,
+		profile.FIT_EVENT_TYPE_STOP_DISABLE, profile.FIT_EVENT_TYPE_STOP_DISABLE_ALL:
+		if !v.timerRunning {
+			v.violate(i, ErrTimerEvents, "timer stopped while not running")
+			return
+		}
+		v.timerRunning = false
+		v.timerTime += float64(timestamp - v.timerStart)
+	}
+}
+
+func (v *validator) checkTimer() {
+	if v.timerRunning {
+		v.violate(-1, ErrTimerEvents, "timer is never stopped")
+		// count the timer as stopped at the end of the file to avoid reporting the totals as well
+		v.timerTime += float64(v.lastTimestamp - v.timerStart)
+	}
+}
+
+// summary contains the totals of a group of session or lap messages
+type summary struct {
+	elapsedTime  float64
+	timerTime    float64
+	distance     float64
+	startTime    types.FitUint32
+	hasStartTime bool
+	hasDistance  bool
+}
+
+func summarize(messages []dataMessage, elapsedTime, timerTime, distance, startTime *profile.FieldInfo) summary {
+	var s summary
+	for _, msg := range messages {
+		if elapsed, ok := floatValue(msg, elapsedTime); ok {
+			s.elapsedTime += elapsed
+		}
+		if timer, ok := floatValue(msg, timerTime); ok {
+			s.timerTime += timer
+		}
+		if d, ok := floatValue(msg, distance); ok {
+			s.distance += d
+			s.hasDistance = true
+		}
+		if start, ok := rawValue(msg, startTime).(types.FitUint32); ok && (!s.hasStartTime || start < s.startTime) {
+			s.startTime, s.hasStartTime = start, true
+		}
+	}
+	return s
+}
+
+func (v *validator) checkTotals() {
+	if len(v.sessions) == 0 {
+		return
+	}
+	sessions := summarize(v.sessions, profile.SessionTotalElapsedTime,
+		profile.SessionTotalTimerTime, profile.SessionTotalDistance, profile.SessionStartTime)
+
+	if sessions.hasDistance && v.hasDistance && math.Abs(sessions.distance-v.recordDistance) > distanceTolerance {
+		v.violate(-1, ErrTotalsMismatch, "session distance %.2f m, records end at %.2f m",
+			sessions.distance, v.recordDistance)
+	}
+	if v.hasRecords {
+		start := v.firstRecordTime
+		if sessions.hasStartTime && sessions.startTime < start {
+			start = sessions.startTime
+		}
+		recordTime := float64(v.lastRecordTime - start)
+		if math.Abs(sessions.elapsedTime-recordTime) > timeTolerance {
+			v.violate(-1, ErrTotalsMismatch, "session elapsed time %.3f s, records span %.0f s",
+				sessions.elapsedTime, recordTime)
+		}
+	}
+	if sessions.timerTime > sessions.elapsedTime+timeTolerance {
+		v.violate(-1, ErrTotalsMismatch, "session timer time %.3f s exceeds elapsed time %.3f s",
+			sessions.timerTime, sessions.elapsedTime)
+	}
+	if v.hasTimerEvents && math.Abs(sessions.timerTime-v.timerTime) > timeTolerance {
+		v.violate(-1, ErrTotalsMismatch, "session timer time %.3f s, timer events span %.0f s",
+			sessions.timerTime, v.timerTime)
+	}
+
+	if len(v.laps) > 0 {
+		laps := summarize(v.laps, profile.LapTotalElapsedTime,
+			profile.LapTotalTimerTime, profile.LapTotalDistance, profile.LapStartTime)
+		if laps.hasDistance && sessions.hasDistance && math.Abs(laps.distance-sessions.distance) > distanceTolerance {
+			v.violate(-1, ErrTotalsMismatch, "lap distances add up to %.2f m, sessions to %.2f m",
+				laps.distance, sessions.distance)
+		}
+		if math.Abs(laps.elapsedTime-sessions.elapsedTime) > timeTolerance {
+			v.violate(-1, ErrTotalsMismatch, "lap elapsed times add up to %.3f s, sessions to %.3f s",
+				laps.elapsedTime, sessions.elapsedTime)
+		}
+		if math.Abs(laps.timerTime-sessions.timerTime) > timeTolerance {
+			v.violate(-1, ErrTotalsMismatch, "lap timer times add up to %.3f s, sessions to %.3f s",
+				laps.timerTime, sessions.timerTime)
+		}
+	}
+
+	for _, activity := range v.activities {
+		if timerTime, ok := floatValue(activity, profile.ActivityTotalTimerTime); ok &&
+			math.Abs(timerTime-sessions.timerTime) > timeTolerance {
+			v.violate(-1, ErrTotalsMismatch, "activity timer time %.3f s, sessions add up to %.3f s",
+				timerTime, sessions.timerTime)
+		}
+	}
+}
+
+func (v *validator) checkCounts() {
+	for _, activity := range v.activities {
+		if numSessions, ok := floatValue(activity, profile.ActivityNumSessions); ok && int(numSessions) != len(v.sessions) {
+			v.violate(-1, ErrCountMismatch, "activity num_sessions is %d, file contains %d sessions",
+				int(numSessions), len(v.sessions))
+		}
+	}
+
+	numLaps, hasNumLaps := 0, false
+	for _, session := range v.sessions {
+		if n, ok := floatValue(session, profile.SessionNumLaps); ok {
+			numLaps += int(n)
+			hasNumLaps = true
+		}
+	}
+	if hasNumLaps && numLaps != len(v.laps) {
+		v.violate(-1, ErrCountMismatch, "session num_laps add up to %d, file contains %d laps",
+			numLaps, len(v.laps))
+	}
+}
+
+// rawValue returns the raw value of the field, or nil if
+// the message doesn't contain it or its value is invalid
+func rawValue(msg dataMessage, field *profile.FieldInfo) interface{} {
+	for _, f := range msg.Fields() {
+		if f.Def.DefNum == field.Num {
+			if f.Def.BaseType.IsInvalidValue(f.Value) {
+				return nil
+			}
+			return f.Value
+		}
+	}
+	return nil
+}
+
+// value returns the value of the field converted using
+// its profile, or nil if the message doesn't contain it
+func value(msg dataMessage, field *profile.FieldInfo) interface{} {
+	raw := rawValue(msg, field)
+	if raw == nil {
+		return nil
+	}
+	converted, err := field.Value(raw)
+	if err != nil {
+		return nil
+	}
+	return converted
+}
+
+var float64Type = reflect.TypeOf(float64(0))
+
+// floatValue returns the numeric value of the field converted using its profile
+func floatValue(msg dataMessage, field *profile.FieldInfo) (float64, bool) {
+	converted := value(msg, field)
+	if converted == nil {
+		return 0, false
+	}
+	rv := reflect.ValueOf(converted)
+	if !rv.CanConvert(float64Type) {
+		return 0, false
+	}
+	return rv.Convert(float64Type).Float(), true
+}
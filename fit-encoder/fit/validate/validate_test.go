@@ -0,0 +1,219 @@
+// Copyright 2021 Artem Mikheev
+
+package validate
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/profile"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	activityStart = time.Date(2022, time.April, 1, 12, 0, 0, 0, time.UTC)
+	activityEnd   = activityStart.Add(10 * time.Second)
+)
+
+type testMessage struct {
+	msg    profile.Message
+	fields []*profile.FieldInfo
+}
+
+// activityMessages returns the messages of a small valid activity file
+func activityMessages() []testMessage {
+	messages := []testMessage{
+		{profile.FileId{Type: profile.FIT_FILE_ACTIVITY, TimeCreated: activityStart},
+			[]*profile.FieldInfo{profile.FileIdType, profile.FileIdTimeCreated}},
+		{profile.Event{Timestamp: activityStart, Event: profile.FIT_EVENT_TIMER, EventType: profile.FIT_EVENT_TYPE_START},
+			[]*profile.FieldInfo{profile.EventTimestamp, profile.EventEvent, profile.EventEventType}},
+	}
+	for i := 0; i <= 10; i++ {
+		messages = append(messages, testMessage{
+			profile.Record{Timestamp: activityStart.Add(time.Duration(i) * time.Second), Distance: float64(i) * 3},
+			[]*profile.FieldInfo{profile.RecordTimestamp, profile.RecordDistance},
+		})
+	}
+	return append(messages,
+		testMessage{profile.Event{Timestamp: activityEnd, Event: profile.FIT_EVENT_TIMER, EventType: profile.FIT_EVENT_TYPE_STOP_ALL},
+			[]*profile.FieldInfo{profile.EventTimestamp, profile.EventEvent, profile.EventEventType}},
+		testMessage{profile.Lap{Timestamp: activityEnd, StartTime: activityStart,
+			TotalElapsedTime: 10, TotalTimerTime: 10, TotalDistance: 30},
+			[]*profile.FieldInfo{profile.LapTimestamp, profile.LapStartTime,
+				profile.LapTotalElapsedTime, profile.LapTotalTimerTime, profile.LapTotalDistance}},
+		testMessage{profile.Session{Timestamp: activityEnd, StartTime: activityStart,
+			TotalElapsedTime: 10, TotalTimerTime: 10, TotalDistance: 30, NumLaps: 1},
+			[]*profile.FieldInfo{profile.SessionTimestamp, profile.SessionStartTime, profile.SessionTotalElapsedTime,
+				profile.SessionTotalTimerTime, profile.SessionTotalDistance, profile.SessionNumLaps}},
+		testMessage{profile.Activity{Timestamp: activityEnd, TotalTimerTime: 10, NumSessions: 1},
+			[]*profile.FieldInfo{profile.ActivityTimestamp, profile.ActivityTotalTimerTime, profile.ActivityNumSessions}},
+	)
+}
+
+func buildFile(r *require.Assertions, messages []testMessage) *fit.FitFile {
+	file := new(fit.FitFile)
+	for _, message := range messages {
+		def, err := profile.Definition(message.msg, message.fields...)
+		r.NoError(err)
+		values, err := profile.Values(message.msg, def)
+		r.NoError(err)
+		r.NoError(file.AddData(def, values...))
+	}
+	return file
+}
+
+func TestValidActivity(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	file := buildFile(r, activityMessages())
+	a.NoError(Validate(file))
+
+	// decoded files must be valid as well
+	buffer := new(bytes.Buffer)
+	r.NoError(file.Encode(buffer, encoding.LittleEndian))
+	decoded, err := fit.Decode(buffer)
+	r.NoError(err)
+	a.NoError(Validate(decoded))
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(messages []testMessage) []testMessage
+		err    error
+	}{
+		{
+			name: "file_id not first",
+			modify: func(messages []testMessage) []testMessage {
+				messages[0], messages[1] = messages[1], messages[0]
+				return messages
+			},
+			err: ErrFileIdNotFirst,
+		},
+		{
+			name: "decreasing timestamp",
+			modify: func(messages []testMessage) []testMessage {
+				messages[3], messages[4] = messages[4], messages[3]
+				return messages
+			},
+			err: ErrTimestampOrder,
+		},
+		{
+			name: "session distance",
+			modify: func(messages []testMessage) []testMessage {
+				session := messages[len(messages)-2].msg.(profile.Session)
+				session.TotalDistance = 40
+				messages[len(messages)-2].msg = session
+				return messages
+			},
+			err: ErrTotalsMismatch,
+		},
+		{
+			name: "lap elapsed time",
+			modify: func(messages []testMessage) []testMessage {
+				lap := messages[len(messages)-3].msg.(profile.Lap)
+				lap.TotalElapsedTime = 5
+				messages[len(messages)-3].msg = lap
+				return messages
+			},
+			err: ErrTotalsMismatch,
+		},
+		{
+			name: "activity timer time",
+			modify: func(messages []testMessage) []testMessage {
+				activity := messages[len(messages)-1].msg.(profile.Activity)
+				activity.TotalTimerTime = 20
+				messages[len(messages)-1].msg = activity
+				return messages
+			},
+			err: ErrTotalsMismatch,
+		},
+		{
+			name: "timer never stopped",
+			modify: func(messages []testMessage) []testMessage {
+				return append(messages[:len(messages)-4], messages[len(messages)-3:]...)
+			},
+			err: ErrTimerEvents,
+		},
+		{
+			name: "timer started twice",
+			modify: func(messages []testMessage) []testMessage {
+				return append(append([]testMessage{}, messages[:2]...), messages[1:]...)
+			},
+			err: ErrTimerEvents,
+		},
+		{
+			name: "num_laps",
+			modify: func(messages []testMessage) []testMessage {
+				return append(messages[:len(messages)-3], messages[len(messages)-2:]...)
+			},
+			err: ErrCountMismatch,
+		},
+		{
+			name: "num_sessions",
+			modify: func(messages []testMessage) []testMessage {
+				activity := messages[len(messages)-1].msg.(profile.Activity)
+				activity.NumSessions = 2
+				messages[len(messages)-1].msg = activity
+				return messages
+			},
+			err: ErrCountMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			r := require.New(t)
+
+			err := Validate(buildFile(r, tt.modify(activityMessages())))
+			a.ErrorIs(err, tt.err)
+
+			var validationErr *Error
+			r.ErrorAs(err, &validationErr)
+			for _, violation := range validationErr.Violations {
+				a.ErrorIsf(violation, tt.err, "unexpected violation: %v", violation)
+			}
+		})
+	}
+}
+
+func TestUndescribedDevField(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	devFieldDef := &fit.DevFieldDefinition{
+		Field: &fit.FieldDescriptionStub{DevDataIndex: 0, DefNum: 1, BaseType: types.FIT_TYPE_UINT8},
+		Size:  types.FIT_TYPE_UINT8_SIZE,
+		DevId: &fit.DeveloperDataIdStub{DevDataIndex: 0},
+	}
+	fileIdDef, err := profile.Definition(profile.FileId{}, profile.FileIdType)
+	r.NoError(err)
+	fileIdDef.DevFieldDefs = []*fit.DevFieldDefinition{devFieldDef}
+	fieldDesc := profile.FieldDescription{FieldDefinitionNumber: 1, FitBaseTypeId: types.FIT_TYPE_UINT8}
+	fieldDescDef, err := profile.Definition(fieldDesc, profile.FieldDescriptionDeveloperDataIndex,
+		profile.FieldDescriptionFieldDefinitionNumber, profile.FieldDescriptionFitBaseTypeId)
+	r.NoError(err)
+	fieldDescValues, err := profile.Values(fieldDesc, fieldDescDef)
+	r.NoError(err)
+
+	// the field is used twice before the description, but must only be reported once
+	file := new(fit.FitFile)
+	r.NoError(file.AddData(fileIdDef, profile.FIT_FILE_ACTIVITY, 1))
+	r.NoError(file.AddData(fileIdDef, profile.FIT_FILE_ACTIVITY, 2))
+	r.NoError(file.AddData(fieldDescDef, fieldDescValues...))
+	r.NoError(file.AddData(fileIdDef, profile.FIT_FILE_ACTIVITY, 3))
+
+	err = Validate(file)
+	a.ErrorIs(err, ErrUndescribedDevField)
+	var validationErr *Error
+	r.ErrorAs(err, &validationErr)
+	r.Len(validationErr.Violations, 1)
+	a.Equal(0, validationErr.Violations[0].Index)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,6 +27,225 @@ type activityConfig struct {
 	RareSpeedChance float64                  `yaml:"rare_speed_chance"`
 	FadeDuration    int                      `yaml:"fade_duration"`
 	FadeFraction    float64                  `yaml:"fade_fraction"`
+	SourceTiming    *sourceTimingConfig      `yaml:"source_timing"`
+	Ride            *rideConfig              `yaml:"ride"`
+	Grade           *gradeConfig             `yaml:"grade"`
+	Power           *powerConfig             `yaml:"power"`
+	HeartRate       *heartRateConfig         `yaml:"heart_rate"`
+	RunCadence      *runCadenceConfig        `yaml:"run_cadence"`
+	Stops           *stopsConfig             `yaml:"stops"`
+	AutoPause       bool                     `yaml:"auto_pause"`
+	Laps            *lapsConfig              `yaml:"laps"`
+	Workout         []workoutStepConfig      `yaml:"workout"`
+	SpeedGenerator  *speedGeneratorConfig    `yaml:"speed_generator"`
+}
+
+// sourceTimingConfig enables keeping the timestamps of the input route if it has them
+type sourceTimingConfig struct {
+	ShiftToStart bool `yaml:"shift_to_start"`
+	ScaleToSpeed bool `yaml:"scale_to_speed"`
+}
+
+// rideConfig describes the rider along with the bike used to model rides
+type rideConfig struct {
+	Mass float64 `yaml:"mass"`
+	CdA  float64 `yaml:"cda"`
+	Crr  float64 `yaml:"crr"`
+}
+
+func (c *rideConfig) options() *activities.RideOptions {
+	return &activities.RideOptions{Mass: c.Mass, CdA: c.CdA, Crr: c.Crr}
+}
+
+// gradeConfig enables adjusting the speed to the grade of the route
+type gradeConfig struct {
+	// pairs of grade and speed factor
+	PaceCurve [][2]float64 `yaml:"pace_curve"`
+}
+
+func (c *gradeConfig) options() *activities.GradeOptions {
+	options := &activities.GradeOptions{}
+	for _, point := range c.PaceCurve {
+		options.PaceCurve = append(options.PaceCurve, activities.GradePoint{Grade: point[0], Factor: point[1]})
+	}
+	return options
+}
+
+// powerConfig enables simulating rides using the power held by the rider
+type powerConfig struct {
+	Power float64 `yaml:"power"`
+	// pairs of distance in km and power in watts
+	Profile       [][2]float64 `yaml:"profile"`
+	WindSpeed     float64      `yaml:"wind_speed"`
+	WindDirection float64      `yaml:"wind_direction"`
+	Cadence       float64      `yaml:"cadence"`
+}
+
+func (c *powerConfig) options() *activities.PowerOptions {
+	options := &activities.PowerOptions{
+		Power:         c.Power,
+		WindSpeed:     c.WindSpeed,
+		WindDirection: c.WindDirection,
+		Cadence:       c.Cadence,
+	}
+	for _, step := range c.Profile {
+		options.Profile = append(options.Profile, activities.PowerStep{Distance: step[0], Power: step[1]})
+	}
+	return options
+}
+
+// heartRateConfig enables synthesizing the heart rate of records
+type heartRateConfig struct {
+	Resting   float64 `yaml:"resting"`
+	Max       float64 `yaml:"max"`
+	Intensity float64 `yaml:"intensity"`
+	// lag in seconds
+	Lag   int     `yaml:"lag"`
+	Drift float64 `yaml:"drift"`
+}
+
+func (c *heartRateConfig) options() *activities.HeartRateOptions {
+	return &activities.HeartRateOptions{
+		Resting:   c.Resting,
+		Max:       c.Max,
+		Intensity: c.Intensity,
+		Lag:       time.Duration(c.Lag) * time.Second,
+		Drift:     c.Drift,
+	}
+}
+
+// runCadenceConfig enables generating the cadence of runs
+type runCadenceConfig struct {
+	// pairs of speed in km/h and stride length in m
+	StrideCurve [][2]float64 `yaml:"stride_curve"`
+	Noise       float64      `yaml:"noise"`
+}
+
+func (c *runCadenceConfig) options() *activities.RunCadenceOptions {
+	options := &activities.RunCadenceOptions{Noise: c.Noise}
+	for _, point := range c.StrideCurve {
+		options.StrideCurve = append(options.StrideCurve, activities.StridePoint{Speed: point[0], Length: point[1]})
+	}
+	return options
+}
+
+// stopsConfig enables making stops during the activity
+type stopsConfig struct {
+	// durations in seconds
+	WaypointDuration  int     `yaml:"waypoint_duration"`
+	RandomPerHour     float64 `yaml:"random_per_hour"`
+	RandomMinDuration int     `yaml:"random_min_duration"`
+	RandomMaxDuration int     `yaml:"random_max_duration"`
+	// pairs of latitude and longitude
+	TrafficLights        [][2]float64 `yaml:"traffic_lights"`
+	TrafficLightDistance float64      `yaml:"traffic_light_distance"`
+	RedLightChance       float64      `yaml:"red_light_chance"`
+	RedLightDuration     int          `yaml:"red_light_duration"`
+}
+
+func (c *stopsConfig) options() *activities.StopOptions {
+	options := &activities.StopOptions{
+		WaypointDuration:     time.Duration(c.WaypointDuration) * time.Second,
+		RandomPerHour:        c.RandomPerHour,
+		RandomMinDuration:    time.Duration(c.RandomMinDuration) * time.Second,
+		RandomMaxDuration:    time.Duration(c.RandomMaxDuration) * time.Second,
+		TrafficLightDistance: c.TrafficLightDistance,
+		RedLightChance:       c.RedLightChance,
+		RedLightDuration:     time.Duration(c.RedLightDuration) * time.Second,
+	}
+	for _, light := range c.TrafficLights {
+		options.TrafficLights = append(options.TrafficLights, activities.TrafficLight{Lat: light[0], Lon: light[1]})
+	}
+	return options
+}
+
+// lapsConfig enables adding laps at the given distances
+type lapsConfig struct {
+	AutoLap   float64   `yaml:"auto_lap"`
+	Distances []float64 `yaml:"distances"`
+	// the distances are in miles instead of km
+	Miles bool `yaml:"miles"`
+}
+
+func (c *lapsConfig) options() *activities.LapOptions {
+	unit := 1.0
+	if c.Miles {
+		unit = activities.MileDistance
+	}
+	options := &activities.LapOptions{AutoLapDistance: c.AutoLap * unit}
+	for _, distance := range c.Distances {
+		options.Distances = append(options.Distances, distance*unit)
+	}
+	return options
+}
+
+// workoutStepConfig is a single step of the workout plan, which either covers
+// the distance in km at the speed in km/h, or repeats the nested steps
+type workoutStepConfig struct {
+	Name      string              `yaml:"name"`
+	Distance  float64             `yaml:"distance"`
+	Speed     float64             `yaml:"speed"`
+	Intensity string              `yaml:"intensity"`
+	Repeat    int                 `yaml:"repeat"`
+	Steps     []workoutStepConfig `yaml:"steps"`
+}
+
+func workoutSteps(configs []workoutStepConfig) []activities.WorkoutStep {
+	var steps []activities.WorkoutStep
+	for _, c := range configs {
+		steps = append(steps, activities.WorkoutStep{
+			Name:      c.Name,
+			Distance:  c.Distance,
+			Speed:     c.Speed,
+			Intensity: activities.WorkoutIntensity(c.Intensity),
+			Repeat:    c.Repeat,
+			Steps:     workoutSteps(c.Steps),
+		})
+	}
+	return steps
+}
+
+// speedGeneratorConfig selects the generator of the speed instead of the speed wave
+type speedGeneratorConfig struct {
+	Type           string  `yaml:"type"`
+	NoiseAmplitude float64 `yaml:"noise_amplitude"`
+	// periods and times in seconds
+	NoisePeriod      int     `yaml:"noise_period"`
+	NoiseOctaves     int     `yaml:"noise_octaves"`
+	NoisePersistence float64 `yaml:"noise_persistence"`
+	Volatility       float64 `yaml:"volatility"`
+	ReversionTime    int     `yaml:"reversion_time"`
+	// path to the timed route whose speed is replayed
+	Reference string `yaml:"reference"`
+
+	referenceProfile []float64
+}
+
+// loadReference loads the speed profile of the reference route, if there is one
+func (c *speedGeneratorConfig) loadReference(activityType activities.ActivityType) error {
+	if c.Reference == "" {
+		return nil
+	}
+	_, path := autoPromptBubble.UserExpand(c.Reference)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	c.referenceProfile, err = activities.ReferenceSpeedProfile(activityType, b)
+	return err
+}
+
+func (c *speedGeneratorConfig) options() *activities.SpeedGeneratorOptions {
+	return &activities.SpeedGeneratorOptions{
+		Type:             activities.SpeedGeneratorType(c.Type),
+		NoiseAmplitude:   c.NoiseAmplitude,
+		NoisePeriod:      time.Duration(c.NoisePeriod) * time.Second,
+		NoiseOctaves:     c.NoiseOctaves,
+		NoisePersistence: c.NoisePersistence,
+		Volatility:       c.Volatility,
+		ReversionTime:    time.Duration(c.ReversionTime) * time.Second,
+		Reference:        c.referenceProfile,
+	}
 }
 
 type UserConfig struct {
@@ -36,8 +256,11 @@ type UserConfig struct {
 
 // Arguments represents the possible commmand-line arguments
 type Arguments struct {
-	ConfigPath string
-	OutputPath string
+	ConfigPath        string
+	OutputPath        string
+	OutputFormat      string
+	WorkoutOutputPath string
+	Seed              int64
 }
 
 // LoadConfig reads and returns the config defined by args
@@ -56,6 +279,18 @@ func (args *Arguments) LoadConfig() (*UserConfig, error) {
 	if config.StravaConfig == nil {
 		return nil, errors.New("currently only strava is supported so it must exist in the config")
 	}
+
+	// the reference routes are loaded in advance, so that their errors are reported along with the config
+	for activityType, activityCfg := range map[activities.ActivityType]*activityConfig{
+		activities.RunActivity:  config.RunActivityConfig,
+		activities.RideActivity: config.RideActivityConfig,
+	} {
+		if activityCfg != nil && activityCfg.SpeedGenerator != nil {
+			if err := activityCfg.SpeedGenerator.loadReference(activityType); err != nil {
+				return nil, fmt.Errorf("unable to load reference route: %w", err)
+			}
+		}
+	}
 	return config, nil
 }
 
@@ -120,23 +355,17 @@ type simpleModel interface {
 }
 
 type ActivityModel struct {
-	args        *Arguments
-	config      *UserConfig
-	options     activities.ActivityOptions
-	gpxFilePath string
-	steps       []simpleModel
-	index       int
+	args          *Arguments
+	config        *UserConfig
+	options       activities.ActivityOptions
+	routeFilePath string
+	steps         []simpleModel
+	index         int
 }
 
 func strToTimestamp(val string) (time.Time, error) {
-	t, err := time.Parse("02.01.2006 15:04:05", val)
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	// offset the time by local timezone offset before sending it as timestamp
-	_, offset := time.Now().Zone()
-	return t.Add(-time.Second * time.Duration(offset)), nil
+	// the time is local, with the timezone offset of the date itself
+	return time.ParseInLocation("02.01.2006 15:04:05", val, time.Local)
 }
 
 func strIsTime(val string) error {
@@ -150,6 +379,92 @@ func strIsTime(val string) error {
 	return err
 }
 
+// strToClock parses durations written as H:MM:SS or M:SS
+func strToClock(val string) (time.Duration, error) {
+	parts := strings.Split(val, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, errors.New("expected H:MM:SS or M:SS")
+	}
+	var duration time.Duration
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 && value >= 60 {
+			return 0, errors.New("minutes and seconds must be less than 60")
+		}
+		duration = duration*60 + time.Duration(value)
+	}
+	return duration * time.Second, nil
+}
+
+// strToTimeOfDay parses the time of day written as HH:MM:SS or HH:MM
+func strToTimeOfDay(val string) (hour, minute, second int, err error) {
+	t, err := time.Parse("15:04:05", val)
+	if err != nil {
+		t, err = time.Parse("15:04", val)
+	}
+	if err != nil {
+		return 0, 0, 0, errors.New("expected HH:MM:SS or HH:MM")
+	}
+	return t.Hour(), t.Minute(), t.Second(), nil
+}
+
+// setSpeed sets the desired speed of the options given either as km/h, as a pace in
+// M:SS/km or M:SS/mi, as the finish time in H:MM:SS or as the time of day at which the
+// activity finishes in @HH:MM[:SS], which is the next day if it is before the start
+func setSpeed(options *activities.ActivityOptions, val string) error {
+	const format = "input speed as a float in km/h, pace as M:SS/km or M:SS/mi, finish time as H:MM:SS or @HH:MM:SS"
+	if strings.HasPrefix(val, "@") {
+		hour, minute, second, err := strToTimeOfDay(val[1:])
+		if err != nil {
+			return errors.New(format + ", error: " + err.Error())
+		}
+		// the day is taken in the location of the start, so that the time of day
+		// is kept even if the timezone offset changes during the activity
+		year, month, day := options.Start.Date()
+		options.End = time.Date(year, month, day, hour, minute, second, 0, options.Start.Location())
+		if !options.End.After(options.Start) {
+			options.End = time.Date(year, month, day+1, hour, minute, second, 0, options.Start.Location())
+		}
+		return nil
+	}
+
+	if !strings.Contains(val, ":") {
+		speed, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return errors.New(format + ", error: " + err.Error())
+		}
+		options.DesiredSpeed = speed
+		return nil
+	}
+
+	clock, unit := val, ""
+	if i := strings.IndexByte(clock, '/'); i >= 0 {
+		clock, unit = clock[:i], clock[i+1:]
+	}
+	duration, err := strToClock(clock)
+	if err == nil && duration <= 0 {
+		err = errors.New("must be positive")
+	}
+	if err != nil {
+		return errors.New(format + ", error: " + err.Error())
+	}
+
+	switch unit {
+	case "":
+		options.TotalDuration = duration
+	case "km":
+		options.DesiredSpeed = 1 / duration.Hours()
+	case "mi":
+		options.DesiredSpeed = activities.MileDistance / duration.Hours()
+	default:
+		return errors.New(format + ", error: unknown unit of pace " + unit)
+	}
+	return nil
+}
+
 const (
 	OkPrefix   = "[+]"
 	ErrPrefix  = "[-]"
@@ -163,15 +478,17 @@ func NewActivityModel(config *UserConfig) *ActivityModel {
 		args:   &arguments,
 		config: config,
 	}
+	// a new seed is chosen by the activity if none is given
+	model.options.Seed = arguments.Seed
 	model.steps = []simpleModel{
 		modelStep{
 			&autoPromptBubble.Model{
-				Prompt:            bubblesCommon.FontColor("Path to GPX file: ", promptBubble.ColorPrompt),
+				Prompt:            bubblesCommon.FontColor("Path to route file: ", promptBubble.ColorPrompt),
 				ValidateOkPrefix:  OkPrefix,
 				ValidateErrPrefix: ErrPrefix,
 			},
 			func(value interface{}) {
-				_, model.gpxFilePath = autoPromptBubble.UserExpand(value.(string))
+				_, model.routeFilePath = autoPromptBubble.UserExpand(value.(string))
 			},
 		},
 		modelStep{
@@ -205,6 +522,40 @@ func NewActivityModel(config *UserConfig) *ActivityModel {
 					model.options.RareSpeedChance = activityCfg.RareSpeedChance
 					model.options.FadeDuration = time.Duration(activityCfg.FadeDuration) * time.Second
 					model.options.FadeFraction = activityCfg.FadeFraction
+					if activityCfg.SourceTiming != nil {
+						model.options.SourceTiming = &activities.SourceTimingOptions{
+							ShiftToStart: activityCfg.SourceTiming.ShiftToStart,
+							ScaleToSpeed: activityCfg.SourceTiming.ScaleToSpeed,
+						}
+					}
+					if activityCfg.Ride != nil {
+						model.options.Ride = activityCfg.Ride.options()
+					}
+					if activityCfg.Grade != nil {
+						model.options.Grade = activityCfg.Grade.options()
+					}
+					if activityCfg.Power != nil {
+						model.options.Power = activityCfg.Power.options()
+					}
+					if activityCfg.HeartRate != nil {
+						model.options.HeartRate = activityCfg.HeartRate.options()
+					}
+					if activityCfg.RunCadence != nil {
+						model.options.RunCadence = activityCfg.RunCadence.options()
+					}
+					if activityCfg.Stops != nil {
+						model.options.Stops = activityCfg.Stops.options()
+					}
+					model.options.AutoPause = activityCfg.AutoPause
+					if activityCfg.Laps != nil {
+						model.options.Laps = activityCfg.Laps.options()
+					}
+					if activityCfg.SpeedGenerator != nil {
+						model.options.SpeedGenerator = activityCfg.SpeedGenerator.options()
+					}
+					if len(activityCfg.Workout) > 0 {
+						model.options.Workout = &activities.WorkoutOptions{Steps: workoutSteps(activityCfg.Workout)}
+					}
 				}
 			},
 		},
@@ -243,27 +594,26 @@ func NewActivityModel(config *UserConfig) *ActivityModel {
 		},
 		modelStep{
 			&promptBubble.Model{
-				Prompt: bubblesCommon.FontColor("Desired speed (km/h) as float: ", promptBubble.ColorPrompt),
+				Prompt: bubblesCommon.FontColor("Desired speed (km/h), pace (M:SS/km, M:SS/mi) or finish time (H:MM:SS, @HH:MM:SS): ",
+					promptBubble.ColorPrompt),
 				ValidateFunc: func(val string) error {
-					_, err := strconv.ParseFloat(val, 64)
-					if err != nil {
-						return errors.New("input speed as a float, error: " + err.Error())
-					}
-					return nil
+					return setSpeed(&activities.ActivityOptions{Start: model.options.Start}, val)
 				},
 				ValidateOkPrefix:  OkPrefix,
 				ValidateErrPrefix: ErrPrefix,
 			},
 			func(value interface{}) {
-				model.options.DesiredSpeed, _ = strconv.ParseFloat(value.(string), 64)
+				_ = setSpeed(&model.options, value.(string))
 			},
 		},
 		modelStep{
 			&stravaBubble.Model{
-				ActivityOptions: &model.options,
-				ApiConfig:       model.config.StravaConfig,
-				GpxFilePath:     &model.gpxFilePath,
-				OutputPath:      &model.args.OutputPath,
+				ActivityOptions:   &model.options,
+				ApiConfig:         model.config.StravaConfig,
+				RouteFilePath:     &model.routeFilePath,
+				OutputPath:        &model.args.OutputPath,
+				OutputFormat:      &model.args.OutputFormat,
+				WorkoutOutputPath: &model.args.WorkoutOutputPath,
 			},
 			func(value interface{}) {
 				if value != nil {
@@ -326,6 +676,13 @@ var (
 )
 
 func run(cmd *cobra.Command, args []string) {
+	if arguments.OutputFormat != "" {
+		if _, err := stravaBubble.ParseOutputFormat(arguments.OutputFormat); err != nil {
+			fmt.Println(bubblesCommon.FontColor(ErrPrefix+" "+err.Error(), ColorError))
+			return
+		}
+	}
+
 	config, err := arguments.LoadConfig()
 	if err != nil {
 		fmt.Println(bubblesCommon.FontColor(ErrPrefix+" Unable to load config: "+err.Error(), ColorError))
@@ -349,6 +706,13 @@ func init() {
 	rootCmd.Flags().StringVar(&arguments.ConfigPath, "config", "config.yml", "path to config file")
 	rootCmd.Flags().StringVar(&arguments.OutputPath, "output", "",
 		"path where to output the created route instead of uploading")
+	rootCmd.Flags().StringVar(&arguments.OutputFormat, "format", "",
+		"format of the output: json, gpx, tcx, fit, csv or geojson (detected by the output extension by default, "+
+			"unknown extensions are saved as json records)")
+	rootCmd.Flags().StringVar(&arguments.WorkoutOutputPath, "workout-output", "",
+		"path where to output the workout plan of the activity as a FIT workout file")
+	rootCmd.Flags().Int64Var(&arguments.Seed, "seed", 0,
+		"seed used to generate the activity, which is printed after building it (random by default)")
 }
 
 func main() {
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/renbou/jogmock/activities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSpeed(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	r.NoError(err)
+	setSpeedAt := func(start time.Time, val string) *activities.ActivityOptions {
+		options := &activities.ActivityOptions{Start: start}
+		r.NoError(setSpeed(options, val), val)
+		return options
+	}
+	summer := time.Date(2022, time.July, 10, 8, 0, 0, 0, berlin)
+
+	// speed, pace and finish time
+	a.Equal(10.5, setSpeedAt(summer, "10.5").DesiredSpeed)
+	a.Equal(12.0, setSpeedAt(summer, "5:00/km").DesiredSpeed)
+	a.InDelta(12, setSpeedAt(summer, "8:03/mi").DesiredSpeed, 0.01)
+	a.Equal(time.Hour+2*time.Minute+3*time.Second, setSpeedAt(summer, "1:02:03").TotalDuration)
+	a.Equal(45*time.Minute, setSpeedAt(summer, "45:00").TotalDuration)
+
+	// the time of day of the finish, on the next day if it is before the start
+	a.Equal(time.Date(2022, time.July, 10, 18, 30, 0, 0, berlin), setSpeedAt(summer, "@18:30").End)
+	a.Equal(time.Date(2022, time.July, 10, 9, 15, 30, 0, berlin), setSpeedAt(summer, "@09:15:30").End)
+	a.Equal(time.Date(2022, time.July, 11, 7, 0, 0, 0, berlin), setSpeedAt(summer, "@07:00").End)
+
+	// the time of day is kept when the clocks change during the activity
+	springStart := time.Date(2022, time.March, 27, 0, 30, 0, 0, berlin)
+	end := setSpeedAt(springStart, "@03:30").End
+	a.Equal(2*time.Hour, end.Sub(springStart))
+	a.Equal(3, end.Hour())
+	autumnStart := time.Date(2022, time.October, 29, 22, 0, 0, 0, berlin)
+	end = setSpeedAt(autumnStart, "@06:00").End
+	a.Equal(9*time.Hour, end.Sub(autumnStart))
+	a.Equal(6, end.Hour())
+
+	// Error tests
+	for _, val := range []string{"", "abc", "0:00", "5:61", "4:00/yd", "@25:00", "@18:30/km", "@18"} {
+		a.Error(setSpeed(&activities.ActivityOptions{Start: summer}, val), val)
+	}
+}
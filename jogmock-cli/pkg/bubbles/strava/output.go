@@ -0,0 +1,47 @@
+package strava
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// OutputFormat is the format in which the activity is saved instead of being uploaded
+type OutputFormat string
+
+const (
+	// JSONOutput is the JSON array of the activity records
+	JSONOutput    OutputFormat = "json"
+	GPXOutput     OutputFormat = "gpx"
+	TCXOutput     OutputFormat = "tcx"
+	FITOutput     OutputFormat = "fit"
+	CSVOutput     OutputFormat = "csv"
+	GeoJSONOutput OutputFormat = "geojson"
+)
+
+// OutputFormats lists all of the supported output formats
+var OutputFormats = []OutputFormat{JSONOutput, GPXOutput, TCXOutput, FITOutput, CSVOutput, GeoJSONOutput}
+
+// ParseOutputFormat parses the name of the format case-insensitively
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	for _, format := range OutputFormats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+
+	names := make([]string, len(OutputFormats))
+	for i, format := range OutputFormats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unknown output format %q, should be one of: %s", name, strings.Join(names, ", "))
+}
+
+// DetectOutputFormat detects the format by the extension of the path,
+// defaulting to JSON records for unknown extensions
+func DetectOutputFormat(path string) OutputFormat {
+	if format, err := ParseOutputFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil {
+		return format
+	}
+	return JSONOutput
+}
@@ -1,9 +1,11 @@
 package strava
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,14 +25,19 @@ const (
 
 type Model struct {
 	ActivityOptions *activities.ActivityOptions
-	GpxFilePath     *string
+	RouteFilePath   *string
 	OutputPath      *string
+	OutputFormat    *string
 	ApiConfig       *stravapi.ApiConfig
+	// path where the workout plan is saved as a FIT workout file, if set
+	WorkoutOutputPath *string
 
-	apiClient *stravapi.ApiClient
-	activity  *activities.Activity
+	apiClient   *stravapi.ApiClient
+	activity    *activities.Activity
+	routeFormat activities.RouteFormat
 
 	builtActivity     bool
+	savedWorkout      bool
 	initializedClient bool
 	uploading         bool
 	uploaded          bool
@@ -44,18 +51,72 @@ func (m *Model) Init() tea.Cmd {
 
 func (m *Model) buildActivity() error {
 	// read and unmarshal the actual file
-	gpxFile, err := os.Open(*m.GpxFilePath)
+	routeFile, err := os.Open(*m.RouteFilePath)
 	if err != nil {
 		return err
 	}
-	defer gpxFile.Close()
+	defer routeFile.Close()
 
-	b, err := ioutil.ReadAll(gpxFile)
+	b, err := ioutil.ReadAll(routeFile)
 	if err != nil {
 		return err
 	}
 
-	return m.activity.BuildFromGPX(b)
+	// the format is detected by the contents since the extensions of exported routes vary
+	m.routeFormat = activities.DetectRouteFormat(b)
+	return m.activity.BuildFromRoute(b)
+}
+
+// marshalActivity marshals the built activity in the output format, which is
+// detected by the extension of the output path if it isn't specified explicitly
+func (m *Model) marshalActivity() ([]byte, error) {
+	format := DetectOutputFormat(*m.OutputPath)
+	if m.OutputFormat != nil && *m.OutputFormat != "" {
+		var err error
+		if format, err = ParseOutputFormat(*m.OutputFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	switch format {
+	case GPXOutput:
+		return m.activity.MarshalGPX()
+	case TCXOutput:
+		return m.activity.MarshalTCX()
+	case FITOutput:
+		// the exact file which would have been uploaded
+		apiClient, err := stravapi.NewClient(m.ApiConfig)
+		if err != nil {
+			return nil, err
+		}
+		buffer := new(bytes.Buffer)
+		if err := apiClient.EncodeActivity(m.activity, buffer); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	case CSVOutput:
+		return m.activity.MarshalCSV()
+	case GeoJSONOutput:
+		return m.activity.MarshalGeoJSON()
+	default:
+		return json.Marshal(m.activity.Records())
+	}
+}
+
+func (m *Model) saveActivity() error {
+	b, err := m.marshalActivity()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(*m.OutputPath, b, 0644)
+}
+
+func (m *Model) saveWorkout() error {
+	buffer := new(bytes.Buffer)
+	if err := stravapi.EncodeWorkout(m.activity, buffer); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(*m.WorkoutOutputPath, buffer.Bytes(), 0644)
 }
 
 type msg int
@@ -102,6 +163,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.builtActivity = true
 
+		if m.WorkoutOutputPath != nil && *m.WorkoutOutputPath != "" {
+			m.err = m.saveWorkout()
+			if m.err != nil {
+				return m, viewErr
+			}
+			m.savedWorkout = true
+		}
+
 		if *m.OutputPath != "" {
 			return m, saveActivity
 		}
@@ -112,15 +181,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case viewErrMsg:
 		return m, tea.Quit
 	case saveActivityMsg:
-		file, err := os.Create(*m.OutputPath)
-		if err != nil {
-			m.err = err
-			return m, viewErr
-		}
-
-		encoder := json.NewEncoder(file)
-		records := m.activity.Records()
-		m.err = encoder.Encode(records)
+		m.err = m.saveActivity()
 		if m.err != nil {
 			return m, viewErr
 		}
@@ -176,7 +237,12 @@ func (m *Model) View() string {
 	var lines []string
 	if m.builtActivity {
 		lines = append(lines,
-			bubblesCommon.FontColor(OkPrefix+" Constructed activity from GPX", ColorInfo))
+			bubblesCommon.FontColor(OkPrefix+" Constructed activity from "+m.routeFormat.String()+
+				" using seed "+strconv.FormatInt(m.activity.Seed(), 10), ColorInfo))
+	}
+	if m.savedWorkout {
+		lines = append(lines,
+			bubblesCommon.FontColor(OkPrefix+" Saved workout to "+*m.WorkoutOutputPath, ColorInfo))
 	}
 	if m.initializedClient {
 		lines = append(lines,
@@ -4,11 +4,14 @@ package stravafit
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"time"
 
 	"github.com/renbou/jogmock/activities"
+	"github.com/renbou/jogmock/fit-encoder/encoding"
 	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/profile"
 	"github.com/renbou/jogmock/fit-encoder/fit/types"
 )
 
@@ -31,103 +34,114 @@ type StravaActivity struct {
 }
 
 const (
-	FIT_MANUFACTURER_STRAVA        = 265
-	FIT_PRODUCT_STRAVA_ANDROID     = 102
-	FIT_FILEID_TYPE_ACTIVITY       = 4
-	FIT_ACTIVITY_ONE_SESSION       = 1
-	FIT_ACTIVITY_EVENT_ACTIVITY    = 26
-	FIT_EVENT_TYPE_START           = 0
-	FIT_EVENT_TYPE_STOP            = 1
-	FIT_SESSION_EVENT_SESSION      = 8
-	FIT_SESSION_ONE_LAP            = 1
-	STRAVA_FIRST_LIVE_ACTIVITY_ID  = 0
-	STRAVA_AUTOPAUSE_DISABLED      = 0
-	FIT_LAP_EVENT_LAP              = 9
-	FIT_LAP_TRIGGER_SESSION_END    = 7
-	FIT_BATTERY_STATUS_GOOD        = 3
-	FIT_EVENT_TIMER                = 0
-	FIT_EVENT_TIMER_TRIGGER_MANUAL = 0
-	STRAVA_NOICE_GPS_ACCURACY      = 4
+	FIT_PRODUCT_STRAVA_ANDROID    = 102
+	FIT_ACTIVITY_ONE_SESSION      = 1
+	STRAVA_FIRST_LIVE_ACTIVITY_ID = 0
+	STRAVA_AUTOPAUSE_DISABLED     = 0
+	STRAVA_AUTOPAUSE_ENABLED      = 1
+	STRAVA_NOICE_GPS_ACCURACY     = 4
 )
 
-func fitActivitySport(act *StravaActivity) uint8 {
+func fitActivitySport(act *StravaActivity) types.FitEnum {
 	activityType := act.Activity.Type()
 	if activityType == activities.RunActivity {
-		return 1
+		return profile.FIT_SPORT_RUNNING
 	} else if activityType == activities.RideActivity {
-		return 2
+		return profile.FIT_SPORT_CYCLING
 	}
 	panic(fmt.Sprintf("unknown activity type: %v", activityType))
 }
 
-func fitEncodeDistanceKm(distance float64) int {
-	return int(math.Round(distance * 100000.0))
-}
-
-func fitEncodeSpeedKmH(speed float64) int {
-	return int(math.Round(speed * (float64(1000) / 3600) * 1000))
+// autopause returns the value of the autopause_enabled developer field
+func autopause(act *StravaActivity) types.FitEnum {
+	if act.Activity.AutoPause() {
+		return STRAVA_AUTOPAUSE_ENABLED
+	}
+	return STRAVA_AUTOPAUSE_DISABLED
 }
 
-func fitEncodeAltitudeM(altitude float64) int {
-	return int(math.Round((altitude + 500.0) * 5.0))
+// timerTrigger returns the trigger of the timer events made during the stops
+func timerTrigger(act *StravaActivity) types.FitEnum {
+	if act.Activity.AutoPause() {
+		return profile.FIT_TIMER_TRIGGER_AUTO
+	}
+	return profile.FIT_TIMER_TRIGGER_MANUAL
 }
 
-func fitEncodeCoordinate(coord float64) int {
-	return int(
-		math.Round(coord * float64(int64(1)<<31) / 180.0),
-	)
+// fitLapTrigger returns the fit lap trigger for the trigger of the lap
+func fitLapTrigger(trigger activities.LapTrigger) types.FitEnum {
+	switch trigger {
+	case activities.DistanceLapTrigger:
+		return profile.FIT_LAP_TRIGGER_DISTANCE
+	case activities.PositionLapTrigger:
+		return profile.FIT_LAP_TRIGGER_POSITION_MARKED
+	case activities.SessionEndLapTrigger:
+		return profile.FIT_LAP_TRIGGER_SESSION_END
+	default:
+		return profile.FIT_LAP_TRIGGER_MANUAL
+	}
 }
 
-func fitEncodeTimestamp(t time.Time) int64 {
-	// fit time is offset by 631065600 seconds
-	return t.Add(-time.Second * 631065600).Unix()
+// kmToM converts the kilometers used by activities to meters
+func kmToM(distance float64) float64 {
+	return distance * 1000
 }
 
-func fitEncodeDuration(duration time.Duration) int {
-	return int(duration.Milliseconds())
+// kmhToMs converts the kilometers per hour used by activities to meters per second
+func kmhToMs(speed float64) float64 {
+	return speed / 3.6
 }
 
 // BuildFitFile creates a fit file based on the filled activity
 func (act *StravaActivity) BuildFitFile() (*fit.FitFile, error) {
 	file := new(fit.FitFile)
-	if err := act.writeHeader(file); err != nil {
-		return nil, err
-	}
-	if err := act.writeBody(file); err != nil {
-		return nil, err
-	}
-	if err := act.writeFooter(file); err != nil {
+	if err := act.writeMessages(file); err != nil {
 		return nil, err
 	}
 	return file, nil
 }
 
-func (act *StravaActivity) writeHeader(file *fit.FitFile) error {
-	// add simple file id message
-	fileIdMessage, err := getFileIdMessageDefinition()
+// EncodeFitFile encodes the fit file based on the filled activity
+// straight into wr, without keeping all of the messages in memory
+func (act *StravaActivity) EncodeFitFile(wr io.Writer, endianness encoding.Endianness) error {
+	enc, err := fit.NewStreamEncoder(wr, endianness)
 	if err != nil {
 		return err
 	}
-	fileIdMessageDef := fileIdMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(fileIdMessageDef); err != nil {
+	if err := act.writeMessages(enc); err != nil {
+		enc.Close()
+		return err
+	}
+	return enc.Close()
+}
+
+func (act *StravaActivity) writeMessages(file fit.MessageWriter) error {
+	if err := act.writeHeader(file); err != nil {
+		return err
+	}
+	if err := act.writeBody(file); err != nil {
 		return err
 	}
-	fileIdMessageData, err := fileIdMessageDef.ConstructData(
-		FIT_MANUFACTURER_STRAVA, FIT_PRODUCT_STRAVA_ANDROID, FIT_FILEID_TYPE_ACTIVITY,
-		fitEncodeTimestamp(act.Activity.Start()))
+	return act.writeFooter(file)
+}
+
+func (act *StravaActivity) writeHeader(file fit.MessageWriter) error {
+	// add simple file id message
+	fileIdMessage, err := getFileIdMessageDefinition()
 	if err != nil {
-		return nil
+		return err
 	}
-	if err := file.AddMessage(fileIdMessageData); err != nil {
+	if err := addProfileData(file, fileIdMessage, profile.FileId{
+		Type:         profile.FIT_FILE_ACTIVITY,
+		Manufacturer: profile.FIT_MANUFACTURER_STRAVA,
+		Product:      FIT_PRODUCT_STRAVA_ANDROID,
+		TimeCreated:  act.Activity.Start(),
+	}); err != nil {
 		return err
 	}
 
 	// add developer data id message and field definition messages
-	dev := &devData{
-		index:      0,
-		appVersion: act.AppVersion,
-		fields:     make(map[string]*devField),
-	}
+	dev := newDevData(0, act.AppVersion)
 	if err := dev.addField("live_activity_id", types.FIT_TYPE_UINT64); err != nil {
 		return err
 	}
@@ -149,32 +163,20 @@ func (act *StravaActivity) writeHeader(file *fit.FitFile) error {
 	if err := dev.addField("device_manufacturer", types.FIT_TYPE_STRING); err != nil {
 		return err
 	}
-	devMessages, err := dev.constructAllMessages(0)
-	if err != nil {
+	if err := dev.writeMessages(file); err != nil {
 		return err
 	}
-	for _, message := range devMessages {
-		if err := file.AddMessage(message); err != nil {
-			return err
-		}
-	}
 
 	// add device info message
 	deviceInfoMessage, err := getDeviceInfoMessageDefinition(act, dev)
 	if err != nil {
 		return err
 	}
-	deviceInfoMessageDef := deviceInfoMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(deviceInfoMessageDef); err != nil {
-		return err
-	}
-	deviceInfoMessageData, err := deviceInfoMessageDef.ConstructData(
-		FIT_MANUFACTURER_STRAVA, FIT_PRODUCT_STRAVA_ANDROID,
-		act.MobileAppVersion, act.DeviceManufacturer, act.DeviceModel, act.DeviceOsVersion)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(deviceInfoMessageData); err != nil {
+	if err := addProfileData(file, deviceInfoMessage, profile.DeviceInfo{
+		Manufacturer: profile.FIT_MANUFACTURER_STRAVA,
+		Product:      FIT_PRODUCT_STRAVA_ANDROID,
+	},
+		act.MobileAppVersion, act.DeviceManufacturer, act.DeviceModel, act.DeviceOsVersion); err != nil {
 		return err
 	}
 
@@ -183,182 +185,228 @@ func (act *StravaActivity) writeHeader(file *fit.FitFile) error {
 	if err != nil {
 		return err
 	}
-	activityMessageDef := activityMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(activityMessageDef); err != nil {
-		return err
-	}
-	activityMessageData, err := activityMessageDef.ConstructData(
-		FIT_ACTIVITY_ONE_SESSION, fitEncodeDuration(act.Activity.TotalDuration()),
-		FIT_ACTIVITY_EVENT_ACTIVITY, FIT_EVENT_TYPE_STOP)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(activityMessageData); err != nil {
+	if err := addProfileData(file, activityMessage, profile.Activity{
+		TotalTimerTime: act.Activity.MovingDuration().Seconds(),
+		NumSessions:    FIT_ACTIVITY_ONE_SESSION,
+		Event:          profile.FIT_EVENT_ACTIVITY,
+		EventType:      profile.FIT_EVENT_TYPE_STOP,
+	}); err != nil {
 		return err
 	}
 
 	// add session message
+	laps := act.Activity.Laps()
 	sessionMessage, err := getSessionMessageDefinition(act, dev)
 	if err != nil {
 		return err
 	}
-	sessionMessageDef := sessionMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(sessionMessageDef); err != nil {
-		return err
-	}
-	sessionMessageData, err := sessionMessageDef.ConstructData(
-		fitEncodeDuration(act.Activity.TotalDuration()), fitEncodeTimestamp(act.Activity.Start()),
-		fitEncodeDuration(act.Activity.TotalDuration()), fitEncodeDistanceKm(act.Activity.TotalDistance()),
-		fitActivitySport(act),
-		FIT_SESSION_EVENT_SESSION, FIT_EVENT_TYPE_STOP, FIT_SESSION_ONE_LAP, STRAVA_FIRST_LIVE_ACTIVITY_ID,
-		ActivityTypeToString(act.Activity.Type()), STRAVA_AUTOPAUSE_DISABLED)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(sessionMessageData); err != nil {
+	if err := addProfileData(file, sessionMessage, profile.Session{
+		Event:            profile.FIT_EVENT_SESSION,
+		EventType:        profile.FIT_EVENT_TYPE_STOP,
+		StartTime:        act.Activity.Start(),
+		Sport:            fitActivitySport(act),
+		TotalElapsedTime: act.Activity.TotalDuration().Seconds(),
+		TotalTimerTime:   act.Activity.MovingDuration().Seconds(),
+		TotalDistance:    kmToM(act.Activity.TotalDistance()),
+		NumLaps:          types.FitUint16(len(laps)),
+	},
+		STRAVA_FIRST_LIVE_ACTIVITY_ID, ActivityTypeToString(act.Activity.Type()), autopause(act)); err != nil {
 		return err
 	}
 
-	// add lap message
+	// add a lap message for each of the laps, which are written along with the session
+	// before the records, so they don't contain the timestamp of the lap end
 	lapMessage, err := getLapMessageDefinition()
 	if err != nil {
 		return err
 	}
-	lapMessageDef := lapMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(lapMessageDef); err != nil {
-		return err
-	}
-	lapMessageData, err := lapMessageDef.ConstructData(
-		fitEncodeDuration(act.Activity.TotalDuration()), fitEncodeTimestamp(act.Activity.Start()),
-		fitEncodeDuration(act.Activity.TotalDuration()), fitEncodeDistanceKm(act.Activity.TotalDistance()),
-		FIT_LAP_EVENT_LAP, FIT_EVENT_TYPE_STOP, fitActivitySport(act), FIT_LAP_TRIGGER_SESSION_END)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(lapMessageData); err != nil {
-		return err
+	for i, lap := range laps {
+		if err := addProfileData(file, lapMessage, profile.Lap{
+			MessageIndex:     types.FitUint16(i),
+			Event:            profile.FIT_EVENT_LAP,
+			EventType:        profile.FIT_EVENT_TYPE_STOP,
+			StartTime:        lap.Start,
+			TotalElapsedTime: lap.ElapsedDuration.Seconds(),
+			TotalTimerTime:   lap.MovingDuration.Seconds(),
+			TotalDistance:    kmToM(lap.Distance),
+			AvgSpeed:         kmhToMs(lap.AverageSpeed),
+			MaxSpeed:         kmhToMs(lap.MaxSpeed),
+			TotalAscent:      types.FitUint16(math.Round(lap.ElevationGain)),
+			TotalDescent:     types.FitUint16(math.Round(lap.ElevationLoss)),
+			LapTrigger:       fitLapTrigger(lap.Trigger),
+			Sport:            fitActivitySport(act),
+		}); err != nil {
+			return err
+		}
 	}
 
 	// add device battery info message on start of activity
+	return act.writeBatteryInfo(file, act.Activity.Start())
+}
+
+// writeBatteryInfo writes the device info message with the battery status at the given time
+func (act *StravaActivity) writeBatteryInfo(file fit.MessageWriter, timestamp time.Time) error {
 	deviceInfoBatteryMessage, err := getDeviceInfoBatteryMessageDefinition()
 	if err != nil {
 		return err
 	}
-	deviceInfoBatteryMessageDef := deviceInfoBatteryMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(deviceInfoBatteryMessageDef); err != nil {
-		return err
-	}
-	deviceInfoBatteryMessageData, err := deviceInfoBatteryMessageDef.ConstructData(
-		FIT_MANUFACTURER_STRAVA, FIT_PRODUCT_STRAVA_ANDROID, fitEncodeTimestamp(act.Activity.Start()), FIT_BATTERY_STATUS_GOOD)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(deviceInfoBatteryMessageData); err != nil {
-		return err
-	}
-
-	return nil
+	return addProfileData(file, deviceInfoBatteryMessage, profile.DeviceInfo{
+		Timestamp:     timestamp,
+		Manufacturer:  profile.FIT_MANUFACTURER_STRAVA,
+		Product:       FIT_PRODUCT_STRAVA_ANDROID,
+		BatteryStatus: profile.FIT_BATTERY_STATUS_OK,
+	})
 }
 
-func (act *StravaActivity) writeBody(file *fit.FitFile) error {
+func (act *StravaActivity) writeBody(file fit.MessageWriter) error {
 	// add event message on start of activity
 	eventMessage, err := getEventMessageDefinition()
 	if err != nil {
 		return err
 	}
-	eventMessageDef := eventMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(eventMessageDef); err != nil {
-		return err
-	}
-	eventStartMessageData, err := eventMessageDef.ConstructData(
-		FIT_EVENT_TIMER, fitEncodeTimestamp(act.Activity.Start()), FIT_EVENT_TIMER_TRIGGER_MANUAL, FIT_EVENT_TYPE_START)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(eventStartMessageData); err != nil {
+	if err := addProfileData(file, eventMessage, profile.Event{
+		Timestamp: act.Activity.Start(),
+		Event:     profile.FIT_EVENT_TIMER,
+		EventType: profile.FIT_EVENT_TYPE_START,
+		Data:      types.FitUint32(profile.FIT_TIMER_TRIGGER_MANUAL),
+	}); err != nil {
 		return err
 	}
 
-	// add normal record definition message
+	// record messages use the compressed timestamp headers, with the
+	// distance being written in a separate message after each record
 	recordMessage, err := getRecordMessageDefinition()
 	if err != nil {
 		return err
 	}
-	recordMessageDef := recordMessage.ConstructLocalMessage(1)
-	if err := file.AddMessage(recordMessageDef); err != nil {
+	recordNoAltitudeMessage, err := getRecordNoAltitudeMessageDefinition()
+	if err != nil {
 		return err
 	}
-
-	// add distance record definition message
 	recordDistanceMessage, err := getRecordDistanceMessageDefinition()
 	if err != nil {
 		return err
 	}
-	recordDistanceMessageDef := recordDistanceMessage.ConstructLocalMessage(2)
-	if err := file.AddMessage(recordDistanceMessageDef); err != nil {
+	recordSensorMessage, err := getRecordSensorMessageDefinition(activityChannels(act.Activity.Records()))
+	if err != nil {
 		return err
 	}
 
-	// add all records to file
+	// add all records to file, along with the timer events of the stops
+	stops := act.Activity.Stops()
 	for _, record := range act.Activity.Records() {
-		// add record normal data
-		recordMessageData, err := recordMessageDef.ConstructData(
-			fitEncodeCoordinate(record.Lat), fitEncodeCoordinate(record.Lon),
-			fitEncodeAltitudeM(record.Altitude), fitEncodeSpeedKmH(record.Speed),
-			STRAVA_NOICE_GPS_ACCURACY, fitEncodeTimestamp(record.Timestamp))
+		fitRecord := profile.Record{
+			PositionLat:  record.Lat,
+			PositionLong: record.Lon,
+			Speed:        kmhToMs(record.Speed),
+			GpsAccuracy:  STRAVA_NOICE_GPS_ACCURACY,
+			Distance:     kmToM(record.Distance),
+			HeartRate:    optionalUint8(record.HeartRate),
+			Cadence:      optionalUint8(record.Cadence),
+			// the fractional cadence is only known along with the cadence
+			FractionalCadence: record.FractionalCadence,
+			Power:             types.FitUint16(record.Power),
+		}
+		timestamp := profile.EncodeTime(record.Timestamp)
+
+		// add record normal data, leaving out the altitude if it is unknown
+		message := recordNoAltitudeMessage
+		if record.Altitude != nil {
+			fitRecord.EnhancedAltitude = *record.Altitude
+			message = recordMessage
+		}
+		recordValues, err := profileValues(fitRecord, message)
 		if err != nil {
 			return err
 		}
-		if err := file.AddMessage(recordMessageData); err != nil {
+		if err := file.AddCompressedData(message, timestamp, recordValues...); err != nil {
 			return err
 		}
 
 		// add record distance data
-		recordDistanceMessageData, err := recordDistanceMessageDef.ConstructData(
-			fitEncodeTimestamp(record.Timestamp), fitEncodeDistanceKm(record.Distance))
+		recordDistanceValues, err := profileValues(fitRecord, recordDistanceMessage)
 		if err != nil {
 			return err
 		}
-		if err := file.AddMessage(recordDistanceMessageData); err != nil {
+		if err := file.AddCompressedData(recordDistanceMessage, timestamp, recordDistanceValues...); err != nil {
 			return err
 		}
+
+		// add record sensor data, with zero power while coasting
+		if recordSensorMessage != nil {
+			recordSensorValues, err := profileValues(fitRecord, recordSensorMessage)
+			if err != nil {
+				return err
+			}
+			if err := file.AddCompressedData(recordSensorMessage, timestamp, recordSensorValues...); err != nil {
+				return err
+			}
+		}
+
+		// the timer is stopped at the record at which the stop is made, and started
+		// again when the stop ends. The events contain the full timestamp, which is
+		// also used for the following compressed timestamps
+		if len(stops) > 0 && stops[0].Start.Equal(record.Timestamp) {
+			if err := act.writeTimerEvent(file, eventMessage, stops[0].Start, profile.FIT_EVENT_TYPE_STOP); err != nil {
+				return err
+			}
+			if err := act.writeTimerEvent(file, eventMessage, stops[0].End(), profile.FIT_EVENT_TYPE_START); err != nil {
+				return err
+			}
+			stops = stops[1:]
+		}
 	}
 
 	// add stop event to file
-	eventStopMessageData, err := eventMessageDef.ConstructData(
-		FIT_EVENT_TIMER,
-		fitEncodeTimestamp(act.Activity.Start().Add(act.Activity.TotalDuration())),
-		FIT_EVENT_TIMER_TRIGGER_MANUAL, FIT_EVENT_TYPE_STOP)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(eventStopMessageData); err != nil {
-		return err
-	}
+	return addProfileData(file, eventMessage, profile.Event{
+		Timestamp: act.Activity.Start().Add(act.Activity.TotalDuration()),
+		Event:     profile.FIT_EVENT_TIMER,
+		EventType: profile.FIT_EVENT_TYPE_STOP,
+		Data:      types.FitUint32(profile.FIT_TIMER_TRIGGER_MANUAL),
+	})
+}
 
-	return nil
+// writeTimerEvent writes a timer event made during a stop
+func (act *StravaActivity) writeTimerEvent(file fit.MessageWriter, eventMessage *fit.DefinitionMessage,
+	timestamp time.Time, eventType types.FitEnum,
+) error {
+	return addProfileData(file, eventMessage, profile.Event{
+		Timestamp: timestamp,
+		Event:     profile.FIT_EVENT_TIMER,
+		EventType: eventType,
+		Data:      types.FitUint32(timerTrigger(act)),
+	})
 }
 
-func (act *StravaActivity) writeFooter(file *fit.FitFile) error {
-	// add device battery info message on end of activity
-	deviceInfoBatteryMessage, err := getDeviceInfoBatteryMessageDefinition()
-	if err != nil {
-		return err
-	}
-	deviceInfoBatteryMessageDef := deviceInfoBatteryMessage.ConstructLocalMessage(0)
-	if err := file.AddMessage(deviceInfoBatteryMessageDef); err != nil {
-		return err
-	}
-	deviceInfoBatteryMessageData, err := deviceInfoBatteryMessageDef.ConstructData(
-		FIT_MANUFACTURER_STRAVA, FIT_PRODUCT_STRAVA_ANDROID,
-		fitEncodeTimestamp(act.Activity.Start().Add(act.Activity.TotalDuration())),
-		FIT_BATTERY_STATUS_GOOD)
-	if err != nil {
-		return nil
-	}
-	if err := file.AddMessage(deviceInfoBatteryMessageData); err != nil {
-		return err
+// recordChannels describes which of the optional values are known in any of the records
+type recordChannels struct {
+	heartRate         bool
+	cadence           bool
+	fractionalCadence bool
+	power             bool
+}
+
+func activityChannels(records []activities.Record) recordChannels {
+	var channels recordChannels
+	for i := range records {
+		channels.heartRate = channels.heartRate || records[i].HeartRate != 0
+		channels.cadence = channels.cadence || records[i].Cadence != 0
+		channels.fractionalCadence = channels.fractionalCadence || records[i].FractionalCadence != 0
+		channels.power = channels.power || records[i].Power != 0
+	}
+	return channels
+}
+
+// optionalUint8 converts a value which is unknown if 0 to the fit
+// value, which is the invalid value of the type if unknown
+func optionalUint8(value uint8) types.FitUint8 {
+	if value == 0 {
+		return types.FitTypeInvalidValue[types.FIT_TYPE_UINT8].(types.FitUint8)
 	}
+	return types.FitUint8(value)
+}
 
-	return nil
+func (act *StravaActivity) writeFooter(file fit.MessageWriter) error {
+	// add device battery info message on end of activity
+	return act.writeBatteryInfo(file, act.Activity.Start().Add(act.Activity.TotalDuration()))
 }
@@ -0,0 +1,319 @@
+// Copyright 2021 Artem Mikheev
+
+package stravafit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/renbou/jogmock/activities"
+	"github.com/renbou/jogmock/fit-encoder/encoding"
+	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/profile"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/renbou/jogmock/fit-encoder/fit/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRoute = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="jogmock">
+  <trk>
+    <trkseg>
+      <trkpt lat="59.9234" lon="30.3052"><ele>20</ele></trkpt>
+      <trkpt lat="59.9284" lon="30.3052"><ele>25</ele></trkpt>
+      <trkpt lat="59.9284" lon="30.3152"><ele>22</ele></trkpt>
+      <trkpt lat="59.9234" lon="30.3152"><ele>18</ele></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func buildTestActivity(r *require.Assertions, activityType activities.ActivityType) *StravaActivity {
+	return buildTestActivityWithOptions(r, &activities.ActivityOptions{Type: activityType})
+}
+
+// buildTestActivityWithOptions builds the test route using options with the start and speed filled in
+func buildTestActivityWithOptions(r *require.Assertions, options *activities.ActivityOptions) *StravaActivity {
+	if options.Start.IsZero() {
+		options.Start = time.Now().Add(-time.Hour).Truncate(time.Second)
+	}
+	options.DesiredSpeed = 12
+	activity, err := activities.NewActivity(options)
+	r.NoError(err)
+	r.NoError(activity.BuildFromGPX([]byte(testRoute)))
+
+	return &StravaActivity{
+		AppVersion:         1223782,
+		MobileAppVersion:   "247.10",
+		DeviceManufacturer: "Honor",
+		DeviceModel:        "STK-LX1",
+		DeviceOsVersion:    "10",
+		Activity:           activity,
+	}
+}
+
+func TestBuildFitFileIsValid(t *testing.T) {
+	for _, activityType := range []activities.ActivityType{activities.RunActivity, activities.RideActivity} {
+		t.Run(ActivityTypeToString(activityType), func(t *testing.T) {
+			a := assert.New(t)
+			r := require.New(t)
+
+			act := buildTestActivity(r, activityType)
+			file, err := act.BuildFitFile()
+			r.NoError(err)
+			a.NoError(validate.Validate(file))
+
+			// the encoded file must still be valid after decoding
+			buffer := new(bytes.Buffer)
+			r.NoError(file.Encode(buffer, encoding.LittleEndian))
+			decoded, err := fit.Decode(bytes.NewReader(buffer.Bytes()))
+			r.NoError(err)
+			a.NoError(validate.Validate(decoded))
+
+			// and the stream encoder must produce the same file
+			streamBuffer := new(bytes.Buffer)
+			r.NoError(act.EncodeFitFile(streamBuffer, encoding.LittleEndian))
+			a.Equal(buffer.Bytes(), streamBuffer.Bytes())
+		})
+	}
+}
+
+func TestBuildFitFileIsReproducible(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	// the same seed produces exactly the same file
+	options := activities.ActivityOptions{
+		Type:       activities.RunActivity,
+		Start:      time.Now().Add(-time.Hour).Truncate(time.Second),
+		HeartRate:  &activities.HeartRateOptions{},
+		RunCadence: &activities.RunCadenceOptions{},
+		Stops:      &activities.StopOptions{RandomPerHour: 30},
+		Seed:       1,
+	}
+	sameOptions := options
+	var files [][]byte
+	for _, options := range []*activities.ActivityOptions{&options, &sameOptions} {
+		buffer := new(bytes.Buffer)
+		r.NoError(buildTestActivityWithOptions(r, options).EncodeFitFile(buffer, encoding.LittleEndian))
+		files = append(files, buffer.Bytes())
+	}
+	a.Equal(files[0], files[1])
+}
+
+func TestBuildFitFileWithSensors(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	act := buildTestActivityWithOptions(r, &activities.ActivityOptions{
+		Type:      activities.RideActivity,
+		Power:     &activities.PowerOptions{},
+		HeartRate: &activities.HeartRateOptions{},
+	})
+	file, err := act.BuildFitFile()
+	r.NoError(err)
+	a.NoError(validate.Validate(file))
+
+	// each record is followed by the distance and the sensor messages
+	var powers, heartRates []interface{}
+	for _, message := range file.DataMessages() {
+		if message.Definition().GlobalMsgNum != profile.FIT_MESG_NUM_RECORD {
+			continue
+		}
+		if power := message.Value(profile.RecordPower.Num); power != nil {
+			powers = append(powers, power)
+			heartRates = append(heartRates, message.Value(profile.RecordHeartRate.Num))
+			a.NotNil(message.Value(profile.RecordCadence.Num))
+		}
+	}
+	records := act.Activity.Records()
+	r.Len(powers, len(records))
+	for i := range records {
+		a.Equal(types.FitUint16(records[i].Power), powers[i])
+		a.Equal(types.FitUint8(records[i].HeartRate), heartRates[i])
+	}
+	a.NotZero(records[10].Power)
+	a.NotZero(records[10].HeartRate)
+
+	// activities without the optional values don't contain the sensor messages
+	file, err = buildTestActivity(r, activities.RideActivity).BuildFitFile()
+	r.NoError(err)
+	for _, message := range file.DataMessages() {
+		if message.Definition().GlobalMsgNum == profile.FIT_MESG_NUM_RECORD {
+			a.Nil(message.Value(profile.RecordPower.Num))
+			a.Nil(message.Value(profile.RecordHeartRate.Num))
+		}
+	}
+}
+
+func TestBuildFitFileWithRunCadence(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	act := buildTestActivityWithOptions(r, &activities.ActivityOptions{
+		Type:       activities.RunActivity,
+		RunCadence: &activities.RunCadenceOptions{},
+	})
+	file, err := act.BuildFitFile()
+	r.NoError(err)
+	a.NoError(validate.Validate(file))
+
+	// the half strides are kept in the fractional cadence, which is scaled by 128
+	var cadences, fractionalCadences []interface{}
+	for _, message := range file.DataMessages() {
+		if message.Definition().GlobalMsgNum != profile.FIT_MESG_NUM_RECORD {
+			continue
+		}
+		if cadence := message.Value(profile.RecordCadence.Num); cadence != nil {
+			cadences = append(cadences, cadence)
+			fractionalCadences = append(fractionalCadences, message.Value(profile.RecordFractionalCadence.Num))
+		}
+	}
+	records := act.Activity.Records()
+	r.Len(cadences, len(records))
+	for i := range records {
+		a.Equal(types.FitUint8(records[i].Cadence), cadences[i])
+		a.Equal(types.FitUint8(records[i].FractionalCadence*128), fractionalCadences[i])
+	}
+}
+
+func TestBuildFitFileWithStops(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	act := buildTestActivityWithOptions(r, &activities.ActivityOptions{
+		Type: activities.RunActivity,
+		Stops: &activities.StopOptions{
+			TrafficLightDistance: 0.5,
+			RedLightChance:       1,
+			RedLightDuration:     time.Minute,
+		},
+		AutoPause: true,
+	})
+	stops := act.Activity.Stops()
+	r.NotEmpty(stops)
+	file, err := act.BuildFitFile()
+	r.NoError(err)
+	a.NoError(validate.Validate(file))
+
+	// the timer is stopped and started during each stop in addition to the start and the end
+	var events, timestamps, triggers []interface{}
+	for _, message := range file.DataMessages() {
+		switch message.Definition().GlobalMsgNum {
+		case profile.FIT_MESG_NUM_EVENT:
+			events = append(events, message.Value(profile.EventEventType.Num))
+			timestamps = append(timestamps, message.Value(profile.EventTimestamp.Num))
+			triggers = append(triggers, message.Value(profile.EventData.Num))
+		case profile.FIT_MESG_NUM_SESSION:
+			// the times are stored in ms
+			elapsed := message.Value(profile.SessionTotalElapsedTime.Num).(types.FitUint32)
+			timer := message.Value(profile.SessionTotalTimerTime.Num).(types.FitUint32)
+			a.InDelta(act.Activity.TotalDuration().Milliseconds(), int64(elapsed), 1)
+			a.InDelta(act.Activity.MovingDuration().Milliseconds(), int64(timer), 1)
+			a.Equal(types.FitEnum(STRAVA_AUTOPAUSE_ENABLED), message.DevFields()[2].Value)
+		}
+	}
+	r.Len(events, 2*len(stops)+2)
+	// the timer is only started and stopped manually at the start and the end
+	a.Equal(types.FitUint32(profile.FIT_TIMER_TRIGGER_MANUAL), triggers[0])
+	a.Equal(types.FitUint32(profile.FIT_TIMER_TRIGGER_MANUAL), triggers[len(triggers)-1])
+	for i, stop := range stops {
+		a.Equal(types.FitUint32(profile.FIT_TIMER_TRIGGER_AUTO), triggers[2*i+1])
+		a.Equal(profile.FIT_EVENT_TYPE_STOP, events[2*i+1])
+		a.Equal(profile.EncodeTime(stop.Start), timestamps[2*i+1])
+		a.Equal(profile.FIT_EVENT_TYPE_START, events[2*i+2])
+		a.Equal(profile.EncodeTime(stop.End()), timestamps[2*i+2])
+	}
+}
+
+func TestBuildFitFileWithLaps(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	act := buildTestActivityWithOptions(r, &activities.ActivityOptions{
+		Type: activities.RunActivity,
+		Laps: &activities.LapOptions{AutoLapDistance: 0.5},
+	})
+	laps := act.Activity.Laps()
+	r.Len(laps, 4)
+	file, err := act.BuildFitFile()
+	r.NoError(err)
+	a.NoError(validate.Validate(file))
+
+	// a lap message is written for each lap, with the session containing the number of laps
+	var distances, triggers []interface{}
+	for _, message := range file.DataMessages() {
+		switch message.Definition().GlobalMsgNum {
+		case profile.FIT_MESG_NUM_LAP:
+			a.Equal(types.FitUint16(len(distances)), message.Value(profile.LapMessageIndex.Num))
+			distances = append(distances, message.Value(profile.LapTotalDistance.Num))
+			triggers = append(triggers, message.Value(profile.LapLapTrigger.Num))
+		case profile.FIT_MESG_NUM_SESSION:
+			a.Equal(types.FitUint16(len(laps)), message.Value(profile.SessionNumLaps.Num))
+		}
+	}
+	r.Len(distances, len(laps))
+	for i := range laps {
+		// the distance is stored in cm
+		a.InDelta(laps[i].Distance*100000, float64(distances[i].(types.FitUint32)), 1)
+	}
+	a.Equal(profile.FIT_LAP_TRIGGER_DISTANCE, triggers[0])
+	a.Equal(profile.FIT_LAP_TRIGGER_SESSION_END, triggers[len(triggers)-1])
+}
+
+func TestBuildWorkoutFitFile(t *testing.T) {
+	a := assert.New(t)
+	r := require.New(t)
+
+	act := buildTestActivityWithOptions(r, &activities.ActivityOptions{
+		Type: activities.RunActivity,
+		Workout: &activities.WorkoutOptions{Steps: []activities.WorkoutStep{
+			{Name: "Warm-up", Distance: 0.5, Intensity: activities.WarmupIntensity},
+			{Repeat: 2, Steps: []activities.WorkoutStep{
+				{Name: "Interval", Distance: 0.4, Speed: 16},
+				{Name: "Jog", Distance: 0.2, Speed: 8, Intensity: activities.RestIntensity},
+			}},
+			{Name: "Cool-down", Intensity: activities.CooldownIntensity},
+		}},
+	})
+	file, err := act.BuildWorkoutFitFile()
+	r.NoError(err)
+	a.NoError(validate.Validate(file))
+
+	// the repeated steps are followed by the step repeating them
+	messages := file.DataMessages()
+	var steps []int
+	for i, message := range messages {
+		switch message.Definition().GlobalMsgNum {
+		case profile.FIT_MESG_NUM_FILE_ID:
+			a.Equal(profile.FIT_FILE_WORKOUT, message.Value(profile.FileIdType.Num))
+		case profile.FIT_MESG_NUM_WORKOUT:
+			a.Equal(types.FitUint16(5), message.Value(profile.WorkoutNumValidSteps.Num))
+		case profile.FIT_MESG_NUM_WORKOUT_STEP:
+			a.Equal(types.FitUint16(len(steps)), message.Value(profile.WorkoutStepMessageIndex.Num))
+			steps = append(steps, i)
+		}
+	}
+	r.Len(steps, 5)
+	value := func(step int, field *profile.FieldInfo) interface{} {
+		return messages[steps[step]].Value(field.Num)
+	}
+	a.Equal(profile.FIT_WKT_STEP_DURATION_DISTANCE, value(0, profile.WorkoutStepDurationType))
+	a.Equal(types.FitUint32(50000), value(0, profile.WorkoutStepDurationValue))
+	a.Equal(profile.FIT_INTENSITY_WARMUP, value(0, profile.WorkoutStepIntensity))
+	a.Equal(profile.FIT_WKT_STEP_TARGET_SPEED, value(1, profile.WorkoutStepTargetType))
+	// the speed target is in mm/s around the target speed
+	low := value(1, profile.WorkoutStepCustomTargetValueLow).(types.FitUint32)
+	high := value(1, profile.WorkoutStepCustomTargetValueHigh).(types.FitUint32)
+	a.Less(float64(low), 16/3.6*1000)
+	a.Greater(float64(high), 16/3.6*1000)
+	a.Equal(profile.FIT_WKT_STEP_DURATION_REPEAT_UNTIL_STEPS_CMPLT, value(3, profile.WorkoutStepDurationType))
+	a.Equal(types.FitUint32(1), value(3, profile.WorkoutStepDurationValue))
+	a.Equal(types.FitUint32(2), value(3, profile.WorkoutStepTargetValue))
+	a.Equal(profile.FIT_WKT_STEP_DURATION_OPEN, value(4, profile.WorkoutStepDurationType))
+
+	// Error tests
+	_, err = buildTestActivity(r, activities.RunActivity).BuildWorkoutFitFile()
+	a.ErrorIs(err, ErrNoWorkout)
+}
@@ -4,38 +4,34 @@ package stravafit
 
 import (
 	"github.com/renbou/jogmock/fit-encoder/fit"
-	"github.com/renbou/jogmock/fit-encoder/fit/types"
+	"github.com/renbou/jogmock/fit-encoder/fit/profile"
 )
 
 // getters for all needed strava fit message definitions
 
+// profileValues returns the values of all fields defined by def taken
+// from msg, followed by the values of the developer fields
+func profileValues(msg profile.Message, def *fit.DefinitionMessage, devValues ...interface{}) ([]interface{}, error) {
+	values, err := profile.Values(msg, def)
+	if err != nil {
+		return nil, err
+	}
+	return append(values, devValues...), nil
+}
+
+// addProfileData adds the data message for msg using def,
+// followed by the values of the developer fields
+func addProfileData(file fit.MessageWriter, def *fit.DefinitionMessage, msg profile.Message, devValues ...interface{}) error {
+	values, err := profileValues(msg, def, devValues...)
+	if err != nil {
+		return err
+	}
+	return file.AddData(def, values...)
+}
+
 func getFileIdMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 0,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   1,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   2,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   0,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   4,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.FileId{},
+		profile.FileIdManufacturer, profile.FileIdProduct, profile.FileIdType, profile.FileIdTimeCreated)
 }
 
 func getDeviceInfoMessageDefinition(act *StravaActivity, dev *devData) (*fit.DefinitionMessage, error) {
@@ -55,56 +51,24 @@ func getDeviceInfoMessageDefinition(act *StravaActivity, dev *devData) (*fit.Def
 	if err != nil {
 		return nil, err
 	}
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 23,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   2,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   4,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-		},
-		DevFieldDefs: []*fit.DevFieldDefinition{
-			mobileAppVersionDevField,
-			deviceManufacturerDevField,
-			deviceModelDevField,
-			deviceOsVersion,
-		},
-	}, nil
+	def, err := profile.Definition(profile.DeviceInfo{},
+		profile.DeviceInfoManufacturer, profile.DeviceInfoProduct)
+	if err != nil {
+		return nil, err
+	}
+	def.DevFieldDefs = []*fit.DevFieldDefinition{
+		mobileAppVersionDevField,
+		deviceManufacturerDevField,
+		deviceModelDevField,
+		deviceOsVersion,
+	}
+	return def, nil
 }
 
 func getActivityMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 34,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   1,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   0,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   3,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   4,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.Activity{},
+		profile.ActivityNumSessions, profile.ActivityTotalTimerTime,
+		profile.ActivityEvent, profile.ActivityEventType)
 }
 
 func getSessionMessageDefinition(act *StravaActivity, dev *devData) (*fit.DefinitionMessage, error) {
@@ -120,219 +84,93 @@ func getSessionMessageDefinition(act *StravaActivity, dev *devData) (*fit.Defini
 	if err != nil {
 		return nil, err
 	}
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 18,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   7,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   2,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   8,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   9,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   5,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   0,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   1,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   26,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-		},
-		DevFieldDefs: []*fit.DevFieldDefinition{
-			liveActivityIdDevField,
-			activityTypeDevField,
-			autopauseEnabledDevField,
-		},
-	}, nil
+	def, err := profile.Definition(profile.Session{},
+		profile.SessionTotalElapsedTime, profile.SessionStartTime,
+		profile.SessionTotalTimerTime, profile.SessionTotalDistance,
+		profile.SessionSport, profile.SessionEvent, profile.SessionEventType,
+		profile.SessionNumLaps)
+	if err != nil {
+		return nil, err
+	}
+	def.DevFieldDefs = []*fit.DevFieldDefinition{
+		liveActivityIdDevField,
+		activityTypeDevField,
+		autopauseEnabledDevField,
+	}
+	return def, nil
 }
 
 func getLapMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 19,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   7,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   2,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   8,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   9,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   0,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   1,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   25,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   24,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.Lap{},
+		profile.LapMessageIndex, profile.LapTotalElapsedTime, profile.LapStartTime,
+		profile.LapTotalTimerTime, profile.LapTotalDistance,
+		profile.LapAvgSpeed, profile.LapMaxSpeed, profile.LapTotalAscent, profile.LapTotalDescent,
+		profile.LapEvent, profile.LapEventType, profile.LapSport, profile.LapLapTrigger)
 }
 
 func getDeviceInfoBatteryMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 23,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   2,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   4,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   253,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   11,
-				Size:     types.FIT_TYPE_UINT8_SIZE,
-				BaseType: types.FIT_TYPE_UINT8,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.DeviceInfo{},
+		profile.DeviceInfoManufacturer, profile.DeviceInfoProduct,
+		profile.DeviceInfoTimestamp, profile.DeviceInfoBatteryStatus)
 }
 
 func getEventMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 21,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   0,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-			{
-				DefNum:   253,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   3,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   1,
-				Size:     types.FIT_TYPE_ENUM_SIZE,
-				BaseType: types.FIT_TYPE_ENUM,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.Event{},
+		profile.EventEvent, profile.EventTimestamp, profile.EventData, profile.EventEventType)
 }
 
+// record messages are encoded with compressed timestamp
+// headers, so their definitions don't contain the timestamp field
 func getRecordMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 20,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   0,
-				Size:     types.FIT_TYPE_SINT32_SIZE,
-				BaseType: types.FIT_TYPE_SINT32,
-			},
-			{
-				DefNum:   1,
-				Size:     types.FIT_TYPE_SINT32_SIZE,
-				BaseType: types.FIT_TYPE_SINT32,
-			},
-			{
-				DefNum:   78,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   6,
-				Size:     types.FIT_TYPE_UINT16_SIZE,
-				BaseType: types.FIT_TYPE_UINT16,
-			},
-			{
-				DefNum:   31,
-				Size:     types.FIT_TYPE_UINT8_SIZE,
-				BaseType: types.FIT_TYPE_UINT8,
-			},
-			{
-				DefNum:   253,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.Record{},
+		profile.RecordPositionLat, profile.RecordPositionLong, profile.RecordEnhancedAltitude,
+		profile.RecordSpeed, profile.RecordGpsAccuracy)
+}
+
+// getRecordNoAltitudeMessageDefinition is used for records with an unknown altitude
+func getRecordNoAltitudeMessageDefinition() (*fit.DefinitionMessage, error) {
+	return profile.Definition(profile.Record{},
+		profile.RecordPositionLat, profile.RecordPositionLong,
+		profile.RecordSpeed, profile.RecordGpsAccuracy)
 }
 
 func getRecordDistanceMessageDefinition() (*fit.DefinitionMessage, error) {
-	return &fit.DefinitionMessage{
-		GlobalMsgNum: 20,
-		FieldDefs: []*fit.FieldDefinition{
-			{
-				DefNum:   253,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-			{
-				DefNum:   5,
-				Size:     types.FIT_TYPE_UINT32_SIZE,
-				BaseType: types.FIT_TYPE_UINT32,
-			},
-		},
-		DevFieldDefs: nil,
-	}, nil
+	return profile.Definition(profile.Record{}, profile.RecordDistance)
+}
+
+// getRecordSensorMessageDefinition is used for the values of the
+// records which are only known in some activities, or nil if none are known
+func getRecordSensorMessageDefinition(channels recordChannels) (*fit.DefinitionMessage, error) {
+	var fields []*profile.FieldInfo
+	if channels.heartRate {
+		fields = append(fields, profile.RecordHeartRate)
+	}
+	if channels.cadence {
+		fields = append(fields, profile.RecordCadence)
+	}
+	if channels.fractionalCadence {
+		fields = append(fields, profile.RecordFractionalCadence)
+	}
+	if channels.power {
+		fields = append(fields, profile.RecordPower)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return profile.Definition(profile.Record{}, fields...)
+}
+
+// the workout and workout step messages contain names, so their definitions depend on the messages
+func getWorkoutMessageDefinition(workout profile.Workout) (*fit.DefinitionMessage, error) {
+	return profile.Definition(workout,
+		profile.WorkoutSport, profile.WorkoutCapabilities, profile.WorkoutNumValidSteps, profile.WorkoutWktName)
+}
+
+func getWorkoutStepMessageDefinition(step profile.WorkoutStep) (*fit.DefinitionMessage, error) {
+	return profile.Definition(step,
+		profile.WorkoutStepMessageIndex, profile.WorkoutStepWktStepName,
+		profile.WorkoutStepDurationType, profile.WorkoutStepDurationValue,
+		profile.WorkoutStepTargetType, profile.WorkoutStepTargetValue,
+		profile.WorkoutStepCustomTargetValueLow, profile.WorkoutStepCustomTargetValueHigh,
+		profile.WorkoutStepIntensity)
 }
@@ -0,0 +1,122 @@
+// Copyright 2021 Artem Mikheev
+
+package stravafit
+
+import (
+	"errors"
+	"math"
+
+	"github.com/renbou/jogmock/activities"
+	"github.com/renbou/jogmock/fit-encoder/fit"
+	"github.com/renbou/jogmock/fit-encoder/fit/profile"
+	"github.com/renbou/jogmock/fit-encoder/fit/types"
+)
+
+// relative range of the speed target around the target speed of the workout steps
+const workoutSpeedRange = 0.05
+
+var ErrNoWorkout = errors.New("activity has no workout plan")
+
+// fitIntensity returns the fit intensity of the workout step intensity
+func fitIntensity(intensity activities.WorkoutIntensity) types.FitEnum {
+	switch intensity {
+	case activities.RestIntensity:
+		return profile.FIT_INTENSITY_REST
+	case activities.WarmupIntensity:
+		return profile.FIT_INTENSITY_WARMUP
+	case activities.CooldownIntensity:
+		return profile.FIT_INTENSITY_COOLDOWN
+	default:
+		return profile.FIT_INTENSITY_ACTIVE
+	}
+}
+
+// fitWorkoutSteps appends the workout step messages of the steps to messages. Repeated
+// steps are followed by a step which repeats them, as defined by the fit protocol
+func fitWorkoutSteps(messages []profile.WorkoutStep, steps []activities.WorkoutStep) []profile.WorkoutStep {
+	for i := range steps {
+		step := &steps[i]
+		if len(step.Steps) > 0 {
+			first := len(messages)
+			messages = fitWorkoutSteps(messages, step.Steps)
+			messages = append(messages, profile.WorkoutStep{
+				MessageIndex:  types.FitUint16(len(messages)),
+				WktStepName:   step.Name,
+				DurationType:  profile.FIT_WKT_STEP_DURATION_REPEAT_UNTIL_STEPS_CMPLT,
+				DurationValue: types.FitUint32(first),
+				TargetType:    profile.FIT_WKT_STEP_TARGET_OPEN,
+				TargetValue:   types.FitUint32(step.Repeat),
+				Intensity:     fitIntensity(step.Intensity),
+			})
+			continue
+		}
+
+		// the distance is stored in cm and the speed in mm/s
+		message := profile.WorkoutStep{
+			MessageIndex:          types.FitUint16(len(messages)),
+			WktStepName:           step.Name,
+			DurationType:          profile.FIT_WKT_STEP_DURATION_DISTANCE,
+			DurationValue:         types.FitUint32(math.Round(kmToM(step.Distance) * 100)),
+			TargetType:            profile.FIT_WKT_STEP_TARGET_SPEED,
+			CustomTargetValueLow:  types.FitUint32(math.Round(kmhToMs(step.Speed*(1-workoutSpeedRange)) * 1000)),
+			CustomTargetValueHigh: types.FitUint32(math.Round(kmhToMs(step.Speed*(1+workoutSpeedRange)) * 1000)),
+			Intensity:             fitIntensity(step.Intensity),
+		}
+		if step.Distance == 0 {
+			message.DurationType = profile.FIT_WKT_STEP_DURATION_OPEN
+			message.DurationValue = 0
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// BuildWorkoutFitFile creates a fit workout file containing the workout plan
+// of the filled activity, which can be followed by devices
+func (act *StravaActivity) BuildWorkoutFitFile() (*fit.FitFile, error) {
+	workout := act.Activity.Workout()
+	if workout == nil {
+		return nil, ErrNoWorkout
+	}
+	file := new(fit.FitFile)
+
+	fileIdMessage, err := getFileIdMessageDefinition()
+	if err != nil {
+		return nil, err
+	}
+	if err := addProfileData(file, fileIdMessage, profile.FileId{
+		Type:         profile.FIT_FILE_WORKOUT,
+		Manufacturer: profile.FIT_MANUFACTURER_STRAVA,
+		Product:      FIT_PRODUCT_STRAVA_ANDROID,
+		TimeCreated:  act.Activity.Start(),
+	}); err != nil {
+		return nil, err
+	}
+
+	steps := fitWorkoutSteps(nil, workout.Steps)
+	fitWorkout := profile.Workout{
+		Sport:         fitActivitySport(act),
+		Capabilities:  profile.FIT_WORKOUT_CAPABILITIES_SPEED | profile.FIT_WORKOUT_CAPABILITIES_DISTANCE,
+		NumValidSteps: types.FitUint16(len(steps)),
+		WktName:       act.Activity.Name(),
+	}
+	workoutMessage, err := getWorkoutMessageDefinition(fitWorkout)
+	if err != nil {
+		return nil, err
+	}
+	if err := addProfileData(file, workoutMessage, fitWorkout); err != nil {
+		return nil, err
+	}
+
+	// the size of the step names differs, so each of the steps needs its own definition
+	for _, step := range steps {
+		stepMessage, err := getWorkoutStepMessageDefinition(step)
+		if err != nil {
+			return nil, err
+		}
+		if err := addProfileData(file, stepMessage, step); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -63,11 +64,9 @@ func NewClient(config *ApiConfig) (*ApiClient, error) {
 	return client, nil
 }
 
-func (api *ApiClient) UploadActivity(activity *activities.Activity) error {
-	if api.Token == "" {
-		return ErrUnauthorized
-	}
-
+// EncodeActivity encodes the activity as the exact FIT file which UploadActivity uploads,
+// which doesn't require the client to be authorized
+func (api *ApiClient) EncodeActivity(activity *activities.Activity, wr io.Writer) error {
 	a := stravafit.StravaActivity{
 		AppVersion:         api.internalAppVersion,
 		MobileAppVersion:   api.MobileAppVersion,
@@ -81,11 +80,37 @@ func (api *ApiClient) UploadActivity(activity *activities.Activity) error {
 		return err
 	}
 
-	activityBuffer := new(bytes.Buffer)
-	encoder := encoding.NewEncoder(activityBuffer, encoding.BigEndian)
+	encoder := encoding.NewEncoder(wr, encoding.BigEndian)
 	if err := encoder.Encode(fitFile); err != nil {
 		return fmt.Errorf("error while encoding fit file: %v", err)
 	}
+	return nil
+}
+
+// EncodeWorkout encodes the workout plan of the activity as a FIT workout file
+func EncodeWorkout(activity *activities.Activity, wr io.Writer) error {
+	a := stravafit.StravaActivity{Activity: activity}
+	fitFile, err := a.BuildWorkoutFitFile()
+	if err != nil {
+		return err
+	}
+
+	encoder := encoding.NewEncoder(wr, encoding.BigEndian)
+	if err := encoder.Encode(fitFile); err != nil {
+		return fmt.Errorf("error while encoding fit file: %v", err)
+	}
+	return nil
+}
+
+func (api *ApiClient) UploadActivity(activity *activities.Activity) error {
+	if api.Token == "" {
+		return ErrUnauthorized
+	}
+
+	activityBuffer := new(bytes.Buffer)
+	if err := api.EncodeActivity(activity, activityBuffer); err != nil {
+		return err
+	}
 
 	randomActivityUUID, err := uuid.NewRandom()
 	if err != nil {